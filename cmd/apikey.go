@@ -0,0 +1,100 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"orderkeeper/internal/auth"
+	"orderkeeper/internal/repository"
+	"orderkeeper/internal/service"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+func openAPIKeyService() (service.APIKeyService, error) {
+	database, _, err := openOrderStore(false)
+	if err != nil {
+		return nil, err
+	}
+	return service.NewAPIKeyService(repository.NewAPIKeyRepository(database)), nil
+}
+
+func runAPIKeyIssue(args []string) error {
+	fs := flag.NewFlagSet("apikey issue", flag.ExitOnError)
+	scopes := fs.String("scopes", auth.DefaultScope, "comma-separated scopes: "+strings.Join(auth.Scopes, ", "))
+	tenant := fs.String("tenant", "", `restrict the key to orders of this entry, or "*" for all tenants (required)`)
+	_ = fs.Parse(args)
+	if fs.NArg() != 1 {
+		return errors.New("usage: orderkeeper apikey issue -tenant ENTRY|* [-scopes LIST] NAME")
+	}
+
+	svc, err := openAPIKeyService()
+	if err != nil {
+		return err
+	}
+	issued, err := svc.IssueAPIKey(fs.Arg(0), *tenant, strings.Split(*scopes, ","))
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Issued key %d %q. Store it now, it cannot be shown again.\n", issued.ID, issued.Name)
+	fmt.Println(issued.Key)
+	return nil
+}
+
+func runAPIKeyList(args []string) error {
+	fs := flag.NewFlagSet("apikey list", flag.ExitOnError)
+	asJSON := fs.Bool("json", false, "print JSON instead of a table")
+	_ = fs.Parse(args)
+
+	svc, err := openAPIKeyService()
+	if err != nil {
+		return err
+	}
+	keys, err := svc.ListAPIKeys()
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		return json.NewEncoder(os.Stdout).Encode(keys)
+	}
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tNAME\tPREFIX\tSCOPES\tTENANT\tCREATED\tREVOKED")
+	for _, k := range keys {
+		revoked := "-"
+		if k.RevokedAt != nil {
+			revoked = k.RevokedAt.UTC().Format(time.RFC3339)
+		}
+		tenant := k.Tenant
+		if tenant == "" {
+			tenant = "-"
+		}
+		fmt.Fprintf(tw, "%d\t%s\t%s…\t%s\t%s\t%s\t%s\n", k.ID, k.Name, k.Prefix, strings.Join(k.Scopes, ","), tenant, k.CreatedAt.UTC().Format(time.RFC3339), revoked)
+	}
+	return tw.Flush()
+}
+
+func runAPIKeyRevoke(args []string) error {
+	fs := flag.NewFlagSet("apikey revoke", flag.ExitOnError)
+	_ = fs.Parse(args)
+	if fs.NArg() != 1 {
+		return errors.New("usage: orderkeeper apikey revoke ID")
+	}
+	id, err := strconv.ParseUint(fs.Arg(0), 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid key ID %q", fs.Arg(0))
+	}
+
+	svc, err := openAPIKeyService()
+	if err != nil {
+		return err
+	}
+	if err := svc.RevokeAPIKey(uint(id)); err != nil {
+		return err
+	}
+	fmt.Printf("API key %d revoked\n", id)
+	return nil
+}
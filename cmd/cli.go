@@ -0,0 +1,103 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"orderkeeper/internal/cache"
+	"orderkeeper/internal/db"
+	"orderkeeper/internal/service"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"gorm.io/gorm"
+)
+
+type command struct {
+	// name — одно или два слова, например "cache stats".
+	name    string
+	args    string
+	summary string
+	run     func(args []string) error
+}
+
+func commands() []command {
+	return []command{
+		{"serve", "", "run the consumer, outbox relay and HTTP API (default)", runServe},
+		{"migrate", "", "apply database migrations and exit", runMigrate},
+		{"import", "[flags] FILE", "load orders from an NDJSON or CSV file", runImport},
+		{"export", "[flags]", "write orders as NDJSON, CSV or Parquet", runExport},
+		{"get", "UID", "print an order as JSON", runGet},
+		{"retention", "[flags]", "archive or purge orders older than the retention period once", runRetention},
+		{"apikey issue", "-tenant ENTRY|* NAME", "issue an API key and print it once", runAPIKeyIssue},
+		{"apikey list", "[flags]", "list issued API keys", runAPIKeyList},
+		{"apikey revoke", "ID", "revoke an API key", runAPIKeyRevoke},
+		{"pii rotate", "[flags]", "add a new key to the PII keyfile and make it current", runPIIRotate},
+		{"pii reencrypt", "[flags]", "re-encrypt delivery personal data with the current key", runPIIReencrypt},
+		{"cache stats", "[flags]", "show order cache statistics of a running instance", runCacheStats},
+		{"consumer lag", "[flags]", "show committed offsets and lag of the consumer group", runConsumerLag},
+		{"produce-sample", "[flags]", "send sample orders to the orders topic", runProduceSample},
+		{"loadgen", "[flags]", "produce random orders at a given rate and measure end-to-end latency", runLoadgen},
+		{"verify-config", "[flags]", "check configuration and connectivity to Postgres and Kafka", runVerifyConfig},
+	}
+}
+
+// runCLI выбирает подкоманду по первым словам args. Без аргументов
+// запускается сервис, как и раньше.
+func runCLI(args []string) error {
+	if len(args) == 0 {
+		return runServe(nil)
+	}
+	switch args[0] {
+	case "help", "-h", "-help", "--help":
+		usage(os.Stdout)
+		return nil
+	}
+	for _, cmd := range commands() {
+		words := strings.Fields(cmd.name)
+		if len(args) >= len(words) && slices.Equal(args[:len(words)], words) {
+			return cmd.run(args[len(words):])
+		}
+	}
+	usage(os.Stderr)
+	return fmt.Errorf("unknown command %q", strings.Join(args, " "))
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: orderkeeper COMMAND [ARGS]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, cmd := range commands() {
+		fmt.Fprintf(tw, "  %s %s\t%s\n", cmd.name, cmd.args, cmd.summary)
+	}
+	_ = tw.Flush()
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Configuration is read from the environment, see .env.example.")
+	fmt.Fprintln(w, "Run 'orderkeeper COMMAND -h' for command flags.")
+}
+
+// openOrderStore подключается к БД по DSN из окружения для подкоманд,
+// которым не нужны Kafka и HTTP. migrate включает миграции схемы.
+func openOrderStore(migrate bool) (*gorm.DB, service.OrderService, error) {
+	dsn := os.Getenv("DSN")
+	if dsn == "" {
+		return nil, nil, errors.New("DSN environment variable is not set")
+	}
+	reconciliation, err := loadReconciliation()
+	if err != nil {
+		return nil, nil, err
+	}
+	cipher, err := loadPIICipher()
+	if err != nil {
+		return nil, nil, err
+	}
+	setupPII(cipher)
+	initDB := db.Open
+	if migrate {
+		initDB = db.InitDB
+	}
+	return newOrderStore(initDB, dsn, reconciliation, cache.NewOrderCache())
+}
@@ -0,0 +1,359 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"orderkeeper/internal/cache"
+	"orderkeeper/internal/db"
+	"orderkeeper/internal/export"
+	"orderkeeper/internal/kafka"
+	"orderkeeper/internal/models"
+	"orderkeeper/internal/money"
+	"orderkeeper/internal/repository"
+	"orderkeeper/internal/retention"
+	"orderkeeper/internal/service"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"text/tabwriter"
+	"time"
+)
+
+// cliTimeout ограничивает обращения подкоманд к Kafka и HTTP.
+const cliTimeout = 30 * time.Second
+
+func runMigrate(args []string) error {
+	fs := flag.NewFlagSet("migrate", flag.ExitOnError)
+	_ = fs.Parse(args)
+
+	dsn := os.Getenv("DSN")
+	if dsn == "" {
+		return errors.New("DSN environment variable is not set")
+	}
+	if _, err := db.InitDB(dsn); err != nil {
+		return fmt.Errorf("migration failed: %w", err)
+	}
+	return nil
+}
+
+func runExport(args []string) error {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	format := fs.String("format", export.FormatNDJSON, "ndjson, csv or parquet")
+	output := fs.String("o", "-", "output file, - for standard output")
+	cursor := fs.String("cursor", "", "export orders with order_uid greater than this")
+	from := fs.String("from", "", "created at or after, YYYY-MM-DD or RFC 3339")
+	to := fs.String("to", "", "created before, YYYY-MM-DD or RFC 3339")
+	status := fs.String("status", "", "order status")
+	customerID := fs.String("customer-id", "", "customer ID")
+	needsReview := fs.String("needs-review", "", "only orders flagged (true) or not flagged (false) for manual review")
+	_ = fs.Parse(args)
+
+	filter := models.OrderFilter{After: *cursor, Status: *status, CustomerID: *customerID}
+	var err error
+	if filter.From, err = export.ParseFilterTime(*from); err != nil {
+		return fmt.Errorf("invalid -from: %w", err)
+	}
+	if filter.To, err = export.ParseFilterTime(*to); err != nil {
+		return fmt.Errorf("invalid -to: %w", err)
+	}
+	if filter.NeedsReview, err = export.ParseFilterBool(*needsReview); err != nil {
+		return fmt.Errorf("invalid -needs-review: %w", err)
+	}
+
+	var out io.Writer = os.Stdout
+	if *output != "-" {
+		f, err := os.Create(*output)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		out = f
+	}
+	writer, err := export.NewWriter(*format, out)
+	if err != nil {
+		return err
+	}
+
+	_, orderService, err := openOrderStore(false)
+	if err != nil {
+		return err
+	}
+	exported := 0
+	err = orderService.ExportOrders(filter, func(orders []models.Order) error {
+		if err := writer.WriteBatch(orders); err != nil {
+			return err
+		}
+		exported += len(orders)
+		log.Printf("Exported %d orders, cursor %s", exported, orders[len(orders)-1].OrderUID)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("export stopped after %d orders: %w", exported, err)
+	}
+	return writer.Close()
+}
+
+func runGet(args []string) error {
+	fs := flag.NewFlagSet("get", flag.ExitOnError)
+	_ = fs.Parse(args)
+	if fs.NArg() != 1 {
+		return errors.New("usage: orderkeeper get UID")
+	}
+
+	_, orderService, err := openOrderStore(false)
+	if err != nil {
+		return err
+	}
+	order, err := orderService.GetOrderByID(fs.Arg(0))
+	if errors.Is(err, service.ErrOrderNotFound) {
+		return fmt.Errorf("order %q not found", fs.Arg(0))
+	}
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(order)
+}
+
+// runRetention применяет политику хранения из окружения один раз; флаги
+// переопределяют RETENTION_DAYS и RETENTION_MODE.
+func runRetention(args []string) error {
+	cfg, err := loadRetention()
+	if err != nil {
+		return err
+	}
+	fs := flag.NewFlagSet("retention", flag.ExitOnError)
+	days := fs.Int("days", int(cfg.Policy.MaxAge/(24*time.Hour)), "process orders created more than this many days ago")
+	mode := fs.String("mode", cfg.Policy.Mode, "archive (soft delete) or purge (delete permanently)")
+	_ = fs.Parse(args)
+
+	policy := service.RetentionPolicy{Mode: *mode, MaxAge: time.Duration(*days) * 24 * time.Hour}
+	if err := policy.Validate(); err != nil {
+		return err
+	}
+	if !policy.Enabled() {
+		return errors.New("retention period is not set, use -days or RETENTION_DAYS")
+	}
+
+	database, _, err := openOrderStore(false)
+	if err != nil {
+		return err
+	}
+	svc := service.NewRetentionService(repository.NewRetentionRepository(database), cache.NewOrderCache())
+	n, err := retention.NewJob(svc, policy, 0).RunOnce()
+	if err != nil {
+		return fmt.Errorf("retention stopped after %d orders: %w", n, err)
+	}
+	fmt.Printf("%d orders processed in %s mode\n", n, policy.Mode)
+	return nil
+}
+
+// runCacheStats запрашивает статистику у работающего инстанса: кеш живет в
+// памяти сервиса.
+func runCacheStats(args []string) error {
+	port := os.Getenv("PORT")
+	if port == "" {
+		port = "8080"
+	}
+	fs := flag.NewFlagSet("cache stats", flag.ExitOnError)
+	addr := fs.String("addr", "http://localhost:"+port, "base URL of a running instance")
+	apiKey := fs.String("api-key", os.Getenv("API_KEY"), "API key for the instance")
+	_ = fs.Parse(args)
+
+	req, err := http.NewRequest(http.MethodGet, *addr+"/admin/cache/stats", nil)
+	if err != nil {
+		return err
+	}
+	if *apiKey != "" {
+		req.Header.Set("X-API-Key", *apiKey)
+	}
+	client := &http.Client{Timeout: cliTimeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s responded with %s", *addr, resp.Status)
+	}
+	_, err = io.Copy(os.Stdout, resp.Body)
+	return err
+}
+
+func runConsumerLag(args []string) error {
+	fs := flag.NewFlagSet("consumer lag", flag.ExitOnError)
+	asJSON := fs.Bool("json", false, "print JSON instead of a table")
+	_ = fs.Parse(args)
+
+	cfg, err := loadKafkaConfig()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), cliTimeout)
+	defer cancel()
+	pos, err := kafka.GroupPosition(ctx, cfg)
+	if err != nil {
+		return err
+	}
+
+	if *asJSON {
+		return json.NewEncoder(os.Stdout).Encode(pos)
+	}
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintf(tw, "TOPIC\tPARTITION\tCOMMITTED\tEND\tLAG\t\n")
+	for _, p := range pos.Partitions {
+		committed := strconv.FormatInt(p.Committed, 10)
+		if p.Committed < 0 {
+			committed = "-"
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%s\t%d\t%d\t\n", p.Topic, p.Partition, committed, p.End, p.Lag)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	fmt.Printf("group %s: total lag %d\n", pos.GroupID, pos.TotalLag)
+	return nil
+}
+
+func runProduceSample(args []string) error {
+	fs := flag.NewFlagSet("produce-sample", flag.ExitOnError)
+	count := fs.Int("count", 1, "number of orders to send")
+	_ = fs.Parse(args)
+	if *count < 1 {
+		return errors.New("-count must be positive")
+	}
+
+	cfg, err := loadKafkaConfig()
+	if err != nil {
+		return err
+	}
+	writer, err := kafka.NewOrderWriter(cfg)
+	if err != nil {
+		return err
+	}
+	defer writer.Close()
+
+	orders := make([]models.Order, *count)
+	prefix := strconv.FormatInt(time.Now().UnixNano(), 36)
+	for i := range orders {
+		orders[i] = sampleOrder(fmt.Sprintf("sample%s%d", prefix, i), time.Now())
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), cliTimeout)
+	defer cancel()
+	if err := writer.Write(ctx, orders...); err != nil {
+		return err
+	}
+	for _, order := range orders {
+		fmt.Println(order.OrderUID)
+	}
+	log.Printf("Sent %d sample orders to topic %s", len(orders), cfg.Topic)
+	return nil
+}
+
+// sampleOrder — заказ из примера в README с заданным order_uid.
+func sampleOrder(uid string, now time.Time) models.Order {
+	return models.Order{
+		OrderUID:    uid,
+		TrackNumber: "WBILMTESTTRACK",
+		Entry:       "WBIL",
+		Delivery: models.Delivery{
+			Name:    "Test Testov",
+			Phone:   "+9720000000",
+			Zip:     "2639809",
+			City:    "Kiryat Mozkin",
+			Address: "Ploshad Mira 15",
+			Region:  "Kraiot",
+			Email:   "test@gmail.com",
+		},
+		Payment: models.Payment{
+			Transaction:  uid,
+			Currency:     "USD",
+			Provider:     "wbpay",
+			Amount:       money.New(1817, "USD"),
+			PaymentDT:    models.UnixTime{Time: now},
+			Bank:         "alpha",
+			DeliveryCost: money.New(1500, "USD"),
+			GoodsTotal:   money.New(317, "USD"),
+		},
+		Items: []models.Item{{
+			CHRTID:      9934930,
+			TrackNumber: "WBILMTESTTRACK",
+			Price:       money.New(453, "USD"),
+			RID:         "ab4219087a764ae0btest",
+			Name:        "Mascaras",
+			Sale:        30,
+			Size:        "0",
+			TotalPrice:  money.New(317, "USD"),
+			NMID:        2389212,
+			Brand:       "Vivienne Sabo",
+			Status:      202,
+		}},
+		Locale:          "en",
+		CustomerID:      "test",
+		DeliveryService: "meest",
+		Shardkey:        "9",
+		SMID:            99,
+		DateCreated:     models.Timestamp{Time: now},
+		OOFShard:        "1",
+		Status:          models.OrderStatusCreated,
+	}
+}
+
+// runVerifyConfig проверяет конфигурацию так же, как serve, и, если не
+// задан -offline, доступность Postgres и Kafka.
+func runVerifyConfig(args []string) error {
+	fs := flag.NewFlagSet("verify-config", flag.ExitOnError)
+	offline := fs.Bool("offline", false, "only validate settings, do not connect")
+	_ = fs.Parse(args)
+
+	cfg, err := NewConfig()
+	if err != nil {
+		return fmt.Errorf("configuration error: %w", err)
+	}
+	fmt.Println("ok  configuration")
+	if *offline {
+		return nil
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	ctx, cancel := context.WithTimeout(ctx, cliTimeout)
+	defer cancel()
+
+	var failed bool
+	check := func(name string, err error) {
+		if err != nil {
+			failed = true
+			fmt.Printf("FAIL %s: %v\n", name, err)
+			return
+		}
+		fmt.Printf("ok  %s\n", name)
+	}
+
+	database, err := db.Open(cfg.DSN)
+	if err == nil {
+		sqlDB, dbErr := database.DB()
+		if dbErr == nil {
+			dbErr = sqlDB.PingContext(ctx)
+			_ = sqlDB.Close()
+		}
+		err = dbErr
+	}
+	check("postgres", err)
+
+	_, err = kafka.GroupPosition(ctx, cfg.Kafka)
+	check("kafka topics "+fmt.Sprint(cfg.Kafka.Topics()), err)
+
+	if failed {
+		return errors.New("configuration check failed")
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"orderkeeper/internal/export"
+	"orderkeeper/internal/importer"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+)
+
+// runImport загружает заказы из NDJSON или CSV в БД, минуя Kafka. Нужны
+// только DSN и настройки сверки.
+func runImport(args []string) error {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	format := fs.String("format", "", "ndjson or csv; detected from the file extension by default")
+	progressEvery := fs.Int("progress-every", importer.DefaultProgressEvery, "log progress after this many records")
+	rejectsPath := fs.String("rejects", "", "file for skipped records (default FILE.rejects.ndjson)")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: orderkeeper import [flags] FILE\n\nFILE may be - to read standard input.")
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return errors.New("exactly one input file is required")
+	}
+	path := fs.Arg(0)
+	if *format == "" {
+		*format = formatFromPath(path)
+	}
+	if *rejectsPath == "" {
+		*rejectsPath = strings.TrimSuffix(path, filepath.Ext(path)) + ".rejects.ndjson"
+		if path == "-" {
+			*rejectsPath = "rejects.ndjson"
+		}
+	}
+
+	var input io.Reader = os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		input = f
+	}
+	reader, err := importer.NewReader(*format, input)
+	if err != nil {
+		return err
+	}
+
+	_, orderService, err := openOrderStore(true)
+	if err != nil {
+		return err
+	}
+
+	rejects := &rejectsFile{path: *rejectsPath}
+	defer rejects.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	progress, err := importer.Import(ctx, orderService, reader, importer.Options{
+		ProgressEvery: *progressEvery,
+		OnProgress: func(p importer.Progress) {
+			log.Printf("Imported %d of %d orders, %d skipped", p.Imported, p.Read, p.Skipped)
+		},
+		OnReject: rejects.Write,
+	})
+	log.Printf("Import finished: %d read, %d imported, %d skipped", progress.Read, progress.Imported, progress.Skipped)
+	if progress.Skipped > 0 {
+		log.Printf("Skipped records written to %s", rejects.path)
+	}
+	if err == nil {
+		err = rejects.err
+	}
+	return err
+}
+
+func formatFromPath(path string) string {
+	if strings.EqualFold(filepath.Ext(path), ".csv") {
+		return export.FormatCSV
+	}
+	return export.FormatNDJSON
+}
+
+// rejectsFile создается при первой отклоненной записи, чтобы успешный
+// импорт не оставлял пустых файлов.
+type rejectsFile struct {
+	path string
+	f    *os.File
+	enc  *json.Encoder
+	err  error
+}
+
+func (r *rejectsFile) Write(reject importer.Reject) {
+	log.Printf("Skipped record at line %d (order %q): %s", reject.Line, reject.OrderUID, reject.Error)
+	if r.err != nil {
+		return
+	}
+	if r.f == nil {
+		if r.f, r.err = os.Create(r.path); r.err != nil {
+			return
+		}
+		r.enc = json.NewEncoder(r.f)
+	}
+	r.err = r.enc.Encode(reject)
+}
+
+func (r *rejectsFile) Close() {
+	if r.f != nil {
+		if err := r.f.Close(); err != nil {
+			log.Printf("Failed to close rejects file: %v", err)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"orderkeeper/internal/export"
+	"orderkeeper/internal/kafka"
+	"orderkeeper/internal/loadgen"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// runLoadgen отправляет случайные заказы в KAFKA_TOPIC и ждет появления
+// валидных в GET /order/{id} работающего инстанса.
+func runLoadgen(args []string) error {
+	port := os.Getenv("PORT")
+	if port == "" {
+		port = "8080"
+	}
+	fs := flag.NewFlagSet("loadgen", flag.ExitOnError)
+	seed := fs.Uint64("seed", 1, "random seed; the same seed and -start produce the same orders")
+	start := fs.String("start", "", "orders are created within 30 days before this date, YYYY-MM-DD or RFC 3339 (default today)")
+	prefix := fs.String("prefix", "loadgen-", "order_uid prefix; change it to rerun a seed against the same database")
+	invalid := fs.Float64("invalid", 0.1, "share of invalid orders, 0..1")
+	rate := fs.Float64("rate", 10, "orders per second, 0 for unlimited")
+	concurrency := fs.Int("concurrency", 4, "parallel producers")
+	count := fs.Int("count", 100, "orders to send, 0 for unlimited")
+	duration := fs.Duration("duration", 0, "stop sending after this long")
+	addr := fs.String("addr", "http://localhost:"+port, "base URL of the instance to read orders from")
+	apiKey := fs.String("api-key", os.Getenv("API_KEY"), "API key for the instance")
+	timeout := fs.Duration("timeout", 30*time.Second, "how long to wait for an order to become readable")
+	asJSON := fs.Bool("json", false, "print the report as JSON")
+	_ = fs.Parse(args)
+
+	if *invalid < 0 || *invalid > 1 {
+		return errors.New("-invalid must be between 0 and 1")
+	}
+	if *count == 0 && *duration == 0 {
+		return errors.New("either -count or -duration must be set")
+	}
+	startAt := time.Now().UTC().Truncate(24 * time.Hour)
+	if *start != "" {
+		var err error
+		if startAt, err = export.ParseFilterTime(*start); err != nil {
+			return fmt.Errorf("invalid -start: %w", err)
+		}
+	}
+
+	cfg, err := loadKafkaConfig()
+	if err != nil {
+		return err
+	}
+	writer, err := kafka.NewOrderWriter(cfg)
+	if err != nil {
+		return err
+	}
+	defer writer.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	log.Printf("Sending orders to topic %s (seed %d, rate %g/s, concurrency %d)", cfg.Topic, *seed, *rate, *concurrency)
+	report := loadgen.Run(ctx,
+		loadgen.NewGenerator(*seed, *prefix, startAt, *invalid),
+		writer,
+		loadgen.HTTPProber{BaseURL: *addr, APIKey: *apiKey, Client: &http.Client{Timeout: 5 * time.Second}},
+		loadgen.Options{
+			Rate:        *rate,
+			Concurrency: *concurrency,
+			Count:       *count,
+			Duration:    *duration,
+			ReadTimeout: *timeout,
+		})
+
+	if *asJSON {
+		return json.NewEncoder(os.Stdout).Encode(report)
+	}
+	report.WriteText(os.Stdout)
+	return nil
+}
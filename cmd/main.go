@@ -3,21 +3,40 @@
 // @description API for managing orders
 // @host localhost:8080
 // @BasePath /
+// @securityDefinitions.apikey ApiKeyAuth
+// @in header
+// @name X-API-Key
+// @description API key issued with "orderkeeper apikey issue" or POST /admin/api-keys
+// @securityDefinitions.apikey BearerAuth
+// @in header
+// @name Authorization
+// @description "Bearer " followed by an API key or a JWT signed with a key from the configured JWKS
 package main
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"expvar"
+	"flag"
 	"fmt"
 	"log"
 	"net/http"
 	_ "orderkeeper/docs"
+	"orderkeeper/internal/auth"
 	"orderkeeper/internal/cache"
 	"orderkeeper/internal/db"
 	"orderkeeper/internal/handler"
 	"orderkeeper/internal/kafka"
+	"orderkeeper/internal/logger"
+	"orderkeeper/internal/money"
+	"orderkeeper/internal/outbox"
+	"orderkeeper/internal/pii"
 	"orderkeeper/internal/repository"
+	"orderkeeper/internal/retention"
+	"orderkeeper/internal/schemaregistry"
 	"orderkeeper/internal/service"
+	"orderkeeper/internal/validation"
 	"os"
 	"os/signal"
 	"syscall"
@@ -30,21 +49,50 @@ import (
 )
 
 type Config struct {
-	Port         string
-	DSN          string
-	KafkaBrokers string
-	KafkaTopic   string
-	KafkaGroupID string
+	Port           string
+	DSN            string
+	Kafka          kafka.Config
+	Outbox         OutboxConfig
+	Reconciliation validation.Reconciliation
+	ReportCacheTTL time.Duration
+	Retention      RetentionConfig
+	// PII шифрует данные получателя; nil — шифрование выключено.
+	PII     *pii.Cipher
+	Auth    AuthConfig
+	Runtime RuntimeSettings
+}
+
+type OutboxConfig struct {
+	Topic string
+	// TenantTopics — топики событий отдельных площадок.
+	TenantTopics map[string]string
+	PollInterval time.Duration
+	BatchSize    int
+}
+
+// AuthConfig — проверка API-ключей и JWT. JWT принимаются, только если
+// задан JWKSFile или JWKSURL.
+type AuthConfig struct {
+	Disabled bool
+	// AllowNoTenant пускает учетные данные без площадки ко всем площадкам.
+	AllowNoTenant bool
+	JWKSFile      string
+	JWKSURL       string
+	JWKSRefresh   time.Duration
+	Issuer        string
+	Audience      string
+}
+
+type RetentionConfig struct {
+	Policy   service.RetentionPolicy
+	Interval time.Duration
 }
 
 func NewConfig() (*Config, error) {
 	log.Println("Loading configuration...")
 	cfg := &Config{
-		Port:         os.Getenv("PORT"),
-		DSN:          os.Getenv("DSN"),
-		KafkaBrokers: os.Getenv("KAFKA_BROKERS"),
-		KafkaTopic:   os.Getenv("KAFKA_TOPIC"),
-		KafkaGroupID: os.Getenv("KAFKA_GROUP_ID"),
+		Port: os.Getenv("PORT"),
+		DSN:  os.Getenv("DSN"),
 	}
 
 	if cfg.Port == "" {
@@ -53,68 +101,355 @@ func NewConfig() (*Config, error) {
 	if cfg.DSN == "" {
 		return nil, errors.New("DSN environment variable is not set")
 	}
-	if cfg.KafkaBrokers == "" {
+	if os.Getenv("KAFKA_BROKERS") == "" {
 		return nil, errors.New("KAFKA_BROKERS environment variable is not set")
 	}
-	if cfg.KafkaTopic == "" {
+	if os.Getenv("KAFKA_TOPIC") == "" {
 		return nil, errors.New("KAFKA_TOPIC environment variable is not set")
 	}
-	if cfg.KafkaGroupID == "" {
+	if os.Getenv("KAFKA_GROUP_ID") == "" {
 		return nil, errors.New("KAFKA_GROUP_ID environment variable is not set")
 	}
+	kafkaCfg, err := loadKafkaConfig()
+	if err != nil {
+		return nil, err
+	}
+	cfg.Kafka = kafkaCfg
+	cfg.Outbox.Topic = os.Getenv("OUTBOX_TOPIC")
+	if cfg.Outbox.Topic == "" {
+		cfg.Outbox.Topic = "order-events"
+	}
+	if cfg.Outbox.TenantTopics, err = kafka.ParseTenantTopics(os.Getenv("OUTBOX_TENANT_TOPICS")); err != nil {
+		return nil, err
+	}
+	if cfg.Outbox.PollInterval, err = envDuration("OUTBOX_POLL_INTERVAL"); err != nil {
+		return nil, err
+	}
+	if cfg.Outbox.BatchSize, err = envInt("OUTBOX_BATCH_SIZE"); err != nil {
+		return nil, err
+	}
+	if cfg.Reconciliation, err = loadReconciliation(); err != nil {
+		return nil, err
+	}
+	if cfg.ReportCacheTTL, err = envDuration("REPORT_CACHE_TTL"); err != nil {
+		return nil, err
+	}
+	if cfg.Retention, err = loadRetention(); err != nil {
+		return nil, err
+	}
+	if cfg.PII, err = loadPIICipher(); err != nil {
+		return nil, err
+	}
+	if cfg.Auth, err = loadAuth(); err != nil {
+		return nil, err
+	}
+	runtime, err := LoadRuntimeSettings()
+	if err != nil {
+		return nil, err
+	}
+	cfg.Runtime = runtime
 	log.Println("Configuration loaded successfully.")
 	return cfg, nil
 }
 
 type App struct {
-	Config   *Config
-	DB       *gorm.DB
-	Cache    *cache.OrderCache
-	Service  service.OrderService
-	Consumer *kafka.Consumer
-	Server   *http.Server
+	Config      *Config
+	DB          *gorm.DB
+	Cache       *cache.OrderCache
+	Service     service.OrderService
+	Consumer    *kafka.Consumer
+	EventWriter *kafka.EventWriter
+	Relay       *outbox.Relay
+	Retention   *retention.Job
+	Server      *http.Server
+
+	reloader *reloader
+	// consumerDone закрывается, когда консьюмер остановился и отчет dry-run
+	// больше не меняется.
+	consumerDone chan struct{}
 }
 
-func NewApp(cfg *Config) (*App, error) {
-	database, err := db.InitDB(cfg.DSN)
+func loadKafkaConfig() (kafka.Config, error) {
+	cfg := kafka.Config{
+		Brokers: kafka.ParseBrokers(os.Getenv("KAFKA_BROKERS")),
+		Topic:   os.Getenv("KAFKA_TOPIC"),
+		GroupID: os.Getenv("KAFKA_GROUP_ID"),
+
+		StatusTopic:       os.Getenv("KAFKA_STATUS_TOPIC"),
+		PaymentTopic:      os.Getenv("KAFKA_PAYMENT_TOPIC"),
+		CancellationTopic: os.Getenv("KAFKA_CANCELLATION_TOPIC"),
+		DLQTopic:          os.Getenv("KAFKA_DLQ_TOPIC"),
+
+		TLS: kafka.TLSConfig{
+			CAFile:   os.Getenv("KAFKA_TLS_CA_FILE"),
+			CertFile: os.Getenv("KAFKA_TLS_CERT_FILE"),
+			KeyFile:  os.Getenv("KAFKA_TLS_KEY_FILE"),
+		},
+		SASL: kafka.SASLConfig{
+			Mechanism: os.Getenv("KAFKA_SASL_MECHANISM"),
+			Username:  os.Getenv("KAFKA_SASL_USERNAME"),
+			Password:  os.Getenv("KAFKA_SASL_PASSWORD"),
+		},
+		StartOffset: os.Getenv("KAFKA_START_OFFSET"),
+	}
+
+	if dir := os.Getenv("SCHEMA_REGISTRY_DIR"); dir != "" {
+		registry, err := schemaregistry.NewFileClient(dir)
+		if err != nil {
+			return cfg, err
+		}
+		cfg.SchemaRegistry = registry
+	}
+
+	var err error
+	// DRY_RUN нужен и CLI: consumer lag показывает группу dry-run-консьюмера.
+	if cfg.DryRun, err = envBool("DRY_RUN"); err != nil {
+		return cfg, err
+	}
+	if cfg.TLS.Enabled, err = envBool("KAFKA_TLS_ENABLED"); err != nil {
+		return cfg, err
+	}
+	if cfg.TLS.InsecureSkipVerify, err = envBool("KAFKA_TLS_INSECURE_SKIP_VERIFY"); err != nil {
+		return cfg, err
+	}
+	if cfg.MinBytes, err = envInt("KAFKA_MIN_BYTES"); err != nil {
+		return cfg, err
+	}
+	if cfg.MaxBytes, err = envInt("KAFKA_MAX_BYTES"); err != nil {
+		return cfg, err
+	}
+	if cfg.MaxWait, err = envDuration("KAFKA_MAX_WAIT"); err != nil {
+		return cfg, err
+	}
+	if cfg.SessionTimeout, err = envDuration("KAFKA_SESSION_TIMEOUT"); err != nil {
+		return cfg, err
+	}
+	if cfg.TenantTopics, err = kafka.ParseTenantTopics(os.Getenv("KAFKA_TENANT_TOPICS")); err != nil {
+		return cfg, err
+	}
+	return cfg, cfg.Validate()
+}
+
+func loadReconciliation() (validation.Reconciliation, error) {
+	var rec validation.Reconciliation
+	var err error
+	if rec.Mode, err = validation.ParseReconcileMode(os.Getenv("RECONCILE_MODE")); err != nil {
+		return rec, err
+	}
+	tolerance, err := envInt("RECONCILE_TOLERANCE")
 	if err != nil {
-		return nil, fmt.Errorf("could not initialize database: %w", err)
+		return rec, err
+	}
+	if tolerance < 0 {
+		return rec, errors.New("RECONCILE_TOLERANCE must not be negative")
 	}
+	rec.Tolerance = money.Amount(tolerance)
+	return rec, nil
+}
 
-	orderCache := cache.NewOrderCache()
+// loadRetention читает политику хранения: RETENTION_DAYS (0 — хранить
+// вечно), RETENTION_MODE и RETENTION_INTERVAL.
+func loadRetention() (RetentionConfig, error) {
+	cfg := RetentionConfig{Policy: service.RetentionPolicy{Mode: os.Getenv("RETENTION_MODE")}}
+	if cfg.Policy.Mode == "" {
+		cfg.Policy.Mode = service.RetentionArchive
+	}
+	days, err := envInt("RETENTION_DAYS")
+	if err != nil {
+		return cfg, err
+	}
+	cfg.Policy.MaxAge = time.Duration(days) * 24 * time.Hour
+	if cfg.Interval, err = envDuration("RETENTION_INTERVAL"); err != nil {
+		return cfg, err
+	}
+	return cfg, cfg.Policy.Validate()
+}
+
+func loadAuth() (AuthConfig, error) {
+	cfg := AuthConfig{
+		JWKSFile: os.Getenv("JWT_JWKS_FILE"),
+		JWKSURL:  os.Getenv("JWT_JWKS_URL"),
+		Issuer:   os.Getenv("JWT_ISSUER"),
+		Audience: os.Getenv("JWT_AUDIENCE"),
+	}
+	var err error
+	if cfg.Disabled, err = envBool("AUTH_DISABLED"); err != nil {
+		return cfg, err
+	}
+	if cfg.AllowNoTenant, err = envBool("AUTH_ALLOW_NO_TENANT"); err != nil {
+		return cfg, err
+	}
+	if cfg.JWKSRefresh, err = envDuration("JWT_JWKS_REFRESH"); err != nil {
+		return cfg, err
+	}
+	if cfg.JWKSFile != "" && cfg.JWKSURL != "" {
+		return cfg, errors.New("set either JWT_JWKS_FILE or JWT_JWKS_URL, not both")
+	}
+	return cfg, nil
+}
 
+// newAuthenticator собирает проверку учетных данных для HTTP API. nil
+// означает, что аутентификация выключена.
+func newAuthenticator(cfg AuthConfig, apiKeys service.APIKeyService) (*auth.Authenticator, error) {
+	if cfg.Disabled {
+		log.Println("AUTH_DISABLED is set, the HTTP API is not protected")
+		return nil, nil
+	}
+	authenticator := &auth.Authenticator{APIKeys: apiKeys, AllowNoTenant: cfg.AllowNoTenant}
+	if cfg.AllowNoTenant {
+		log.Println("AUTH_ALLOW_NO_TENANT is set, credentials without a tenant can access all tenants")
+	}
+	var keys auth.KeySet
+	switch {
+	case cfg.JWKSFile != "":
+		fileKeys, err := auth.LoadJWKSFile(cfg.JWKSFile)
+		if err != nil {
+			return nil, fmt.Errorf("could not load JWKS: %w", err)
+		}
+		keys = fileKeys
+	case cfg.JWKSURL != "":
+		keys = auth.NewRemoteKeySet(cfg.JWKSURL, cfg.JWKSRefresh)
+	default:
+		return authenticator, nil
+	}
+	authenticator.JWT = &auth.JWTVerifier{
+		Keys:     keys,
+		Issuer:   cfg.Issuer,
+		Audience: cfg.Audience,
+		Leeway:   time.Minute,
+	}
+	return authenticator, nil
+}
+
+// loadPIICipher читает ключи шифрования данных получателя из файла
+// PII_KEYFILE. Без него шифрование выключено.
+func loadPIICipher() (*pii.Cipher, error) {
+	path := os.Getenv("PII_KEYFILE")
+	if path == "" {
+		return nil, nil
+	}
+	keys, err := pii.LoadLocalKeyProvider(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not load PII keyfile: %w", err)
+	}
+	return pii.NewCipher(keys), nil
+}
+
+func setupPII(c *pii.Cipher) {
+	pii.SetCipher(c)
+	if c == nil {
+		log.Println("PII_KEYFILE is not set, delivery personal data is stored unencrypted")
+	}
+}
+
+// newOrderStore подключается к БД через initDB (db.InitDB или db.Open) и
+// собирает сервис заказов без Kafka и HTTP. Это общая часть NewApp и
+// подкоманд CLI, которым нужны только заказы.
+func newOrderStore(initDB func(dsn string) (*gorm.DB, error), dsn string, rec validation.Reconciliation, orderCache *cache.OrderCache) (*gorm.DB, service.OrderService, error) {
+	database, err := initDB(dsn)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not initialize database: %w", err)
+	}
 	orderRepo := repository.NewOrderRepository(database)
-	orderService := service.NewOrderService(orderRepo, orderCache)
+	return database, service.NewOrderServiceWithReconciliation(orderRepo, orderCache, rec), nil
+}
+
+func NewApp(cfg *Config) (*App, error) {
+	// В режиме dry-run схема БД не меняется, а маршруты, которые пишут в
+	// БД, отвечают 409 (см. setupRouter): сервис только читает заказы.
+	initDB := db.InitDB
+	if cfg.Kafka.DryRun {
+		initDB = db.Open
+	}
+	setupPII(cfg.PII)
+	orderCache := cache.NewOrderCacheWithCapacity(cfg.Runtime.CacheCapacity)
+	database, orderService, err := newOrderStore(initDB, cfg.DSN, cfg.Reconciliation, orderCache)
+	if err != nil {
+		return nil, err
+	}
+
 	orderHandler := handler.NewOrderHandler(orderService)
+	customerHandler := handler.NewCustomerHandler(service.NewCustomerService(repository.NewCustomerRepository(database), orderCache))
+	searchHandler := handler.NewSearchHandler(service.NewSearchService(repository.NewSearchRepository(database)))
+	reportHandler := handler.NewReportHandler(service.NewReportService(repository.NewReportRepository(database), cfg.ReportCacheTTL))
 
 	if err := orderService.RestoreCache(); err != nil {
 		return nil, fmt.Errorf("failed to restore cache: %w", err)
 	}
 
-	kafkaConsumer, err := kafka.InitKafkaConsumer(cfg.KafkaBrokers, cfg.KafkaTopic, cfg.KafkaGroupID, orderService)
+	kafkaConsumer, err := kafka.InitKafkaConsumer(cfg.Kafka, orderService)
 	if err != nil {
 		return nil, fmt.Errorf("could not initialize Kafka consumer: %w", err)
 	}
+	kafkaConsumer.SetConcurrency(cfg.Runtime.ConsumerConcurrency)
+
+	eventWriter, err := kafka.NewEventWriter(cfg.Kafka, cfg.Outbox.Topic, cfg.Outbox.TenantTopics)
+	if err != nil {
+		return nil, fmt.Errorf("could not initialize Kafka event writer: %w", err)
+	}
+	relay := outbox.NewRelay(repository.NewOutboxRepository(database), eventWriter, cfg.Outbox.PollInterval, cfg.Outbox.BatchSize)
 
-	router := setupRouter(orderHandler)
-	server := &http.Server{
+	apiKeyService := service.NewAPIKeyService(repository.NewAPIKeyRepository(database))
+	authenticator, err := newAuthenticator(cfg.Auth, apiKeyService)
+	if err != nil {
+		return nil, err
+	}
+
+	retentionService := service.NewRetentionService(repository.NewRetentionRepository(database), orderCache)
+
+	app := &App{
+		Config:      cfg,
+		DB:          database,
+		Cache:       orderCache,
+		Service:     orderService,
+		Consumer:    kafkaConsumer,
+		EventWriter: eventWriter,
+		Relay:       relay,
+		Retention:   retention.NewJob(retentionService, cfg.Retention.Policy, cfg.Retention.Interval),
+	}
+	app.reloader = &reloader{app: app}
+
+	adminHandler := handler.NewAdminHandler(app.reloader.Reload, kafkaConsumer, orderCache.Stats)
+	apiKeyHandler := handler.NewAPIKeyHandler(apiKeyService)
+	router := setupRouter(cfg.Kafka.DryRun, authenticator, orderHandler, customerHandler, searchHandler, reportHandler, adminHandler, apiKeyHandler)
+	app.Server = &http.Server{
 		Addr:    ":" + cfg.Port,
 		Handler: router,
 	}
 
-	return &App{
-		Config:   cfg,
-		DB:       database,
-		Cache:    orderCache,
-		Service:  orderService,
-		Consumer: kafkaConsumer,
-		Server:   server,
-	}, nil
+	return app, nil
+}
+
+// WatchReload перечитывает настройки при каждом SIGHUP до отмены ctx.
+func (a *App) WatchReload(ctx context.Context) {
+	sighup := make(chan os.Signal, 1)
+	signal.Notify(sighup, syscall.SIGHUP)
+	defer signal.Stop(sighup)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-sighup:
+			log.Println("SIGHUP received, reloading settings...")
+			_, _ = a.reloader.Reload()
+		}
+	}
 }
 
 func (a *App) Run(ctx context.Context) {
 	log.Println("Starting application...")
-	go a.Consumer.Run(ctx)
+	a.consumerDone = make(chan struct{})
+	go func() {
+		defer close(a.consumerDone)
+		a.Consumer.Run(ctx)
+	}()
+	if !a.Config.Kafka.DryRun {
+		go a.Relay.Run(ctx)
+		if a.Config.Retention.Policy.Enabled() {
+			go a.Retention.Run(ctx)
+		}
+	}
+	go a.WatchReload(ctx)
 	go func() {
 		log.Printf("Server starting and listening on port %s", a.Config.Port)
 		if err := a.Server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
@@ -130,26 +465,99 @@ func (a *App) Shutdown() {
 	if err := a.Server.Shutdown(shutdownCtx); err != nil {
 		log.Printf("HTTP server shutdown failed: %v", err)
 	}
+	if err := a.EventWriter.Close(); err != nil {
+		log.Printf("Kafka event writer close failed: %v", err)
+	}
+	select {
+	case <-a.consumerDone:
+	case <-shutdownCtx.Done():
+		log.Println("Kafka consumer did not stop in time")
+	}
+	if summary := a.Consumer.ValidationReport(); summary != nil {
+		summary.WriteText(os.Stdout)
+		_ = json.NewEncoder(os.Stdout).Encode(summary)
+	}
 }
 
-func setupRouter(orderHandler *handler.OrderHandler) *chi.Mux {
+func setupRouter(
+	dryRun bool,
+	authenticator *auth.Authenticator,
+	orderHandler *handler.OrderHandler,
+	customerHandler *handler.CustomerHandler,
+	searchHandler *handler.SearchHandler,
+	reportHandler *handler.ReportHandler,
+	adminHandler *handler.AdminHandler,
+	apiKeyHandler *handler.APIKeyHandler,
+) *chi.Mux {
+	writes := handler.ReadOnly(dryRun)
 	r := chi.NewRouter()
 	r.Use(chimiddleware.Logger)
 	r.Use(chimiddleware.Recoverer)
-	r.Get("/order/{id}", orderHandler.GetOrderByIDHandler)
+	r.Group(func(r chi.Router) {
+		if authenticator != nil {
+			r.Use(authenticator.Middleware)
+		} else {
+			r.Use(auth.Anonymous)
+		}
+		r.Group(func(r chi.Router) {
+			r.Use(auth.Require(auth.ScopeOrdersRead))
+			r.Get("/order/{id}", orderHandler.GetOrderByIDHandler)
+			r.Get("/track/{track_number}", orderHandler.TrackHandler)
+			r.Get("/orders/export", orderHandler.ExportOrdersHandler)
+			r.Get("/customers/{id}/orders", customerHandler.CustomerOrdersHandler)
+			r.Get("/customers/{id}/summary", customerHandler.CustomerSummaryHandler)
+			r.Get("/search", searchHandler.SearchHandler)
+			r.Get("/reports/orders", reportHandler.OrdersReportHandler)
+		})
+		r.Group(func(r chi.Router) {
+			r.Use(auth.Require(auth.ScopeOrdersWrite))
+			r.With(writes).Delete("/order/{id}", orderHandler.DeleteOrderHandler)
+			r.With(writes).Post("/orders/import", orderHandler.ImportOrdersHandler)
+		})
+		r.Group(func(r chi.Router) {
+			r.Use(auth.Require(auth.ScopeOrdersAdmin))
+			r.With(writes).Delete("/customers/{id}/personal-data", customerHandler.EraseCustomerHandler)
+		})
+
+		// Администрирование затрагивает все площадки сразу.
+		r.Group(func(r chi.Router) {
+			r.Use(auth.Require(auth.ScopeOrdersAdmin), auth.RequireAllTenants)
+			r.Post("/admin/reload", adminHandler.ReloadHandler)
+			r.Post("/admin/consumer/pause", adminHandler.PauseConsumerHandler)
+			r.Post("/admin/consumer/resume", adminHandler.ResumeConsumerHandler)
+			r.Post("/admin/consumer/seek", adminHandler.SeekConsumerHandler)
+			r.Post("/admin/consumer/replay", adminHandler.ReplayConsumerHandler)
+			r.Get("/admin/consumer/position", adminHandler.ConsumerPositionHandler)
+			r.Get("/admin/dry-run/report", adminHandler.DryRunReportHandler)
+			r.Get("/admin/cache/stats", adminHandler.CacheStatsHandler)
+			r.With(writes).Post("/admin/api-keys", apiKeyHandler.IssueAPIKeyHandler)
+			r.Get("/admin/api-keys", apiKeyHandler.ListAPIKeysHandler)
+			r.With(writes).Delete("/admin/api-keys/{id}", apiKeyHandler.RevokeAPIKeyHandler)
+			r.Handle("/debug/vars", expvar.Handler())
+		})
+	})
+	// Документация и страница просмотра заказа открыты: данные страница
+	// запрашивает через API с ключом пользователя.
 	r.Get("/swagger/*", httpSwagger.Handler(httpSwagger.URL("/swagger/doc.json")))
 	r.Handle("/*", http.FileServer(http.Dir("web")))
 	return r
 }
 
-func main() {
+// runServe запускает сервис: консьюмер, outbox relay и HTTP API.
+func runServe(args []string) error {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	_ = fs.Parse(args)
+
 	cfg, err := NewConfig()
 	if err != nil {
-		log.Fatalf("Configuration error: %v", err)
+		return fmt.Errorf("configuration error: %w", err)
+	}
+	if err := logger.Setup(cfg.Runtime.LogLevel); err != nil {
+		return fmt.Errorf("logger setup error: %w", err)
 	}
 	app, err := NewApp(cfg)
 	if err != nil {
-		log.Fatalf("Application initialization failed: %v", err)
+		return fmt.Errorf("application initialization failed: %w", err)
 	}
 	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer stop()
@@ -157,4 +565,11 @@ func main() {
 	<-ctx.Done()
 	app.Shutdown()
 	log.Println("Application shut down gracefully.")
+	return nil
+}
+
+func main() {
+	if err := runCLI(os.Args[1:]); err != nil {
+		log.Fatal(err)
+	}
 }
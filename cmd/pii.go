@@ -0,0 +1,55 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"orderkeeper/internal/pii"
+	"orderkeeper/internal/repository"
+	"orderkeeper/internal/service"
+	"os"
+	"time"
+)
+
+// runPIIRotate добавляет ключ в файл ключей. Работающие инстансы начнут
+// шифровать им новые значения после перезапуска; старые значения
+// перешифровывает pii reencrypt.
+func runPIIRotate(args []string) error {
+	fs := flag.NewFlagSet("pii rotate", flag.ExitOnError)
+	keyfile := fs.String("keyfile", os.Getenv("PII_KEYFILE"), "keyfile to update, created if missing")
+	_ = fs.Parse(args)
+	if *keyfile == "" {
+		return errors.New("keyfile is not set, use -keyfile or PII_KEYFILE")
+	}
+
+	id, err := pii.Rotate(*keyfile, time.Now())
+	if err != nil {
+		return err
+	}
+	fmt.Printf("current key is now %s\n", id)
+	return nil
+}
+
+func runPIIReencrypt(args []string) error {
+	fs := flag.NewFlagSet("pii reencrypt", flag.ExitOnError)
+	batchSize := fs.Int("batch-size", service.ReencryptBatchSize, "deliveries per transaction")
+	_ = fs.Parse(args)
+
+	database, _, err := openOrderStore(false)
+	if err != nil {
+		return err
+	}
+	if pii.Current() == nil {
+		return errors.New("PII_KEYFILE is not set")
+	}
+	svc := service.NewEncryptionService(repository.NewEncryptionRepository(database))
+	n, err := svc.ReencryptDeliveries(*batchSize, func(total int) {
+		log.Printf("Re-encrypted %d deliveries", total)
+	})
+	if err != nil {
+		return fmt.Errorf("re-encryption stopped after %d deliveries: %w", n, err)
+	}
+	fmt.Printf("%d deliveries re-encrypted with key %s\n", n, pii.Current().KeyID())
+	return nil
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"orderkeeper/internal/handler"
+	"orderkeeper/internal/logger"
+	"orderkeeper/internal/metrics"
+	"os"
+	"strconv"
+	"sync"
+
+	"github.com/joho/godotenv"
+)
+
+const (
+	defaultCacheCapacity       = 1000
+	defaultConsumerConcurrency = 1
+)
+
+// RuntimeSettings — подмножество настроек, которые можно поменять без
+// перезапуска: по SIGHUP или через POST /admin/reload.
+type RuntimeSettings struct {
+	CacheCapacity       int    `json:"cache_capacity"`
+	LogLevel            string `json:"log_level"`
+	ConsumerConcurrency int    `json:"consumer_concurrency"`
+}
+
+// LoadRuntimeSettings читает настройки из окружения. Если задан
+// CONFIG_FILE, значения из него (в формате .env) имеют приоритет — так их
+// можно менять у работающего процесса.
+func LoadRuntimeSettings() (RuntimeSettings, error) {
+	env := map[string]string{}
+	if path := os.Getenv("CONFIG_FILE"); path != "" {
+		fileEnv, err := godotenv.Read(path)
+		if err != nil {
+			return RuntimeSettings{}, fmt.Errorf("could not read config file %s: %w", path, err)
+		}
+		env = fileEnv
+	}
+	lookup := func(key string) string {
+		if v, ok := env[key]; ok {
+			return v
+		}
+		return os.Getenv(key)
+	}
+
+	settings := RuntimeSettings{
+		CacheCapacity:       defaultCacheCapacity,
+		LogLevel:            lookup("LOG_LEVEL"),
+		ConsumerConcurrency: defaultConsumerConcurrency,
+	}
+	if settings.LogLevel == "" {
+		settings.LogLevel = "info"
+	}
+	if _, err := logger.ParseLevel(settings.LogLevel); err != nil {
+		return RuntimeSettings{}, err
+	}
+	if v := lookup("CACHE_CAPACITY"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			return RuntimeSettings{}, fmt.Errorf("CACHE_CAPACITY must be a positive integer, got %q", v)
+		}
+		settings.CacheCapacity = n
+	}
+	if v := lookup("CONSUMER_CONCURRENCY"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			return RuntimeSettings{}, fmt.Errorf("CONSUMER_CONCURRENCY must be a positive integer, got %q", v)
+		}
+		settings.ConsumerConcurrency = n
+	}
+	return settings, nil
+}
+
+type reloader struct {
+	mu  sync.Mutex
+	app *App
+}
+
+// Reload перечитывает RuntimeSettings и применяет их к кешу, логгеру и
+// консьюмеру. Настройки проверяются целиком до применения, так что при
+// ошибке приложение продолжает работать со старыми значениями. Без
+// CONFIG_FILE перечитывать нечего, и Reload возвращает
+// handler.ErrNoConfigFile.
+func (r *reloader) Reload() (any, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if os.Getenv("CONFIG_FILE") == "" {
+		log.Printf("Settings reload skipped: %v", handler.ErrNoConfigFile)
+		return nil, handler.ErrNoConfigFile
+	}
+
+	settings, err := LoadRuntimeSettings()
+	metrics.RecordReload(err)
+	if err != nil {
+		log.Printf("Settings reload failed: %v", err)
+		return nil, err
+	}
+
+	r.app.apply(settings)
+	log.Printf("Settings reloaded: cache_capacity=%d log_level=%s consumer_concurrency=%d",
+		settings.CacheCapacity, settings.LogLevel, settings.ConsumerConcurrency)
+	return settings, nil
+}
+
+func (a *App) apply(settings RuntimeSettings) {
+	_ = logger.SetLevel(settings.LogLevel)
+	a.Cache.Resize(settings.CacheCapacity)
+	a.Consumer.SetConcurrency(settings.ConsumerConcurrency)
+	a.Config.Runtime = settings
+}
@@ -0,0 +1,37 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+)
+
+// APIKeyPrefix начинает каждый API-ключ, чтобы отличать его от JWT и
+// находить случайно опубликованные ключи.
+const APIKeyPrefix = "okk_"
+
+// displayPrefixLen — сколько первых символов ключа хранится открыто, чтобы
+// ключ можно было узнать в списке.
+const displayPrefixLen = len(APIKeyPrefix) + 8
+
+// GenerateAPIKey создает ключ и возвращает его вместе с открытым префиксом
+// и хешем для хранения. Сам ключ нигде не сохраняется.
+func GenerateAPIKey() (key, prefix, hash string) {
+	secret := make([]byte, 32)
+	_, _ = rand.Read(secret)
+	key = APIKeyPrefix + base64.RawURLEncoding.EncodeToString(secret)
+	return key, key[:displayPrefixLen], HashAPIKey(key)
+}
+
+// HashAPIKey — SHA-256 ключа. У ключа 256 бит энтропии, поэтому медленный
+// хеш для паролей не нужен, а поиск по хешу остается индексным.
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+func IsAPIKey(token string) bool {
+	return strings.HasPrefix(token, APIKeyPrefix)
+}
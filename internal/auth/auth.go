@@ -0,0 +1,97 @@
+// Package auth проверяет учетные данные HTTP-запросов: API-ключи, хеши
+// которых хранятся в БД, и JWT, подписанные ключами из JWKS.
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+)
+
+const (
+	MethodAPIKey = "api_key"
+	MethodJWT    = "jwt"
+	// MethodNone — запрос при выключенной аутентификации.
+	MethodNone = "none"
+)
+
+// Области доступа. orders:admin включает orders:write, а тот — orders:read;
+// pii:read от них не зависит и открывает персональные данные получателя.
+const (
+	ScopeOrdersRead  = "orders:read"
+	ScopeOrdersWrite = "orders:write"
+	ScopeOrdersAdmin = "orders:admin"
+	ScopePIIRead     = "pii:read"
+)
+
+var Scopes = []string{ScopeOrdersRead, ScopeOrdersWrite, ScopeOrdersAdmin, ScopePIIRead}
+
+// DefaultScope получают ключи, выпущенные без областей, в том числе ключи,
+// выпущенные до их появления.
+const DefaultScope = ScopeOrdersRead
+
+// AllTenants — явная привязка учетных данных ко всем площадкам. Пустая
+// площадка «всех» не означает: такие учетные данные отклоняются.
+const AllTenants = "*"
+
+// implied — области, которые дает каждая область помимо себя самой.
+var implied = map[string][]string{
+	ScopeOrdersWrite: {ScopeOrdersRead},
+	ScopeOrdersAdmin: {ScopeOrdersWrite, ScopeOrdersRead},
+}
+
+// ValidateScopes проверяет, что все области известны и есть хотя бы одна.
+func ValidateScopes(scopes []string) error {
+	if len(scopes) == 0 {
+		return errors.New("at least one scope is required")
+	}
+	for _, s := range scopes {
+		if !slices.Contains(Scopes, s) {
+			return fmt.Errorf("unknown scope %q", s)
+		}
+	}
+	return nil
+}
+
+var (
+	ErrNoCredentials      = errors.New("authentication required")
+	ErrInvalidCredentials = errors.New("invalid credentials")
+	// ErrNoTenant — учетные данные верны, но не привязаны ни к площадке,
+	// ни явно ко всем площадкам.
+	ErrNoTenant = errors.New("credentials are not bound to a tenant")
+)
+
+// Principal — тот, от чьего имени выполняется запрос.
+type Principal struct {
+	// Subject — имя API-ключа или claim sub токена.
+	Subject string   `json:"subject"`
+	Method  string   `json:"method"`
+	Scopes  []string `json:"scopes"`
+	// Tenant — площадка (Order.Entry), к заказам которой ограничен доступ,
+	// или AllTenants.
+	Tenant string `json:"tenant,omitempty"`
+}
+
+// HasScope сообщает, дает ли набор областей Principal область scope, в том
+// числе через более широкую.
+func (p Principal) HasScope(scope string) bool {
+	for _, s := range p.Scopes {
+		if s == scope || slices.Contains(implied[s], scope) {
+			return true
+		}
+	}
+	return false
+}
+
+type contextKey struct{}
+
+func NewContext(ctx context.Context, p Principal) context.Context {
+	return context.WithValue(ctx, contextKey{}, p)
+}
+
+// FromContext возвращает Principal запроса, прошедшего Middleware.
+func FromContext(ctx context.Context) (Principal, bool) {
+	p, ok := ctx.Value(contextKey{}).(Principal)
+	return p, ok
+}
@@ -0,0 +1,402 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var b64url = base64.RawURLEncoding
+
+func encodeSegment(t *testing.T, v any) string {
+	t.Helper()
+	data, err := json.Marshal(v)
+	require.NoError(t, err)
+	return b64url.EncodeToString(data)
+}
+
+// sign собирает JWS с подписью signer.
+func sign(t *testing.T, header, claims map[string]any, signer func([]byte) []byte) string {
+	t.Helper()
+	signed := encodeSegment(t, header) + "." + encodeSegment(t, claims)
+	return signed + "." + b64url.EncodeToString(signer([]byte(signed)))
+}
+
+func rsaSigner(t *testing.T, key *rsa.PrivateKey) func([]byte) []byte {
+	return func(data []byte) []byte {
+		digest := sha256.Sum256(data)
+		sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+		require.NoError(t, err)
+		return sig
+	}
+}
+
+func writeJWKS(t *testing.T, rsaKey *rsa.PrivateKey, ecKey *ecdsa.PrivateKey, edKey ed25519.PublicKey) []byte {
+	t.Helper()
+	pad := func(n *big.Int, size int) string { return b64url.EncodeToString(n.FillBytes(make([]byte, size))) }
+	data, err := json.Marshal(map[string]any{"keys": []map[string]string{
+		{"kty": "RSA", "kid": "rsa-1", "use": "sig", "n": b64url.EncodeToString(rsaKey.N.Bytes()), "e": "AQAB"},
+		{"kty": "EC", "kid": "ec-1", "crv": "P-256", "x": pad(ecKey.X, 32), "y": pad(ecKey.Y, 32)},
+		{"kty": "OKP", "kid": "ed-1", "crv": "Ed25519", "x": b64url.EncodeToString(edKey)},
+		{"kty": "RSA", "kid": "enc-1", "use": "enc", "n": "AQAB", "e": "AQAB"},
+	}})
+	require.NoError(t, err)
+	return data
+}
+
+func TestJWTVerifier(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	edPub, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	require.NoError(t, os.WriteFile(path, writeJWKS(t, rsaKey, ecKey, edPub), 0o600))
+	keys, err := LoadJWKSFile(path)
+	require.NoError(t, err)
+	assert.Len(t, keys, 3)
+
+	now := time.Unix(1_700_000_000, 0)
+	verifier := &JWTVerifier{Keys: keys, Issuer: "https://idp.example.com", Audience: "orderkeeper", now: func() time.Time { return now }}
+	claims := func() map[string]any {
+		return map[string]any{
+			"sub": "support-bot", "iss": "https://idp.example.com", "aud": []string{"other", "orderkeeper"},
+			"exp": now.Add(time.Hour).Unix(), "nbf": now.Add(-time.Minute).Unix(),
+		}
+	}
+
+	t.Run("RS256", func(t *testing.T) {
+		token := sign(t, map[string]any{"alg": "RS256", "kid": "rsa-1"}, claims(), rsaSigner(t, rsaKey))
+		got, err := verifier.Verify(context.Background(), token)
+		assert.NoError(t, err)
+		assert.Equal(t, "support-bot", got.Subject)
+		assert.Empty(t, got.Scopes())
+	})
+
+	t.Run("scope, scp and tenant claims", func(t *testing.T) {
+		c := claims()
+		c["scope"] = "orders:read pii:read"
+		c["scp"] = []string{"orders:write"}
+		c["tenant"] = "WBIL"
+		got, err := verifier.Verify(context.Background(), sign(t, map[string]any{"alg": "RS256", "kid": "rsa-1"}, c, rsaSigner(t, rsaKey)))
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"orders:read", "pii:read", "orders:write"}, got.Scopes())
+		assert.Equal(t, "WBIL", got.Tenant)
+	})
+
+	t.Run("ES256", func(t *testing.T) {
+		token := sign(t, map[string]any{"alg": "ES256", "kid": "ec-1"}, claims(), func(data []byte) []byte {
+			digest := sha256.Sum256(data)
+			r, s, err := ecdsa.Sign(rand.Reader, ecKey, digest[:])
+			require.NoError(t, err)
+			return append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+		})
+		_, err := verifier.Verify(context.Background(), token)
+		assert.NoError(t, err)
+	})
+
+	t.Run("EdDSA", func(t *testing.T) {
+		token := sign(t, map[string]any{"alg": "EdDSA", "kid": "ed-1"}, claims(), func(data []byte) []byte {
+			return ed25519.Sign(edKey, data)
+		})
+		_, err := verifier.Verify(context.Background(), token)
+		assert.NoError(t, err)
+	})
+
+	rejected := []struct {
+		name   string
+		header map[string]any
+		claims func(map[string]any)
+		signer func([]byte) []byte
+	}{
+		{"expired", nil, func(c map[string]any) { c["exp"] = now.Add(-time.Hour).Unix() }, nil},
+		{"not yet valid", nil, func(c map[string]any) { c["nbf"] = now.Add(time.Hour).Unix() }, nil},
+		{"without exp", nil, func(c map[string]any) { delete(c, "exp") }, nil},
+		{"other issuer", nil, func(c map[string]any) { c["iss"] = "https://evil.example.com" }, nil},
+		{"other audience", nil, func(c map[string]any) { c["aud"] = "billing" }, nil},
+		{"without subject", nil, func(c map[string]any) { delete(c, "sub") }, nil},
+		{"alg none", map[string]any{"alg": "none", "kid": "rsa-1"}, nil, func([]byte) []byte { return nil }},
+		{"HMAC", map[string]any{"alg": "HS256", "kid": "rsa-1"}, nil, nil},
+		{"unknown kid", map[string]any{"alg": "RS256", "kid": "rsa-2"}, nil, nil},
+		{"key of another type", map[string]any{"alg": "RS256", "kid": "ec-1"}, nil, nil},
+		{"tampered signature", nil, nil, func(data []byte) []byte {
+			sig := rsaSigner(t, rsaKey)(data)
+			sig[0] ^= 1
+			return sig
+		}},
+	}
+	for _, tc := range rejected {
+		t.Run("rejects "+tc.name, func(t *testing.T) {
+			header := map[string]any{"alg": "RS256", "kid": "rsa-1"}
+			if tc.header != nil {
+				header = tc.header
+			}
+			c := claims()
+			if tc.claims != nil {
+				tc.claims(c)
+			}
+			signer := rsaSigner(t, rsaKey)
+			if tc.signer != nil {
+				signer = tc.signer
+			}
+			_, err := verifier.Verify(context.Background(), sign(t, header, c, signer))
+			assert.ErrorIs(t, err, ErrInvalidCredentials)
+		})
+	}
+}
+
+func TestRemoteKeySet(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	edPub, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	jwks := writeJWKS(t, rsaKey, ecKey, edPub)
+
+	fetches := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches++
+		_, _ = w.Write(jwks)
+	}))
+	defer srv.Close()
+
+	keys := NewRemoteKeySet(srv.URL, time.Hour)
+	_, err = keys.Key(context.Background(), "rsa-1")
+	assert.NoError(t, err)
+	_, err = keys.Key(context.Background(), "ec-1")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, fetches)
+
+	// Неизвестный kid сразу после загрузки не вызывает новый запрос.
+	_, err = keys.Key(context.Background(), "rotated")
+	assert.Error(t, err)
+	assert.Equal(t, 1, fetches)
+}
+
+func TestRemoteKeySet_Unavailable(t *testing.T) {
+	var fetches atomic.Int32
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches.Add(1)
+		<-release
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+	keys := NewRemoteKeySet(srv.URL, time.Hour)
+
+	// Одновременные запросы ждут одну загрузку.
+	var wg sync.WaitGroup
+	for range 5 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := keys.Key(context.Background(), "rsa-1")
+			assert.Error(t, err)
+		}()
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	assert.Equal(t, int32(1), fetches.Load())
+
+	// После неудачи JWKS не перезапрашивается раньше minJWKSRefresh.
+	_, err := keys.Key(context.Background(), "forged")
+	assert.ErrorContains(t, err, "503")
+	assert.Equal(t, int32(1), fetches.Load())
+}
+
+type fakeAPIKeys map[string]Principal
+
+func (f fakeAPIKeys) AuthenticateAPIKey(key string) (Principal, error) {
+	if p, ok := f[key]; ok {
+		return p, nil
+	}
+	if key == APIKeyPrefix+"broken" {
+		return Principal{}, errors.New("database is down")
+	}
+	return Principal{}, fmt.Errorf("%w: unknown API key", ErrInvalidCredentials)
+}
+
+func TestMiddleware(t *testing.T) {
+	key, prefix, hash := GenerateAPIKey()
+	assert.True(t, IsAPIKey(key))
+	assert.Equal(t, key[:len(prefix)], prefix)
+	assert.Equal(t, HashAPIKey(key), hash)
+
+	authenticator := &Authenticator{APIKeys: fakeAPIKeys{
+		key: {Subject: "billing", Method: MethodAPIKey, Tenant: AllTenants},
+	}}
+	handler := authenticator.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p, ok := FromContext(r.Context())
+		assert.True(t, ok)
+		_, _ = w.Write([]byte(p.Subject + "/" + p.Method))
+	}))
+	do := func(header, value string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/order/1", nil)
+		if header != "" {
+			req.Header.Set(header, value)
+		}
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+
+	t.Run("X-API-Key", func(t *testing.T) {
+		rr := do("X-API-Key", key)
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "billing/api_key", rr.Body.String())
+	})
+
+	t.Run("bearer API key", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, do("Authorization", "Bearer "+key).Code)
+	})
+
+	t.Run("no credentials", func(t *testing.T) {
+		rr := do("", "")
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+		assert.Contains(t, rr.Header().Get("WWW-Authenticate"), "Bearer")
+	})
+
+	t.Run("unknown key", func(t *testing.T) {
+		assert.Equal(t, http.StatusUnauthorized, do("X-API-Key", APIKeyPrefix+"unknown").Code)
+	})
+
+	t.Run("JWT when JWKS is not configured", func(t *testing.T) {
+		assert.Equal(t, http.StatusUnauthorized, do("Authorization", "Bearer a.b.c").Code)
+	})
+
+	t.Run("store failure", func(t *testing.T) {
+		assert.Equal(t, http.StatusInternalServerError, do("X-API-Key", APIKeyPrefix+"broken").Code)
+	})
+}
+
+func TestAuthenticator_Tenant(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	edPub, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	require.NoError(t, os.WriteFile(path, writeJWKS(t, rsaKey, ecKey, edPub), 0o600))
+	keys, err := LoadJWKSFile(path)
+	require.NoError(t, err)
+
+	token := func(tenant string) string {
+		claims := map[string]any{"sub": "support-bot", "exp": time.Now().Add(time.Hour).Unix()}
+		if tenant != "" {
+			claims["tenant"] = tenant
+		}
+		return sign(t, map[string]any{"alg": "RS256", "kid": "rsa-1"}, claims, rsaSigner(t, rsaKey))
+	}
+	legacyKey, _, _ := GenerateAPIKey()
+	boundKey, _, _ := GenerateAPIKey()
+	authenticator := &Authenticator{
+		APIKeys: fakeAPIKeys{
+			legacyKey: {Subject: "legacy", Method: MethodAPIKey},
+			boundKey:  {Subject: "partner", Method: MethodAPIKey, Tenant: "WBIL"},
+		},
+		JWT: &JWTVerifier{Keys: keys},
+	}
+	handler := authenticator.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p, _ := FromContext(r.Context())
+		_, _ = w.Write([]byte(p.Tenant))
+	}))
+	do := func(credentials string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/order/1", nil)
+		req.Header.Set("Authorization", "Bearer "+credentials)
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+
+	t.Run("bound credentials", func(t *testing.T) {
+		for _, credentials := range []string{boundKey, token("WBIL")} {
+			rr := do(credentials)
+			assert.Equal(t, http.StatusOK, rr.Code)
+			assert.Equal(t, "WBIL", rr.Body.String())
+		}
+		rr := do(token(AllTenants))
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, AllTenants, rr.Body.String())
+	})
+
+	t.Run("credentials without tenant are rejected", func(t *testing.T) {
+		for _, credentials := range []string{legacyKey, token("")} {
+			rr := do(credentials)
+			assert.Equal(t, http.StatusForbidden, rr.Code)
+			assert.Contains(t, rr.Body.String(), ErrNoTenant.Error())
+		}
+	})
+
+	t.Run("credentials without tenant are allowed by config", func(t *testing.T) {
+		authenticator.AllowNoTenant = true
+		defer func() { authenticator.AllowNoTenant = false }()
+
+		for _, credentials := range []string{legacyKey, token("")} {
+			rr := do(credentials)
+			assert.Equal(t, http.StatusOK, rr.Code)
+			assert.Equal(t, AllTenants, rr.Body.String())
+		}
+	})
+}
+
+func TestScopes(t *testing.T) {
+	admin := Principal{Scopes: []string{ScopeOrdersAdmin}}
+	assert.True(t, admin.HasScope(ScopeOrdersRead))
+	assert.True(t, admin.HasScope(ScopeOrdersWrite))
+	assert.False(t, admin.HasScope(ScopePIIRead))
+
+	reader := Principal{Scopes: []string{ScopeOrdersRead, ScopePIIRead}}
+	assert.True(t, reader.HasScope(ScopePIIRead))
+	assert.False(t, reader.HasScope(ScopeOrdersWrite))
+
+	assert.NoError(t, ValidateScopes([]string{ScopeOrdersRead, ScopePIIRead}))
+	assert.Error(t, ValidateScopes([]string{"orders:delete"}))
+	assert.Error(t, ValidateScopes(nil))
+
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	do := func(handler http.Handler, principal *Principal) int {
+		req := httptest.NewRequest(http.MethodDelete, "/order/1", nil)
+		if principal != nil {
+			req = req.WithContext(NewContext(req.Context(), *principal))
+		}
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr.Code
+	}
+	requireWrite := Require(ScopeOrdersWrite)(ok)
+	assert.Equal(t, http.StatusOK, do(requireWrite, &admin))
+	assert.Equal(t, http.StatusForbidden, do(requireWrite, &reader))
+	assert.Equal(t, http.StatusUnauthorized, do(requireWrite, nil))
+	assert.Equal(t, http.StatusOK, do(Anonymous(requireWrite), nil))
+
+	allTenants := RequireAllTenants(ok)
+	assert.Equal(t, http.StatusOK, do(allTenants, &Principal{Scopes: admin.Scopes, Tenant: AllTenants}))
+	assert.Equal(t, http.StatusForbidden, do(allTenants, &admin))
+	assert.Equal(t, http.StatusForbidden, do(allTenants, &Principal{Scopes: admin.Scopes, Tenant: "WBIL"}))
+}
@@ -0,0 +1,250 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// KeySet отдает ключ проверки подписи JWT по kid.
+type KeySet interface {
+	Key(ctx context.Context, kid string) (crypto.PublicKey, error)
+}
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// ParseJWKS разбирает JWKS (RFC 7517). Поддерживаются ключи RSA, EC
+// (P-256, P-384, P-521) и OKP Ed25519; ключи шифрования (use=enc) и
+// неизвестных типов пропускаются.
+func ParseJWKS(data []byte) (map[string]crypto.PublicKey, error) {
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("invalid JWKS: %w", err)
+	}
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		key, err := k.publicKey()
+		if err != nil {
+			return nil, fmt.Errorf("JWKS key %q: %w", k.Kid, err)
+		}
+		if key != nil {
+			keys[k.Kid] = key
+		}
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("JWKS has no signing keys")
+	}
+	return keys, nil
+}
+
+func (k jwk) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		if !e.IsInt64() || e.Int64() < 3 {
+			return nil, errors.New("invalid RSA exponent")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		key := &ecdsa.PublicKey{Curve: curve, X: x, Y: y}
+		if _, err := key.ECDH(); err != nil {
+			return nil, errors.New("EC point is not on the curve")
+		}
+		return key, nil
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid Ed25519 key")
+		}
+		return ed25519.PublicKey(x), nil
+	}
+	return nil, nil
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil || len(b) == 0 {
+		return nil, errors.New("invalid base64url integer")
+	}
+	return new(big.Int).SetBytes(b), nil
+}
+
+// StaticKeySet — ключи, прочитанные один раз, например из файла.
+type StaticKeySet map[string]crypto.PublicKey
+
+func LoadJWKSFile(path string) (StaticKeySet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	keys, err := ParseJWKS(data)
+	return StaticKeySet(keys), err
+}
+
+func (s StaticKeySet) Key(_ context.Context, kid string) (crypto.PublicKey, error) {
+	return lookupKey(s, kid)
+}
+
+// lookupKey ищет ключ по kid; токен без kid подходит, только если ключ
+// один.
+func lookupKey(keys map[string]crypto.PublicKey, kid string) (crypto.PublicKey, error) {
+	if key, ok := keys[kid]; ok {
+		return key, nil
+	}
+	if kid == "" && len(keys) == 1 {
+		for _, key := range keys {
+			return key, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown signing key %q", kid)
+}
+
+const (
+	defaultJWKSRefresh = 10 * time.Minute
+	// minJWKSRefresh ограничивает перезапросы JWKS из-за токенов с
+	// неизвестным kid и после неудачных попыток.
+	minJWKSRefresh = time.Minute
+)
+
+// RemoteKeySet загружает JWKS по URL при первом обращении и перечитывает
+// его раз в refresh или раньше, если встретился неизвестный kid, но не
+// чаще minJWKSRefresh. Если обновить ключи не удалось, используются
+// прежние. Загрузка идет без блокировки: одновременные запросы ждут одну
+// загрузку, а проверки с уже известными ключами ее не ждут.
+type RemoteKeySet struct {
+	url     string
+	client  *http.Client
+	refresh time.Duration
+	fetches singleflight.Group
+
+	mu        sync.Mutex
+	keys      map[string]crypto.PublicKey
+	fetchedAt time.Time
+	// attemptedAt и fetchErr — время и ошибка последней попытки загрузки,
+	// в том числе неудачной.
+	attemptedAt time.Time
+	fetchErr    error
+}
+
+func NewRemoteKeySet(url string, refresh time.Duration) *RemoteKeySet {
+	if refresh <= 0 {
+		refresh = defaultJWKSRefresh
+	}
+	return &RemoteKeySet{url: url, client: &http.Client{Timeout: 10 * time.Second}, refresh: refresh}
+}
+
+func (s *RemoteKeySet) Key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	s.mu.Lock()
+	keys, fetchedAt, attemptedAt, fetchErr := s.keys, s.fetchedAt, s.attemptedAt, s.fetchErr
+	s.mu.Unlock()
+
+	if keys != nil && time.Since(fetchedAt) < s.refresh {
+		if key, err := lookupKey(keys, kid); err == nil {
+			return key, nil
+		}
+	}
+	if time.Since(attemptedAt) < minJWKSRefresh {
+		if keys == nil {
+			return nil, fetchErr
+		}
+		return lookupKey(keys, kid)
+	}
+
+	fetched, err, _ := s.fetches.Do("", func() (any, error) {
+		// Загрузку ждут и другие запросы: отмена одного из них ее не прерывает.
+		keys, err := s.fetch(context.WithoutCancel(ctx))
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.attemptedAt, s.fetchErr = time.Now(), err
+		if err != nil {
+			return nil, err
+		}
+		s.keys, s.fetchedAt = keys, s.attemptedAt
+		return keys, nil
+	})
+	if err != nil {
+		if keys == nil {
+			return nil, err
+		}
+		return lookupKey(keys, kid)
+	}
+	return lookupKey(fetched.(map[string]crypto.PublicKey), kid)
+}
+
+func (s *RemoteKeySet) fetch(ctx context.Context) (map[string]crypto.PublicKey, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch JWKS: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("could not fetch JWKS: %s", resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+	return ParseJWKS(data)
+}
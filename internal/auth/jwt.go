@@ -0,0 +1,212 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+	"strings"
+	"time"
+)
+
+// JWTVerifier проверяет подпись и стандартные claims токена. HMAC-алгоритмы
+// и "none" не принимаются: ключи берутся только из JWKS.
+type JWTVerifier struct {
+	Keys KeySet
+	// Issuer и Audience, если заданы, должны совпасть с iss и одним из aud.
+	Issuer   string
+	Audience string
+	// Leeway — допуск на расхождение часов при проверке exp и nbf.
+	Leeway time.Duration
+	now    func() time.Time
+}
+
+// Claims — используемые сервисом claims токена.
+type Claims struct {
+	Subject   string   `json:"sub"`
+	Issuer    string   `json:"iss"`
+	Audience  audience `json:"aud"`
+	ExpiresAt *int64   `json:"exp"`
+	NotBefore *int64   `json:"nbf"`
+	// Scope — области через пробел (RFC 8693); некоторые провайдеры
+	// вместо него выдают массив scp.
+	Scope string   `json:"scope"`
+	SCP   []string `json:"scp"`
+	// Tenant — площадка, к которой привязан токен, или AllTenants.
+	Tenant string `json:"tenant"`
+}
+
+// Scopes возвращает области токена из scope и scp.
+func (c Claims) Scopes() []string {
+	return append(strings.Fields(c.Scope), c.SCP...)
+}
+
+// audience — claim aud, который бывает строкой или массивом строк.
+type audience []string
+
+func (a *audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = audience{single}
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return errors.New("aud must be a string or an array of strings")
+	}
+	*a = list
+	return nil
+}
+
+type signingMethod struct {
+	hash crypto.Hash
+	// kind — "rsa", "pss", "ecdsa" или "eddsa".
+	kind string
+}
+
+var signingMethods = map[string]signingMethod{
+	"RS256": {crypto.SHA256, "rsa"},
+	"RS384": {crypto.SHA384, "rsa"},
+	"RS512": {crypto.SHA512, "rsa"},
+	"PS256": {crypto.SHA256, "pss"},
+	"PS384": {crypto.SHA384, "pss"},
+	"PS512": {crypto.SHA512, "pss"},
+	"ES256": {crypto.SHA256, "ecdsa"},
+	"ES384": {crypto.SHA384, "ecdsa"},
+	"ES512": {crypto.SHA512, "ecdsa"},
+	"EdDSA": {0, "eddsa"},
+}
+
+// Verify проверяет токен и возвращает его claims. Все ошибки оборачивают
+// ErrInvalidCredentials.
+func (v *JWTVerifier) Verify(ctx context.Context, token string) (Claims, error) {
+	claims, err := v.verify(ctx, token)
+	if err != nil {
+		return Claims{}, fmt.Errorf("%w: %v", ErrInvalidCredentials, err)
+	}
+	return claims, nil
+}
+
+func (v *JWTVerifier) verify(ctx context.Context, token string) (Claims, error) {
+	var claims Claims
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return claims, errors.New("malformed token")
+	}
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return claims, fmt.Errorf("malformed token header: %w", err)
+	}
+	method, ok := signingMethods[header.Alg]
+	if !ok {
+		return claims, fmt.Errorf("unsupported algorithm %q", header.Alg)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return claims, errors.New("malformed token signature")
+	}
+	key, err := v.Keys.Key(ctx, header.Kid)
+	if err != nil {
+		return claims, err
+	}
+	if err := method.verify(key, []byte(parts[0]+"."+parts[1]), signature); err != nil {
+		return claims, err
+	}
+
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return claims, fmt.Errorf("malformed token claims: %w", err)
+	}
+	return claims, v.validate(claims)
+}
+
+func decodeSegment(segment string, v any) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+func (m signingMethod) verify(key crypto.PublicKey, signed, signature []byte) error {
+	var digest []byte
+	if m.hash != 0 {
+		h := m.hash.New()
+		h.Write(signed)
+		digest = h.Sum(nil)
+	}
+	valid := false
+	switch m.kind {
+	case "rsa":
+		if k, ok := key.(*rsa.PublicKey); ok {
+			valid = rsa.VerifyPKCS1v15(k, m.hash, digest, signature) == nil
+		} else {
+			return errors.New("key type does not match algorithm")
+		}
+	case "pss":
+		if k, ok := key.(*rsa.PublicKey); ok {
+			valid = rsa.VerifyPSS(k, m.hash, digest, signature, nil) == nil
+		} else {
+			return errors.New("key type does not match algorithm")
+		}
+	case "ecdsa":
+		k, ok := key.(*ecdsa.PublicKey)
+		if !ok {
+			return errors.New("key type does not match algorithm")
+		}
+		// В JWS подпись ECDSA — r и s фиксированной длины подряд.
+		size := (k.Curve.Params().BitSize + 7) / 8
+		if len(signature) == 2*size {
+			r := new(big.Int).SetBytes(signature[:size])
+			s := new(big.Int).SetBytes(signature[size:])
+			valid = ecdsa.Verify(k, digest, r, s)
+		}
+	case "eddsa":
+		k, ok := key.(ed25519.PublicKey)
+		if !ok {
+			return errors.New("key type does not match algorithm")
+		}
+		valid = ed25519.Verify(k, signed, signature)
+	}
+	if !valid {
+		return errors.New("signature verification failed")
+	}
+	return nil
+}
+
+func (v *JWTVerifier) validate(c Claims) error {
+	now := time.Now()
+	if v.now != nil {
+		now = v.now()
+	}
+	if c.ExpiresAt == nil {
+		return errors.New("token has no exp claim")
+	}
+	if now.After(time.Unix(*c.ExpiresAt, 0).Add(v.Leeway)) {
+		return errors.New("token is expired")
+	}
+	if c.NotBefore != nil && now.Before(time.Unix(*c.NotBefore, 0).Add(-v.Leeway)) {
+		return errors.New("token is not valid yet")
+	}
+	if v.Issuer != "" && c.Issuer != v.Issuer {
+		return fmt.Errorf("unexpected issuer %q", c.Issuer)
+	}
+	if v.Audience != "" && !slices.Contains(c.Audience, v.Audience) {
+		return errors.New("token is not issued for this audience")
+	}
+	if c.Subject == "" {
+		return errors.New("token has no sub claim")
+	}
+	return nil
+}
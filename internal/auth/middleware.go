@@ -0,0 +1,160 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"orderkeeper/pkg/utils"
+	"strings"
+)
+
+// APIKeyAuthenticator проверяет API-ключ; его реализует
+// service.APIKeyService.
+type APIKeyAuthenticator interface {
+	AuthenticateAPIKey(key string) (Principal, error)
+}
+
+// Authenticator принимает API-ключ в заголовке X-API-Key или
+// Authorization: Bearer, а JWT — в Authorization: Bearer. Если JWT не
+// настроен (JWT == nil), принимаются только API-ключи.
+type Authenticator struct {
+	APIKeys APIKeyAuthenticator
+	JWT     *JWTVerifier
+	// AllowNoTenant пускает учетные данные без площадки (JWT без claim
+	// tenant, ключи, выпущенные до появления площадок) ко всем площадкам.
+	// Без него они отклоняются с ErrNoTenant.
+	AllowNoTenant bool
+}
+
+func (a *Authenticator) Authenticate(r *http.Request) (Principal, error) {
+	principal, err := a.authenticate(r)
+	if err != nil || principal.Tenant != "" {
+		return principal, err
+	}
+	if !a.AllowNoTenant {
+		return Principal{}, fmt.Errorf("%w: %s %q", ErrNoTenant, principal.Method, principal.Subject)
+	}
+	principal.Tenant = AllTenants
+	return principal, nil
+}
+
+func (a *Authenticator) authenticate(r *http.Request) (Principal, error) {
+	token := r.Header.Get("X-API-Key")
+	if token == "" {
+		scheme, credentials, _ := strings.Cut(r.Header.Get("Authorization"), " ")
+		if strings.EqualFold(scheme, "Bearer") {
+			token = strings.TrimSpace(credentials)
+		}
+	}
+	switch {
+	case token == "":
+		return Principal{}, ErrNoCredentials
+	case IsAPIKey(token):
+		return a.APIKeys.AuthenticateAPIKey(token)
+	case a.JWT != nil:
+		return a.authenticateJWT(r.Context(), token)
+	default:
+		return Principal{}, ErrInvalidCredentials
+	}
+}
+
+func (a *Authenticator) authenticateJWT(ctx context.Context, token string) (Principal, error) {
+	claims, err := a.JWT.Verify(ctx, token)
+	if err != nil {
+		return Principal{}, err
+	}
+	return Principal{Subject: claims.Subject, Method: MethodJWT, Scopes: claims.Scopes(), Tenant: claims.Tenant}, nil
+}
+
+// Middleware пропускает только аутентифицированные запросы и кладет
+// Principal в их контекст.
+func (a *Authenticator) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		principal, err := a.Authenticate(r)
+		if errors.Is(err, ErrNoTenant) {
+			log.Printf("Rejected credentials for %s %s: %v", r.Method, r.URL.Path, err)
+			utils.JSONResponse(w, http.StatusForbidden, map[string]string{
+				"error": ErrNoTenant.Error(),
+			})
+			return
+		}
+		if err != nil {
+			unauthorized(w, r, err)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(NewContext(r.Context(), principal)))
+	})
+}
+
+func unauthorized(w http.ResponseWriter, r *http.Request, err error) {
+	message := ErrNoCredentials.Error()
+	switch {
+	case errors.Is(err, ErrNoCredentials):
+	case errors.Is(err, ErrInvalidCredentials):
+		// Причину отказа знает только лог: клиенту она не нужна.
+		log.Printf("Rejected credentials for %s %s: %v", r.Method, r.URL.Path, err)
+		message = ErrInvalidCredentials.Error()
+	default:
+		log.Printf("Authentication failed for %s %s: %v", r.Method, r.URL.Path, err)
+		utils.JSONResponse(w, http.StatusInternalServerError, map[string]string{
+			"error": "authentication is temporarily unavailable",
+		})
+		return
+	}
+	w.Header().Set("WWW-Authenticate", `Bearer realm="orderkeeper"`)
+	utils.JSONResponse(w, http.StatusUnauthorized, map[string]string{
+		"error": message,
+	})
+}
+
+// Anonymous заменяет Middleware при выключенной аутентификации: запросу
+// выдаются все области.
+func Anonymous(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		principal := Principal{Subject: "anonymous", Method: MethodNone, Scopes: Scopes, Tenant: AllTenants}
+		next.ServeHTTP(w, r.WithContext(NewContext(r.Context(), principal)))
+	})
+}
+
+// Require пропускает только запросы, Principal которых имеет область scope.
+// Ставится после Middleware или Anonymous.
+func Require(scope string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			principal, ok := FromContext(r.Context())
+			if !ok {
+				unauthorized(w, r, ErrNoCredentials)
+				return
+			}
+			if !principal.HasScope(scope) {
+				utils.JSONResponse(w, http.StatusForbidden, map[string]string{
+					"error": "missing scope " + scope,
+				})
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RequireAllTenants пропускает только запросы, привязанные ко всем
+// площадкам: так закрываются маршруты, которые действуют на все
+// развертывание.
+func RequireAllTenants(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		principal, ok := FromContext(r.Context())
+		if !ok {
+			unauthorized(w, r, ErrNoCredentials)
+			return
+		}
+		if principal.Tenant != AllTenants {
+			utils.JSONResponse(w, http.StatusForbidden, map[string]string{
+				"error": "credentials are bound to tenant " + principal.Tenant,
+			})
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
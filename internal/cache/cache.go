@@ -1,4 +1,8 @@
 // Package cache реализует потокобезопасный, шардированный LRU-кеш.
+//
+// Заказы хранятся в пространствах имен площадок (Order.Entry): запрос с
+// площадкой видит только ее заказы и трек-номера, запрос без площадки —
+// заказы всех площадок.
 package cache
 
 import (
@@ -6,6 +10,7 @@ import (
 	"hash/fnv"
 	"orderkeeper/internal/models"
 	"sync"
+	"sync/atomic"
 )
 
 const (
@@ -27,16 +32,45 @@ type cacheShard struct {
 
 type OrderCache struct {
 	shards []*cacheShard
+
+	// Индексы закешированных заказов: tracks связывает трек-номера заказов
+	// и их позиций с order_uid в пространстве имен площадки, allTracks и
+	// owners — трек-номера и order_uid с ключами для запросов без площадки.
+	indexMu   sync.RWMutex
+	tracks    map[string]string
+	allTracks map[string]string
+	owners    map[string]string
+
+	hits   atomic.Int64
+	misses atomic.Int64
+}
+
+// Stats — состояние кеша для GET /admin/cache/stats.
+type Stats struct {
+	Count    int   `json:"count"`
+	Capacity int   `json:"capacity"`
+	Tracks   int   `json:"tracks"`
+	Hits     int64 `json:"hits"`
+	Misses   int64 `json:"misses"`
+}
+
+// Key возвращает ключ кеша: id в пространстве имен площадки tenant.
+func Key(tenant, id string) string {
+	return tenant + "/" + id
 }
 
 func NewOrderCache() *OrderCache {
+	return NewOrderCacheWithCapacity(defaultMaxCacheSize)
+}
+
+func NewOrderCacheWithCapacity(capacity int) *OrderCache {
 	c := &OrderCache{
-		shards: make([]*cacheShard, shardCount),
-	}
-	shardCapacity := defaultMaxCacheSize / shardCount
-	if shardCapacity < 1 {
-		shardCapacity = 1
+		shards:    make([]*cacheShard, shardCount),
+		tracks:    make(map[string]string),
+		allTracks: make(map[string]string),
+		owners:    make(map[string]string),
 	}
+	shardCapacity := shardCapacityFor(capacity)
 
 	for i := 0; i < shardCount; i++ {
 		c.shards[i] = &cacheShard{
@@ -48,49 +82,108 @@ func NewOrderCache() *OrderCache {
 	return c
 }
 
+func shardCapacityFor(capacity int) int {
+	shardCapacity := capacity / shardCount
+	if shardCapacity < 1 {
+		shardCapacity = 1
+	}
+	return shardCapacity
+}
+
 func (c *OrderCache) getShard(key string) *cacheShard {
 	hasher := fnv.New32a()
 	_, _ = hasher.Write([]byte(key))
 	return c.shards[hasher.Sum32()%shardCount]
 }
 
+// Set кеширует заказ в пространстве имен его площадки.
 func (c *OrderCache) Set(order models.Order) {
-	shard := c.getShard(order.OrderUID)
+	key := Key(order.Entry, order.OrderUID)
+	shard := c.getShard(key)
 	shard.mu.Lock()
 	defer shard.mu.Unlock()
 
-	if elem, ok := shard.items[order.OrderUID]; ok {
+	if elem, ok := shard.items[key]; ok {
 		shard.ll.MoveToFront(elem)
-		elem.Value.(*cacheEntry).order = order
+		entry := elem.Value.(*cacheEntry)
+		c.unindex(entry.order)
+		entry.order = order
+		c.index(order)
 		return
 	}
 
 	if shard.ll.Len() >= shard.capacity {
 		oldest := shard.ll.Back()
 		if oldest != nil {
-			removedEntry := shard.ll.Remove(oldest).(*cacheEntry)
-			delete(shard.items, removedEntry.key)
+			c.evict(shard, oldest)
 		}
 	}
 
-	newEntry := &cacheEntry{key: order.OrderUID, order: order}
+	newEntry := &cacheEntry{key: key, order: order}
 	elem := shard.ll.PushFront(newEntry)
-	shard.items[order.OrderUID] = elem
+	shard.items[key] = elem
+	c.index(order)
+}
+
+// evict удаляет запись из шарда; вызывается под shard.mu.
+func (c *OrderCache) evict(shard *cacheShard, elem *list.Element) {
+	removedEntry := shard.ll.Remove(elem).(*cacheEntry)
+	delete(shard.items, removedEntry.key)
+	c.unindex(removedEntry.order)
+}
+
+// keyOf возвращает ключ заказа uid для площадки tenant; без площадки ключ
+// ищется в индексе.
+func (c *OrderCache) keyOf(tenant, uid string) (string, bool) {
+	if tenant != "" {
+		return Key(tenant, uid), true
+	}
+	c.indexMu.RLock()
+	owner, ok := c.owners[uid]
+	c.indexMu.RUnlock()
+	return Key(owner, uid), ok
+}
+
+// Get ищет заказ uid площадки tenant; пустой tenant — любой площадки.
+func (c *OrderCache) Get(tenant, uid string) (models.Order, bool) {
+	key, ok := c.keyOf(tenant, uid)
+	if !ok {
+		c.misses.Add(1)
+		return models.Order{}, false
+	}
+	return c.get(key)
 }
 
-func (c *OrderCache) Get(uid string) (models.Order, bool) {
-	shard := c.getShard(uid)
+func (c *OrderCache) get(key string) (models.Order, bool) {
+	shard := c.getShard(key)
 	shard.mu.Lock()
 	defer shard.mu.Unlock()
 
-	if elem, ok := shard.items[uid]; ok {
+	if elem, ok := shard.items[key]; ok {
 		shard.ll.MoveToFront(elem)
+		c.hits.Add(1)
 		return elem.Value.(*cacheEntry).order, true
 	}
 
+	c.misses.Add(1)
 	return models.Order{}, false
 }
 
+// Delete удаляет заказ uid площадки tenant; пустой tenant — любой площадки.
+func (c *OrderCache) Delete(tenant, uid string) {
+	key, ok := c.keyOf(tenant, uid)
+	if !ok {
+		return
+	}
+	shard := c.getShard(key)
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+
+	if elem, ok := shard.items[key]; ok {
+		c.evict(shard, elem)
+	}
+}
+
 func (c *OrderCache) LoadFromDB(orders []models.Order) {
 	for _, order := range orders {
 		c.Set(order)
@@ -107,3 +200,87 @@ func (c *OrderCache) Count() int {
 	}
 	return count
 }
+
+// Resize меняет емкость кеша на лету. При уменьшении лишние записи
+// вытесняются в порядке LRU.
+func (c *OrderCache) Resize(capacity int) {
+	shardCapacity := shardCapacityFor(capacity)
+	for i := 0; i < shardCount; i++ {
+		shard := c.shards[i]
+		shard.mu.Lock()
+		shard.capacity = shardCapacity
+		for shard.ll.Len() > shard.capacity {
+			c.evict(shard, shard.ll.Back())
+		}
+		shard.mu.Unlock()
+	}
+}
+
+func (c *OrderCache) Capacity() int {
+	c.shards[0].mu.Lock()
+	defer c.shards[0].mu.Unlock()
+	return c.shards[0].capacity * shardCount
+}
+
+// Stats возвращает число записей, емкость и счетчики попаданий с момента
+// создания кеша.
+func (c *OrderCache) Stats() Stats {
+	c.indexMu.RLock()
+	tracks := len(c.allTracks)
+	c.indexMu.RUnlock()
+	return Stats{
+		Count:    c.Count(),
+		Capacity: c.Capacity(),
+		Tracks:   tracks,
+		Hits:     c.hits.Load(),
+		Misses:   c.misses.Load(),
+	}
+}
+
+// GetByTrack ищет закешированный заказ площадки tenant по трек-номеру
+// заказа или позиции; пустой tenant — заказ любой площадки.
+func (c *OrderCache) GetByTrack(tenant, track string) (models.Order, bool) {
+	c.indexMu.RLock()
+	var key string
+	var ok bool
+	if tenant == "" {
+		key, ok = c.allTracks[track]
+	} else {
+		var uid string
+		uid, ok = c.tracks[Key(tenant, track)]
+		key = Key(tenant, uid)
+	}
+	c.indexMu.RUnlock()
+	if !ok {
+		return models.Order{}, false
+	}
+	return c.get(key)
+}
+
+func (c *OrderCache) index(order models.Order) {
+	key := Key(order.Entry, order.OrderUID)
+	c.indexMu.Lock()
+	defer c.indexMu.Unlock()
+	c.owners[order.OrderUID] = order.Entry
+	for _, track := range order.TrackNumbers() {
+		c.tracks[Key(order.Entry, track)] = order.OrderUID
+		c.allTracks[track] = key
+	}
+}
+
+func (c *OrderCache) unindex(order models.Order) {
+	key := Key(order.Entry, order.OrderUID)
+	c.indexMu.Lock()
+	defer c.indexMu.Unlock()
+	if owner, ok := c.owners[order.OrderUID]; ok && owner == order.Entry {
+		delete(c.owners, order.OrderUID)
+	}
+	for _, track := range order.TrackNumbers() {
+		if c.tracks[Key(order.Entry, track)] == order.OrderUID {
+			delete(c.tracks, Key(order.Entry, track))
+		}
+		if c.allTracks[track] == key {
+			delete(c.allTracks, track)
+		}
+	}
+}
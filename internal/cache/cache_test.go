@@ -0,0 +1,103 @@
+package cache
+
+import (
+	"fmt"
+	"orderkeeper/internal/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOrderCache_Resize(t *testing.T) {
+	c := NewOrderCacheWithCapacity(shardCount * 4)
+	for i := 0; i < shardCount*8; i++ {
+		c.Set(models.Order{OrderUID: fmt.Sprintf("uid-%d", i)})
+	}
+	assert.LessOrEqual(t, c.Count(), shardCount*4)
+	assert.Equal(t, shardCount*4, c.Capacity())
+
+	c.Resize(shardCount)
+
+	assert.LessOrEqual(t, c.Count(), shardCount)
+	assert.Equal(t, shardCount, c.Capacity())
+
+	c.Resize(0)
+	assert.Equal(t, shardCount, c.Capacity())
+}
+
+func TestOrderCache_GetByTrack(t *testing.T) {
+	c := NewOrderCache()
+	order := models.Order{
+		OrderUID:    "uid-1",
+		TrackNumber: "TRACK-1",
+		Items:       []models.Item{{TrackNumber: "TRACK-1"}, {TrackNumber: "TRACK-2"}},
+	}
+	c.Set(order)
+
+	for _, track := range []string{"TRACK-1", "TRACK-2"} {
+		got, ok := c.GetByTrack("", track)
+		assert.True(t, ok, track)
+		assert.Equal(t, "uid-1", got.OrderUID)
+	}
+
+	order.Items = order.Items[:1]
+	c.Set(order)
+	_, ok := c.GetByTrack("", "TRACK-2")
+	assert.False(t, ok)
+
+	c.Delete("", "uid-1")
+	_, ok = c.GetByTrack("", "TRACK-1")
+	assert.False(t, ok)
+	assert.Empty(t, c.tracks)
+	assert.Empty(t, c.allTracks)
+	assert.Empty(t, c.owners)
+}
+
+func TestOrderCache_Tenants(t *testing.T) {
+	c := NewOrderCache()
+	c.Set(models.Order{OrderUID: "uid-1", Entry: "WBIL", TrackNumber: "TRACK"})
+	c.Set(models.Order{OrderUID: "uid-2", Entry: "OZON", TrackNumber: "TRACK"})
+
+	_, ok := c.Get("OZON", "uid-1")
+	assert.False(t, ok)
+	got, ok := c.Get("WBIL", "uid-1")
+	assert.True(t, ok)
+	assert.Equal(t, "WBIL", got.Entry)
+	_, ok = c.Get("", "uid-1")
+	assert.True(t, ok)
+
+	got, ok = c.GetByTrack("WBIL", "TRACK")
+	assert.True(t, ok)
+	assert.Equal(t, "uid-1", got.OrderUID)
+	got, ok = c.GetByTrack("OZON", "TRACK")
+	assert.True(t, ok)
+	assert.Equal(t, "uid-2", got.OrderUID)
+
+	c.Delete("OZON", "uid-1")
+	_, ok = c.Get("WBIL", "uid-1")
+	assert.True(t, ok)
+	c.Delete("", "uid-1")
+	_, ok = c.Get("WBIL", "uid-1")
+	assert.False(t, ok)
+	_, ok = c.GetByTrack("OZON", "TRACK")
+	assert.True(t, ok)
+}
+
+func TestOrderCache_EvictionDropsTracks(t *testing.T) {
+	c := NewOrderCacheWithCapacity(shardCount)
+	for i := 0; i < shardCount*4; i++ {
+		c.Set(models.Order{OrderUID: fmt.Sprintf("uid-%d", i), TrackNumber: fmt.Sprintf("TRACK-%d", i)})
+	}
+
+	assert.Len(t, c.tracks, c.Count())
+}
+
+func TestOrderCache_Stats(t *testing.T) {
+	c := NewOrderCache()
+	c.Set(models.Order{OrderUID: "uid-1", TrackNumber: "TRACK"})
+	c.Get("", "uid-1")
+	c.Get("", "uid-2")
+	c.Get("", "uid-3")
+
+	assert.Equal(t, Stats{Count: 1, Capacity: c.Capacity(), Tracks: 1, Hits: 1, Misses: 2}, c.Stats())
+}
@@ -2,6 +2,7 @@
 package db
 
 import (
+	"fmt"
 	"log"
 	"math"
 	"orderkeeper/internal/models"
@@ -11,7 +12,41 @@ import (
 	"gorm.io/gorm"
 )
 
+// InitDB подключается к БД и применяет миграции.
 func InitDB(dsn string) (*gorm.DB, error) {
+	dbInstance, err := Open(dsn)
+	if err != nil {
+		return nil, err
+	}
+	if err := migrateColumns(dbInstance); err != nil {
+		return nil, err
+	}
+	err = dbInstance.AutoMigrate(
+		&models.Order{},
+		&models.Delivery{},
+		&models.Payment{},
+		&models.Item{},
+		&models.OutboxEvent{},
+		&models.APIKey{},
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := backfillAPIKeyScopes(dbInstance); err != nil {
+		return nil, fmt.Errorf("failed to backfill API key scopes: %w", err)
+	}
+	if err := cleanupOutbox(dbInstance); err != nil {
+		return nil, fmt.Errorf("failed to clean up outbox events: %w", err)
+	}
+	if err := setupSearch(dbInstance); err != nil {
+		return nil, fmt.Errorf("failed to set up order search: %w", err)
+	}
+	log.Println("Database migration successful.")
+	return dbInstance, nil
+}
+
+// Open подключается к БД с повторными попытками, не меняя схему.
+func Open(dsn string) (*gorm.DB, error) {
 	maxAttempts := 5
 	initialDelay := 2 * time.Second
 	var dbInstance *gorm.DB
@@ -21,16 +56,6 @@ func InitDB(dsn string) (*gorm.DB, error) {
 		dbInstance, err = gorm.Open(postgres.Open(dsn), &gorm.Config{})
 		if err == nil {
 			log.Println("Database connection successful.")
-			err = dbInstance.AutoMigrate(
-				&models.Order{},
-				&models.Delivery{},
-				&models.Payment{},
-				&models.Item{},
-			)
-			if err != nil {
-				return nil, err
-			}
-			log.Println("Database migration successful.")
 			return dbInstance, nil
 		}
 
@@ -0,0 +1,149 @@
+package db
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"orderkeeper/internal/auth"
+	"orderkeeper/internal/models"
+	"slices"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// columnMigration меняет тип колонки до AutoMigrate, если он еще старый:
+// AutoMigrate не умеет преобразовывать существующие данные.
+type columnMigration struct {
+	table, column string
+	// key — колонка, по которой в ошибке указываются непреобразуемые строки.
+	key  string
+	from []string
+	// using — выражение для ALTER COLUMN ... TYPE timestamptz USING. Для
+	// значений, которые нельзя преобразовать, оно возвращает NULL.
+	using string
+}
+
+var columnMigrations = []columnMigration{
+	{
+		table:  "orders",
+		column: "date_created",
+		key:    "order_uid",
+		from:   []string{"text", "character varying"},
+		using:  "pg_temp.to_timestamptz(date_created)",
+	},
+	{
+		table:  "payments",
+		column: "payment_dt",
+		key:    "order_uid",
+		from:   []string{"bigint", "integer"},
+		using:  "pg_temp.to_timestamptz(payment_dt::text)",
+	},
+}
+
+// toTimestamptz разбирает Unix-секунды и строки, начинающиеся с даты
+// YYYY-MM-DD, а вместо ошибки возвращает NULL. Функция временная: она
+// видна только в транзакции миграции.
+const toTimestamptz = `CREATE OR REPLACE FUNCTION pg_temp.to_timestamptz(v text) RETURNS timestamptz AS $$
+BEGIN
+	IF v ~ '^[0-9]+$' THEN
+		RETURN to_timestamp(v::double precision);
+	ELSIF v ~ '^[0-9]{4}-[0-9]{2}-[0-9]{2}' THEN
+		RETURN v::timestamptz;
+	END IF;
+	RETURN NULL;
+EXCEPTION WHEN others THEN
+	RETURN NULL;
+END;
+$$ LANGUAGE plpgsql`
+
+// maxReportedRows — сколько непреобразуемых строк перечисляется в ошибке.
+const maxReportedRows = 10
+
+func migrateColumns(db *gorm.DB) error {
+	for _, m := range columnMigrations {
+		var dataType string
+		err := db.Raw(`SELECT data_type FROM information_schema.columns
+			WHERE table_schema = current_schema() AND table_name = ? AND column_name = ?`,
+			m.table, m.column).Scan(&dataType).Error
+		if err != nil {
+			return err
+		}
+		if !slices.Contains(m.from, dataType) {
+			continue
+		}
+
+		err = db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Exec(toTimestamptz).Error; err != nil {
+				return err
+			}
+			// Сначала ищем значения, которые не преобразуются, чтобы не
+			// потерять их молча и не оборвать ALTER на середине.
+			var invalid []struct{ Key, Value string }
+			err := tx.Raw(fmt.Sprintf(`SELECT %s AS key, %s::text AS value FROM %s
+				WHERE %s IS NOT NULL AND %s IS NULL ORDER BY 1 LIMIT %d`,
+				m.key, m.column, m.table, m.column, m.using, maxReportedRows+1)).Scan(&invalid).Error
+			if err != nil {
+				return err
+			}
+			if len(invalid) > 0 {
+				rows := make([]string, 0, maxReportedRows)
+				for _, row := range invalid[:min(len(invalid), maxReportedRows)] {
+					rows = append(rows, fmt.Sprintf("%s=%q value %q", m.key, row.Key, row.Value))
+				}
+				if len(invalid) > maxReportedRows {
+					rows = append(rows, "...")
+				}
+				return fmt.Errorf("unparseable values, fix or delete these rows and restart: %s", strings.Join(rows, ", "))
+			}
+			return tx.Exec(fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s TYPE timestamptz USING %s",
+				m.table, m.column, m.using)).Error
+		})
+		if err != nil {
+			return fmt.Errorf("failed to migrate %s.%s to timestamptz: %w", m.table, m.column, err)
+		}
+		log.Printf("Migration: converted %s.%s from %s to timestamptz", m.table, m.column, dataType)
+	}
+	return nil
+}
+
+// cleanupOutbox приводит к текущему виду события, записанные прежними
+// версиями: добавляет типам models.EventTypePrefix, заполняет площадку
+// заказа, а в событиях order.created заменяет персональные данные
+// получателя ссылкой personal_data.
+func cleanupOutbox(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Exec(`UPDATE outbox_events SET event_type = ? || event_type WHERE event_type NOT LIKE ?`,
+			models.EventTypePrefix, models.EventTypePrefix+"%").Error
+		if err != nil {
+			return err
+		}
+		err = tx.Exec(`UPDATE outbox_events SET tenant = orders.entry FROM orders
+			WHERE orders.order_uid = outbox_events.aggregate_id AND outbox_events.tenant = ''`).Error
+		if err != nil {
+			return err
+		}
+		return tx.Exec(`UPDATE outbox_events
+			SET payload = jsonb_set(payload, '{delivery}', payload->'delivery' || '{"name": "", "phone": "", "email": "", "address": ""}')
+				|| jsonb_build_object('personal_data', ?::text || aggregate_id)
+			WHERE event_type = ? AND payload->'personal_data' IS NULL`, models.OrderPath(""), models.EventOrderCreated).Error
+	})
+}
+
+// backfillAPIKeyScopes выдает auth.DefaultScope ключам, выпущенным до
+// появления областей: колонка scopes добавилась им пустой, и без этого они
+// получали бы 403 на всех маршрутах. Новые ключи без областей не выпускаются.
+func backfillAPIKeyScopes(db *gorm.DB) error {
+	scopes, err := json.Marshal([]string{auth.DefaultScope})
+	if err != nil {
+		return err
+	}
+	result := db.Exec(`UPDATE api_keys SET scopes = ? WHERE scopes = '[]'`, string(scopes))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected > 0 {
+		log.Printf("Migration: granted %s to %d API key(s) issued without scopes", auth.DefaultScope, result.RowsAffected)
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package db
+
+import (
+	"log"
+
+	"gorm.io/gorm"
+)
+
+// Поисковые колонки заказа обновляются триггерами на deliveries и items,
+// поэтому их нет в моделях GORM:
+//   - search_vector — tsvector по имени, телефону, email, городу, адресу,
+//     названиям и брендам позиций;
+//   - search_text — те же поля одной строкой для ts_headline;
+//   - search_phone — цифры телефона для поиска по части номера;
+//   - search_public — tsvector только по городу и позициям для тех, кому
+//     нельзя искать по данным получателя.
+//
+// Зашифрованные поля доставки (см. пакет pii) в индекс не попадают: такие
+// заказы находятся по городу и позициям, а по телефону и email — через
+// слепые индексы deliveries.phone_index и email_index.
+var searchStatements = []string{
+	`CREATE OR REPLACE FUNCTION pii_plain(v text) RETURNS text AS $$
+		SELECT CASE WHEN v LIKE 'enc:%' THEN NULL ELSE v END
+	$$ LANGUAGE sql IMMUTABLE`,
+
+	`ALTER TABLE orders
+		ADD COLUMN IF NOT EXISTS search_vector tsvector,
+		ADD COLUMN IF NOT EXISTS search_text text,
+		ADD COLUMN IF NOT EXISTS search_phone text,
+		ADD COLUMN IF NOT EXISTS search_public tsvector`,
+
+	`CREATE OR REPLACE FUNCTION orders_search_refresh(uid text) RETURNS void AS $$
+	BEGIN
+		UPDATE orders o SET
+			search_vector =
+				setweight(to_tsvector('simple', coalesce(d.name, '')), 'A') ||
+				setweight(to_tsvector('simple', coalesce(d.phone, '') || ' ' || translate(coalesce(d.email, ''), '@.', '  ')), 'A') ||
+				setweight(to_tsvector('simple', coalesce(d.email, '')), 'A') ||
+				setweight(to_tsvector('simple', coalesce(d.city, '') || ' ' || coalesce(d.address, '')), 'B') ||
+				setweight(to_tsvector('simple', coalesce(i.names, '')), 'C'),
+			search_text = concat_ws(' | ', d.name, d.phone, d.email, d.city, d.address, i.names),
+			search_phone = regexp_replace(coalesce(d.phone, ''), '[^0-9]', '', 'g'),
+			search_public =
+				setweight(to_tsvector('simple', coalesce(d.city, '')), 'B') ||
+				setweight(to_tsvector('simple', coalesce(i.names, '')), 'C')
+		FROM (SELECT uid AS order_uid) u
+		LEFT JOIN (
+			SELECT order_uid, pii_plain(name) AS name, pii_plain(phone) AS phone, pii_plain(email) AS email,
+				city, pii_plain(address) AS address
+			FROM deliveries WHERE order_uid = uid
+		) d ON d.order_uid = u.order_uid
+		LEFT JOIN (
+			SELECT order_uid, string_agg(concat_ws(' ', name, brand), ' | ' ORDER BY id) AS names
+			FROM items WHERE order_uid = uid GROUP BY order_uid
+		) i ON i.order_uid = u.order_uid
+		WHERE o.order_uid = uid;
+	END;
+	$$ LANGUAGE plpgsql`,
+
+	`CREATE OR REPLACE FUNCTION orders_search_trigger() RETURNS trigger AS $$
+	BEGIN
+		IF TG_OP = 'DELETE' THEN
+			PERFORM orders_search_refresh(OLD.order_uid);
+			RETURN OLD;
+		END IF;
+		PERFORM orders_search_refresh(NEW.order_uid);
+		IF TG_OP = 'UPDATE' AND OLD.order_uid IS DISTINCT FROM NEW.order_uid THEN
+			PERFORM orders_search_refresh(OLD.order_uid);
+		END IF;
+		RETURN NEW;
+	END;
+	$$ LANGUAGE plpgsql`,
+
+	`DROP TRIGGER IF EXISTS deliveries_search ON deliveries`,
+	`CREATE TRIGGER deliveries_search AFTER INSERT OR UPDATE OR DELETE ON deliveries
+		FOR EACH ROW EXECUTE FUNCTION orders_search_trigger()`,
+	`DROP TRIGGER IF EXISTS items_search ON items`,
+	`CREATE TRIGGER items_search AFTER INSERT OR UPDATE OR DELETE ON items
+		FOR EACH ROW EXECUTE FUNCTION orders_search_trigger()`,
+
+	`CREATE INDEX IF NOT EXISTS idx_orders_search_vector ON orders USING GIN (search_vector)`,
+	`CREATE INDEX IF NOT EXISTS idx_orders_search_public ON orders USING GIN (search_public)`,
+
+	// Заказы, созданные до появления поиска или search_public.
+	`SELECT orders_search_refresh(order_uid) FROM orders WHERE search_vector IS NULL OR search_public IS NULL`,
+}
+
+func setupSearch(db *gorm.DB) error {
+	err := db.Transaction(func(tx *gorm.DB) error {
+		for _, stmt := range searchStatements {
+			if err := tx.Exec(stmt).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// Для поиска по середине номера нужен pg_trgm. Без него поиск работает,
+	// но по телефону — последовательным сканированием.
+	err = db.Exec(`CREATE EXTENSION IF NOT EXISTS pg_trgm`).Error
+	if err == nil {
+		err = db.Exec(`CREATE INDEX IF NOT EXISTS idx_orders_search_phone ON orders USING GIN (search_phone gin_trgm_ops)`).Error
+	}
+	if err != nil {
+		log.Printf("Phone search index is not available: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+// Package dto описывает версии формата заказа, которые присылают продюсеры,
+// отдельно от GORM-моделей, и приводит любую поддерживаемую версию к
+// текущей доменной модели.
+package dto
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"orderkeeper/internal/models"
+	"strconv"
+)
+
+// VersionHeader — заголовок Kafka с версией схемы. Вместо него версию можно
+// передать в конверте {"schema_version": N, "payload": {...}}. Сообщения
+// без версии считаются версией 1.
+const VersionHeader = "schema-version"
+
+const CurrentVersion = 2
+
+var ErrUnsupportedSchemaVersion = errors.New("unsupported schema version")
+
+type Envelope struct {
+	SchemaVersion int             `json:"schema_version"`
+	Payload       json.RawMessage `json:"payload"`
+}
+
+// decoders разбирают payload своей версии и поднимают его до текущей.
+var decoders = map[int]func(payload []byte) (OrderV2, error){
+	1: func(payload []byte) (OrderV2, error) {
+		var o OrderV1
+		if err := decodeStrict(payload, &o); err != nil {
+			return OrderV2{}, err
+		}
+		return upcastV1(o), nil
+	},
+	2: func(payload []byte) (OrderV2, error) {
+		var o OrderV2
+		if err := decodeStrict(payload, &o); err != nil {
+			return OrderV2{}, err
+		}
+		return o, nil
+	},
+}
+
+// DecodeOrder разбирает сообщение с заказом любой поддерживаемой версии.
+// headerVersion — значение VersionHeader, пустое, если заголовка нет.
+func DecodeOrder(data []byte, headerVersion string) (models.Order, error) {
+	version, payload, err := unwrap(data, headerVersion)
+	if err != nil {
+		return models.Order{}, err
+	}
+	decode, ok := decoders[version]
+	if !ok {
+		return models.Order{}, fmt.Errorf("%w: %d (supported: 1..%d)", ErrUnsupportedSchemaVersion, version, CurrentVersion)
+	}
+	order, err := decode(payload)
+	if err != nil {
+		return models.Order{}, fmt.Errorf("invalid order v%d payload: %w", version, err)
+	}
+	return order.ToModel(), nil
+}
+
+func unwrap(data []byte, headerVersion string) (int, []byte, error) {
+	if headerVersion != "" {
+		version, err := strconv.Atoi(headerVersion)
+		if err != nil {
+			return 0, nil, fmt.Errorf("%w: %q", ErrUnsupportedSchemaVersion, headerVersion)
+		}
+		return version, data, nil
+	}
+
+	var probe map[string]json.RawMessage
+	if err := json.Unmarshal(data, &probe); err != nil {
+		return 0, nil, err
+	}
+	if _, ok := probe["schema_version"]; !ok {
+		return 1, data, nil
+	}
+
+	var env Envelope
+	if err := decodeStrict(data, &env); err != nil {
+		return 0, nil, fmt.Errorf("invalid envelope: %w", err)
+	}
+	if len(env.Payload) == 0 {
+		return 0, nil, errors.New("invalid envelope: payload is missing")
+	}
+	return env.SchemaVersion, env.Payload, nil
+}
+
+// decodeStrict не допускает неизвестных полей, чтобы переименованные
+// продюсером поля не терялись молча.
+func decodeStrict(data []byte, v any) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	return dec.Decode(v)
+}
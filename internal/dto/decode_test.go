@@ -0,0 +1,74 @@
+package dto
+
+import (
+	"encoding/json"
+	"errors"
+	"orderkeeper/internal/models"
+	"orderkeeper/internal/money"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const orderV1 = `{"order_uid":"uid-1","track_number":"TRACK","payment":{"transaction":"uid-1","currency":"USD","amount":1817},"items":[{"chrt_id":1,"name":"Mascaras","price":453}]}`
+
+func TestDecodeOrder(t *testing.T) {
+	t.Run("bare v1 payload is upcast", func(t *testing.T) {
+		order, err := DecodeOrder([]byte(orderV1), "")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "uid-1", order.OrderUID)
+		assert.Equal(t, models.OrderStatusCreated, order.Status)
+		assert.Equal(t, money.New(1817, "USD"), order.Payment.Amount)
+		assert.Equal(t, money.New(453, "USD"), order.Items[0].Price)
+		assert.Equal(t, "uid-1", order.Items[0].OrderUID)
+	})
+
+	t.Run("v2 envelope", func(t *testing.T) {
+		data := `{"schema_version":2,"payload":{"order_uid":"uid-2","status":"paid"}}`
+
+		order, err := DecodeOrder([]byte(data), "")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "uid-2", order.OrderUID)
+		assert.Equal(t, models.OrderStatusPaid, order.Status)
+	})
+
+	t.Run("version from header", func(t *testing.T) {
+		order, err := DecodeOrder([]byte(`{"order_uid":"uid-3","status":"shipped"}`), "2")
+
+		assert.NoError(t, err)
+		assert.Equal(t, models.OrderStatusShipped, order.Status)
+	})
+
+	t.Run("unsupported version", func(t *testing.T) {
+		_, err := DecodeOrder([]byte(`{"schema_version":99,"payload":{}}`), "")
+		assert.True(t, errors.Is(err, ErrUnsupportedSchemaVersion))
+
+		_, err = DecodeOrder([]byte(orderV1), "latest")
+		assert.True(t, errors.Is(err, ErrUnsupportedSchemaVersion))
+	})
+
+	t.Run("unknown field is rejected", func(t *testing.T) {
+		_, err := DecodeOrder([]byte(`{"order_uid":"uid-4","created_at":"2021-11-26T06:22:19Z"}`), "")
+		assert.ErrorContains(t, err, "created_at")
+	})
+
+	t.Run("v1 does not know status", func(t *testing.T) {
+		_, err := DecodeOrder([]byte(`{"order_uid":"uid-5","status":"paid"}`), "1")
+		assert.Error(t, err)
+	})
+}
+
+func TestFromModel(t *testing.T) {
+	order, err := DecodeOrder([]byte(orderV1), "")
+	assert.NoError(t, err)
+	order.Status = models.OrderStatusPaid
+
+	data, err := json.Marshal(FromModel(order))
+	assert.NoError(t, err)
+	decoded, err := DecodeOrder(data, "2")
+
+	assert.NoError(t, err)
+	assert.Equal(t, order, decoded)
+}
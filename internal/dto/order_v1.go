@@ -0,0 +1,71 @@
+package dto
+
+import (
+	"orderkeeper/internal/models"
+	"orderkeeper/internal/money"
+)
+
+// OrderV1 — исходный формат заказа без версии: продюсеры присылают его
+// как есть, без конверта и заголовка schema-version.
+type OrderV1 struct {
+	OrderUID          string           `json:"order_uid"`
+	TrackNumber       string           `json:"track_number"`
+	Entry             string           `json:"entry"`
+	Delivery          DeliveryV1       `json:"delivery"`
+	Payment           PaymentV1        `json:"payment"`
+	Items             []ItemV1         `json:"items"`
+	Locale            string           `json:"locale"`
+	InternalSignature string           `json:"internal_signature"`
+	CustomerID        string           `json:"customer_id"`
+	DeliveryService   string           `json:"delivery_service"`
+	Shardkey          string           `json:"shardkey"`
+	SMID              int              `json:"sm_id"`
+	DateCreated       models.Timestamp `json:"date_created"`
+	OOFShard          string           `json:"oof_shard"`
+}
+
+type DeliveryV1 struct {
+	Name    string `json:"name"`
+	Phone   string `json:"phone"`
+	Zip     string `json:"zip"`
+	City    string `json:"city"`
+	Address string `json:"address"`
+	Region  string `json:"region"`
+	Email   string `json:"email"`
+}
+
+type PaymentV1 struct {
+	Transaction  string          `json:"transaction"`
+	RequestID    string          `json:"request_id"`
+	Currency     money.Currency  `json:"currency"`
+	Provider     string          `json:"provider"`
+	Amount       money.Amount    `json:"amount"`
+	PaymentDT    models.UnixTime `json:"payment_dt"`
+	Bank         string          `json:"bank"`
+	DeliveryCost money.Amount    `json:"delivery_cost"`
+	GoodsTotal   money.Amount    `json:"goods_total"`
+	CustomFee    money.Amount    `json:"custom_fee"`
+}
+
+type ItemV1 struct {
+	CHRTID      int          `json:"chrt_id"`
+	TrackNumber string       `json:"track_number"`
+	Price       money.Amount `json:"price"`
+	RID         string       `json:"rid"`
+	Name        string       `json:"name"`
+	Sale        int          `json:"sale"`
+	Size        string       `json:"size"`
+	TotalPrice  money.Amount `json:"total_price"`
+	NMID        int          `json:"nm_id"`
+	Brand       string       `json:"brand"`
+	Status      int          `json:"status"`
+}
+
+// upcastV1 переводит заказ v1 в v2: в v1 не было статуса заказа, все
+// такие заказы считаются только что созданными.
+func upcastV1(o OrderV1) OrderV2 {
+	return OrderV2{
+		OrderV1: o,
+		Status:  models.OrderStatusCreated,
+	}
+}
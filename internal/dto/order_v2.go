@@ -0,0 +1,131 @@
+package dto
+
+import (
+	"orderkeeper/internal/models"
+	"orderkeeper/internal/money"
+)
+
+// OrderV2 — текущая версия формата: v1 плюс статус заказа.
+type OrderV2 struct {
+	OrderV1
+	Status string `json:"status"`
+}
+
+func (o OrderV2) ToModel() models.Order {
+	items := make([]models.Item, 0, len(o.Items))
+	for _, it := range o.Items {
+		items = append(items, models.Item{
+			OrderUID:    o.OrderUID,
+			CHRTID:      it.CHRTID,
+			TrackNumber: it.TrackNumber,
+			Price:       money.New(it.Price, o.Payment.Currency),
+			RID:         it.RID,
+			Name:        it.Name,
+			Sale:        it.Sale,
+			Size:        it.Size,
+			TotalPrice:  money.New(it.TotalPrice, o.Payment.Currency),
+			NMID:        it.NMID,
+			Brand:       it.Brand,
+			Status:      it.Status,
+		})
+	}
+
+	return models.Order{
+		OrderUID:    o.OrderUID,
+		TrackNumber: o.TrackNumber,
+		Entry:       o.Entry,
+		Delivery: models.Delivery{
+			OrderUID: o.OrderUID,
+			Name:     o.Delivery.Name,
+			Phone:    o.Delivery.Phone,
+			Zip:      o.Delivery.Zip,
+			City:     o.Delivery.City,
+			Address:  o.Delivery.Address,
+			Region:   o.Delivery.Region,
+			Email:    o.Delivery.Email,
+		},
+		Payment: models.Payment{
+			OrderUID:     o.OrderUID,
+			Transaction:  o.Payment.Transaction,
+			RequestID:    o.Payment.RequestID,
+			Currency:     o.Payment.Currency,
+			Provider:     o.Payment.Provider,
+			Amount:       money.New(o.Payment.Amount, o.Payment.Currency),
+			PaymentDT:    o.Payment.PaymentDT,
+			Bank:         o.Payment.Bank,
+			DeliveryCost: money.New(o.Payment.DeliveryCost, o.Payment.Currency),
+			GoodsTotal:   money.New(o.Payment.GoodsTotal, o.Payment.Currency),
+			CustomFee:    money.New(o.Payment.CustomFee, o.Payment.Currency),
+		},
+		Items:             items,
+		Locale:            o.Locale,
+		InternalSignature: o.InternalSignature,
+		CustomerID:        o.CustomerID,
+		DeliveryService:   o.DeliveryService,
+		Shardkey:          o.Shardkey,
+		SMID:              o.SMID,
+		DateCreated:       o.DateCreated,
+		OOFShard:          o.OOFShard,
+		Status:            o.Status,
+	}
+}
+
+// FromModel переводит доменную модель в текущую версию формата — в том виде,
+// в каком заказ присылают продюсеры.
+func FromModel(order models.Order) OrderV2 {
+	items := make([]ItemV1, 0, len(order.Items))
+	for _, it := range order.Items {
+		items = append(items, ItemV1{
+			CHRTID:      it.CHRTID,
+			TrackNumber: it.TrackNumber,
+			Price:       it.Price.Amount,
+			RID:         it.RID,
+			Name:        it.Name,
+			Sale:        it.Sale,
+			Size:        it.Size,
+			TotalPrice:  it.TotalPrice.Amount,
+			NMID:        it.NMID,
+			Brand:       it.Brand,
+			Status:      it.Status,
+		})
+	}
+
+	return OrderV2{
+		OrderV1: OrderV1{
+			OrderUID:    order.OrderUID,
+			TrackNumber: order.TrackNumber,
+			Entry:       order.Entry,
+			Delivery: DeliveryV1{
+				Name:    order.Delivery.Name,
+				Phone:   order.Delivery.Phone,
+				Zip:     order.Delivery.Zip,
+				City:    order.Delivery.City,
+				Address: order.Delivery.Address,
+				Region:  order.Delivery.Region,
+				Email:   order.Delivery.Email,
+			},
+			Payment: PaymentV1{
+				Transaction:  order.Payment.Transaction,
+				RequestID:    order.Payment.RequestID,
+				Currency:     order.Payment.Currency,
+				Provider:     order.Payment.Provider,
+				Amount:       order.Payment.Amount.Amount,
+				PaymentDT:    order.Payment.PaymentDT,
+				Bank:         order.Payment.Bank,
+				DeliveryCost: order.Payment.DeliveryCost.Amount,
+				GoodsTotal:   order.Payment.GoodsTotal.Amount,
+				CustomFee:    order.Payment.CustomFee.Amount,
+			},
+			Items:             items,
+			Locale:            order.Locale,
+			InternalSignature: order.InternalSignature,
+			CustomerID:        order.CustomerID,
+			DeliveryService:   order.DeliveryService,
+			Shardkey:          order.Shardkey,
+			SMID:              order.SMID,
+			DateCreated:       order.DateCreated,
+			OOFShard:          order.OOFShard,
+		},
+		Status: order.Status,
+	}
+}
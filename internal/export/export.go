@@ -0,0 +1,266 @@
+// Package export записывает заказы потоком в NDJSON, CSV и Parquet.
+// Writer получает заказы пачками и не держит в памяти больше одной пачки.
+// Плоский CSV разбирается обратно в заказы для импорта (см. parse.go).
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"orderkeeper/internal/models"
+	"strconv"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+const (
+	FormatNDJSON  = "ndjson"
+	FormatCSV     = "csv"
+	FormatParquet = "parquet"
+)
+
+type Writer interface {
+	// WriteBatch записывает пачку заказов и сбрасывает ее в w.
+	WriteBatch(orders []models.Order) error
+	// Close дописывает окончание файла; для Parquet без него файл нечитаем.
+	Close() error
+}
+
+// ContentType возвращает MIME-тип формата.
+func ContentType(format string) string {
+	switch format {
+	case FormatCSV:
+		return "text/csv; charset=utf-8"
+	case FormatParquet:
+		return "application/vnd.apache.parquet"
+	default:
+		return "application/x-ndjson"
+	}
+}
+
+// ParseFilterTime разбирает границу периода выгрузки: дату YYYY-MM-DD или
+// время в RFC 3339. Пустая строка — нулевое время, то есть без границы.
+func ParseFilterTime(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse("2006-01-02", s); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, s)
+}
+
+// ParseFilterBool разбирает флаг фильтра выгрузки. Пустая строка — nil,
+// то есть без ограничения.
+func ParseFilterBool(s string) (*bool, error) {
+	if s == "" {
+		return nil, nil
+	}
+	v, err := strconv.ParseBool(s)
+	if err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+func NewWriter(format string, w io.Writer) (Writer, error) {
+	switch format {
+	case "", FormatNDJSON:
+		return &ndjsonWriter{enc: json.NewEncoder(w)}, nil
+	case FormatCSV:
+		return newCSVWriter(w)
+	case FormatParquet:
+		return &parquetWriter{w: parquet.NewGenericWriter[Row](w)}, nil
+	default:
+		return nil, fmt.Errorf("unsupported export format %q", format)
+	}
+}
+
+type ndjsonWriter struct {
+	enc *json.Encoder
+}
+
+func (w *ndjsonWriter) WriteBatch(orders []models.Order) error {
+	for _, order := range orders {
+		if err := w.enc.Encode(order); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *ndjsonWriter) Close() error { return nil }
+
+// Row — плоская строка экспорта: заказ, доставка и оплата, повторенные для
+// каждой позиции. Заказ без позиций дает одну строку с пустыми полями позиции.
+type Row struct {
+	OrderUID        string    `parquet:"order_uid"`
+	TrackNumber     string    `parquet:"track_number"`
+	Entry           string    `parquet:"entry"`
+	Locale          string    `parquet:"locale"`
+	CustomerID      string    `parquet:"customer_id"`
+	DeliveryService string    `parquet:"delivery_service"`
+	Status          string    `parquet:"status"`
+	DateCreated     time.Time `parquet:"date_created,timestamp(millisecond)"`
+
+	DeliveryName    string `parquet:"delivery_name"`
+	DeliveryPhone   string `parquet:"delivery_phone"`
+	DeliveryZip     string `parquet:"delivery_zip"`
+	DeliveryCity    string `parquet:"delivery_city"`
+	DeliveryAddress string `parquet:"delivery_address"`
+	DeliveryRegion  string `parquet:"delivery_region"`
+	DeliveryEmail   string `parquet:"delivery_email"`
+
+	PaymentTransaction  string    `parquet:"payment_transaction"`
+	PaymentCurrency     string    `parquet:"payment_currency"`
+	PaymentProvider     string    `parquet:"payment_provider"`
+	PaymentAmount       int64     `parquet:"payment_amount"`
+	PaymentDT           time.Time `parquet:"payment_dt,timestamp(millisecond)"`
+	PaymentBank         string    `parquet:"payment_bank"`
+	PaymentDeliveryCost int64     `parquet:"payment_delivery_cost"`
+	PaymentGoodsTotal   int64     `parquet:"payment_goods_total"`
+	PaymentCustomFee    int64     `parquet:"payment_custom_fee"`
+
+	ItemCHRTID      int64  `parquet:"item_chrt_id"`
+	ItemTrackNumber string `parquet:"item_track_number"`
+	ItemPrice       int64  `parquet:"item_price"`
+	ItemRID         string `parquet:"item_rid"`
+	ItemName        string `parquet:"item_name"`
+	ItemSale        int64  `parquet:"item_sale"`
+	ItemSize        string `parquet:"item_size"`
+	ItemTotalPrice  int64  `parquet:"item_total_price"`
+	ItemNMID        int64  `parquet:"item_nm_id"`
+	ItemBrand       string `parquet:"item_brand"`
+	ItemStatus      int64  `parquet:"item_status"`
+}
+
+// Flatten разворачивает заказ в строки по одной на позицию.
+func Flatten(order models.Order) []Row {
+	base := Row{
+		OrderUID:        order.OrderUID,
+		TrackNumber:     order.TrackNumber,
+		Entry:           order.Entry,
+		Locale:          order.Locale,
+		CustomerID:      order.CustomerID,
+		DeliveryService: order.DeliveryService,
+		Status:          order.Status,
+		DateCreated:     order.DateCreated.UTC(),
+
+		DeliveryName:    order.Delivery.Name,
+		DeliveryPhone:   order.Delivery.Phone,
+		DeliveryZip:     order.Delivery.Zip,
+		DeliveryCity:    order.Delivery.City,
+		DeliveryAddress: order.Delivery.Address,
+		DeliveryRegion:  order.Delivery.Region,
+		DeliveryEmail:   order.Delivery.Email,
+
+		PaymentTransaction:  order.Payment.Transaction,
+		PaymentCurrency:     string(order.Payment.Currency),
+		PaymentProvider:     order.Payment.Provider,
+		PaymentAmount:       int64(order.Payment.Amount.Amount),
+		PaymentDT:           order.Payment.PaymentDT.UTC(),
+		PaymentBank:         order.Payment.Bank,
+		PaymentDeliveryCost: int64(order.Payment.DeliveryCost.Amount),
+		PaymentGoodsTotal:   int64(order.Payment.GoodsTotal.Amount),
+		PaymentCustomFee:    int64(order.Payment.CustomFee.Amount),
+	}
+	if len(order.Items) == 0 {
+		return []Row{base}
+	}
+	rows := make([]Row, len(order.Items))
+	for i, item := range order.Items {
+		row := base
+		row.ItemCHRTID = int64(item.CHRTID)
+		row.ItemTrackNumber = item.TrackNumber
+		row.ItemPrice = int64(item.Price.Amount)
+		row.ItemRID = item.RID
+		row.ItemName = item.Name
+		row.ItemSale = int64(item.Sale)
+		row.ItemSize = item.Size
+		row.ItemTotalPrice = int64(item.TotalPrice.Amount)
+		row.ItemNMID = int64(item.NMID)
+		row.ItemBrand = item.Brand
+		row.ItemStatus = int64(item.Status)
+		rows[i] = row
+	}
+	return rows
+}
+
+var csvHeader = []string{
+	"order_uid", "track_number", "entry", "locale", "customer_id", "delivery_service", "status", "date_created",
+	"delivery_name", "delivery_phone", "delivery_zip", "delivery_city", "delivery_address", "delivery_region", "delivery_email",
+	"payment_transaction", "payment_currency", "payment_provider", "payment_amount", "payment_dt", "payment_bank",
+	"payment_delivery_cost", "payment_goods_total", "payment_custom_fee",
+	"item_chrt_id", "item_track_number", "item_price", "item_rid", "item_name", "item_sale", "item_size",
+	"item_total_price", "item_nm_id", "item_brand", "item_status",
+}
+
+// CSVHeader возвращает заголовок плоского CSV.
+func CSVHeader() []string {
+	return append([]string(nil), csvHeader...)
+}
+
+func (r Row) csvRecord() []string {
+	i := func(n int64) string { return strconv.FormatInt(n, 10) }
+	t := func(v time.Time) string { return v.Format(time.RFC3339) }
+	return []string{
+		r.OrderUID, r.TrackNumber, r.Entry, r.Locale, r.CustomerID, r.DeliveryService, r.Status, t(r.DateCreated),
+		r.DeliveryName, r.DeliveryPhone, r.DeliveryZip, r.DeliveryCity, r.DeliveryAddress, r.DeliveryRegion, r.DeliveryEmail,
+		r.PaymentTransaction, r.PaymentCurrency, r.PaymentProvider, i(r.PaymentAmount), t(r.PaymentDT), r.PaymentBank,
+		i(r.PaymentDeliveryCost), i(r.PaymentGoodsTotal), i(r.PaymentCustomFee),
+		i(r.ItemCHRTID), r.ItemTrackNumber, i(r.ItemPrice), r.ItemRID, r.ItemName, i(r.ItemSale), r.ItemSize,
+		i(r.ItemTotalPrice), i(r.ItemNMID), r.ItemBrand, i(r.ItemStatus),
+	}
+}
+
+type csvWriter struct {
+	w *csv.Writer
+}
+
+func newCSVWriter(w io.Writer) (*csvWriter, error) {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvHeader); err != nil {
+		return nil, err
+	}
+	return &csvWriter{w: cw}, nil
+}
+
+func (w *csvWriter) WriteBatch(orders []models.Order) error {
+	for _, order := range orders {
+		for _, row := range Flatten(order) {
+			if err := w.w.Write(row.csvRecord()); err != nil {
+				return err
+			}
+		}
+	}
+	w.w.Flush()
+	return w.w.Error()
+}
+
+func (w *csvWriter) Close() error {
+	w.w.Flush()
+	return w.w.Error()
+}
+
+type parquetWriter struct {
+	w *parquet.GenericWriter[Row]
+}
+
+// WriteBatch записывает пачку отдельной группой строк, чтобы в памяти не
+// копились данные всего экспорта.
+func (w *parquetWriter) WriteBatch(orders []models.Order) error {
+	var rows []Row
+	for _, order := range orders {
+		rows = append(rows, Flatten(order)...)
+	}
+	if _, err := w.w.Write(rows); err != nil {
+		return err
+	}
+	return w.w.Flush()
+}
+
+func (w *parquetWriter) Close() error {
+	return w.w.Close()
+}
@@ -0,0 +1,140 @@
+package export
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"orderkeeper/internal/models"
+	"orderkeeper/internal/money"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testOrders() []models.Order {
+	created := models.Timestamp{Time: time.Date(2021, 11, 26, 6, 22, 19, 0, time.UTC)}
+	return []models.Order{
+		{
+			OrderUID:    "uid-1",
+			TrackNumber: "TRACK",
+			DateCreated: created,
+			Delivery:    models.Delivery{Name: "Test Testov", City: "Kiryat Mozkin"},
+			Payment:     models.Payment{Currency: "USD", Amount: money.New(1817, "USD")},
+			Items: []models.Item{
+				{CHRTID: 1, Name: "Mascaras", Price: money.New(453, "USD"), TotalPrice: money.New(317, "USD")},
+				{CHRTID: 2, Name: "Lipstick, red", Price: money.New(100, "USD"), TotalPrice: money.New(100, "USD")},
+			},
+		},
+		{OrderUID: "uid-2", DateCreated: created},
+	}
+}
+
+func TestNDJSON(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(FormatNDJSON, &buf)
+	require.NoError(t, err)
+
+	require.NoError(t, w.WriteBatch(testOrders()))
+	require.NoError(t, w.Close())
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 2)
+	var order models.Order
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &order))
+	assert.Equal(t, "uid-1", order.OrderUID)
+	assert.Len(t, order.Items, 2)
+}
+
+func TestCSV(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(FormatCSV, &buf)
+	require.NoError(t, err)
+
+	require.NoError(t, w.WriteBatch(testOrders()))
+	require.NoError(t, w.Close())
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 4)
+	assert.Equal(t, CSVHeader(), records[0])
+	for _, record := range records {
+		assert.Len(t, record, len(CSVHeader()))
+	}
+	assert.Equal(t, "uid-1", records[1][0])
+	assert.Equal(t, "Lipstick, red", records[2][28])
+	assert.Equal(t, "uid-2", records[3][0])
+	assert.Equal(t, "2021-11-26T06:22:19Z", records[3][7])
+}
+
+func TestParquet(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(FormatParquet, &buf)
+	require.NoError(t, err)
+
+	orders := testOrders()
+	require.NoError(t, w.WriteBatch(orders[:1]))
+	require.NoError(t, w.WriteBatch(orders[1:]))
+	require.NoError(t, w.Close())
+
+	rows, err := parquet.Read[Row](bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	require.Len(t, rows, 3)
+	assert.Equal(t, "Mascaras", rows[0].ItemName)
+	assert.Equal(t, int64(1817), rows[1].PaymentAmount)
+	assert.Equal(t, "uid-2", rows[2].OrderUID)
+	assert.True(t, orders[0].DateCreated.Equal(rows[2].DateCreated))
+}
+
+func TestUnsupportedFormat(t *testing.T) {
+	_, err := NewWriter("xml", &bytes.Buffer{})
+	assert.Error(t, err)
+}
+
+func TestParseCSV(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(FormatCSV, &buf)
+	require.NoError(t, err)
+	require.NoError(t, w.WriteBatch(testOrders()))
+	require.NoError(t, w.Close())
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+	cols, err := ParseCSVHeader(records[0])
+	require.NoError(t, err)
+
+	var rows []Row
+	for _, record := range records[1:3] {
+		row, err := cols.ParseRow(record)
+		require.NoError(t, err)
+		rows = append(rows, row)
+	}
+	order := Assemble(rows)
+	want := testOrders()[0]
+	assert.Equal(t, want.OrderUID, order.OrderUID)
+	assert.True(t, want.DateCreated.Equal(order.DateCreated.Time))
+	assert.Equal(t, want.Payment.Amount, order.Payment.Amount)
+	require.Len(t, order.Items, 2)
+	assert.Equal(t, "Lipstick, red", order.Items[1].Name)
+
+	row, err := cols.ParseRow(records[3])
+	require.NoError(t, err)
+	assert.Empty(t, Assemble([]Row{row}).Items)
+
+	t.Run("bad values", func(t *testing.T) {
+		record := append([]string(nil), records[1]...)
+		record[cols["payment_amount"]] = "12.5"
+		_, err := cols.ParseRow(record)
+		assert.ErrorContains(t, err, "payment_amount")
+	})
+
+	t.Run("bad header", func(t *testing.T) {
+		_, err := ParseCSVHeader([]string{"order_uid", "colour"})
+		assert.ErrorContains(t, err, "colour")
+		_, err = ParseCSVHeader([]string{"track_number"})
+		assert.Error(t, err)
+	})
+}
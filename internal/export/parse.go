@@ -0,0 +1,183 @@
+package export
+
+import (
+	"errors"
+	"fmt"
+	"orderkeeper/internal/models"
+	"orderkeeper/internal/money"
+	"strconv"
+	"time"
+)
+
+// CSVColumns сопоставляет заголовку плоского CSV индексы колонок. Колонки
+// могут идти в любом порядке, обязательна только order_uid.
+type CSVColumns map[string]int
+
+func ParseCSVHeader(header []string) (CSVColumns, error) {
+	known := make(map[string]bool, len(csvHeader))
+	for _, name := range csvHeader {
+		known[name] = true
+	}
+	cols := make(CSVColumns, len(header))
+	for i, name := range header {
+		if !known[name] {
+			return nil, fmt.Errorf("unknown CSV column %q", name)
+		}
+		if _, dup := cols[name]; dup {
+			return nil, fmt.Errorf("duplicate CSV column %q", name)
+		}
+		cols[name] = i
+	}
+	if _, ok := cols["order_uid"]; !ok {
+		return nil, errors.New("CSV header has no order_uid column")
+	}
+	return cols, nil
+}
+
+// ParseRow разбирает запись CSV, записанную csvWriter. Пустые числа и
+// даты считаются нулевыми значениями.
+func (c CSVColumns) ParseRow(record []string) (Row, error) {
+	p := rowParser{cols: c, record: record}
+	row := Row{
+		OrderUID:        p.str("order_uid"),
+		TrackNumber:     p.str("track_number"),
+		Entry:           p.str("entry"),
+		Locale:          p.str("locale"),
+		CustomerID:      p.str("customer_id"),
+		DeliveryService: p.str("delivery_service"),
+		Status:          p.str("status"),
+		DateCreated:     p.time("date_created"),
+
+		DeliveryName:    p.str("delivery_name"),
+		DeliveryPhone:   p.str("delivery_phone"),
+		DeliveryZip:     p.str("delivery_zip"),
+		DeliveryCity:    p.str("delivery_city"),
+		DeliveryAddress: p.str("delivery_address"),
+		DeliveryRegion:  p.str("delivery_region"),
+		DeliveryEmail:   p.str("delivery_email"),
+
+		PaymentTransaction:  p.str("payment_transaction"),
+		PaymentCurrency:     p.str("payment_currency"),
+		PaymentProvider:     p.str("payment_provider"),
+		PaymentAmount:       p.int("payment_amount"),
+		PaymentDT:           p.time("payment_dt"),
+		PaymentBank:         p.str("payment_bank"),
+		PaymentDeliveryCost: p.int("payment_delivery_cost"),
+		PaymentGoodsTotal:   p.int("payment_goods_total"),
+		PaymentCustomFee:    p.int("payment_custom_fee"),
+
+		ItemCHRTID:      p.int("item_chrt_id"),
+		ItemTrackNumber: p.str("item_track_number"),
+		ItemPrice:       p.int("item_price"),
+		ItemRID:         p.str("item_rid"),
+		ItemName:        p.str("item_name"),
+		ItemSale:        p.int("item_sale"),
+		ItemSize:        p.str("item_size"),
+		ItemTotalPrice:  p.int("item_total_price"),
+		ItemNMID:        p.int("item_nm_id"),
+		ItemBrand:       p.str("item_brand"),
+		ItemStatus:      p.int("item_status"),
+	}
+	return row, p.err
+}
+
+type rowParser struct {
+	cols   CSVColumns
+	record []string
+	err    error
+}
+
+func (p *rowParser) str(name string) string {
+	i, ok := p.cols[name]
+	if !ok || i >= len(p.record) {
+		return ""
+	}
+	return p.record[i]
+}
+
+func (p *rowParser) int(name string) int64 {
+	s := p.str(name)
+	if s == "" || p.err != nil {
+		return 0
+	}
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		p.err = fmt.Errorf("column %s: invalid integer %q", name, s)
+	}
+	return n
+}
+
+func (p *rowParser) time(name string) time.Time {
+	s := p.str(name)
+	if s == "" || p.err != nil {
+		return time.Time{}
+	}
+	t, err := models.ParseTime(s)
+	if err != nil {
+		p.err = fmt.Errorf("column %s: %w", name, err)
+	}
+	return t
+}
+
+// Assemble собирает заказ из строк, полученных Flatten для одного заказа.
+// Поля заказа берутся из первой строки; строка без позиции (item_chrt_id и
+// item_name пусты) позиций не добавляет.
+func Assemble(rows []Row) models.Order {
+	if len(rows) == 0 {
+		return models.Order{}
+	}
+	r := rows[0]
+	currency := money.Currency(r.PaymentCurrency)
+	order := models.Order{
+		OrderUID:        r.OrderUID,
+		TrackNumber:     r.TrackNumber,
+		Entry:           r.Entry,
+		Locale:          r.Locale,
+		CustomerID:      r.CustomerID,
+		DeliveryService: r.DeliveryService,
+		Status:          r.Status,
+		DateCreated:     models.Timestamp{Time: r.DateCreated},
+		Delivery: models.Delivery{
+			OrderUID: r.OrderUID,
+			Name:     r.DeliveryName,
+			Phone:    r.DeliveryPhone,
+			Zip:      r.DeliveryZip,
+			City:     r.DeliveryCity,
+			Address:  r.DeliveryAddress,
+			Region:   r.DeliveryRegion,
+			Email:    r.DeliveryEmail,
+		},
+		Payment: models.Payment{
+			OrderUID:     r.OrderUID,
+			Transaction:  r.PaymentTransaction,
+			Currency:     currency,
+			Provider:     r.PaymentProvider,
+			Amount:       money.New(money.Amount(r.PaymentAmount), currency),
+			PaymentDT:    models.UnixTime{Time: r.PaymentDT},
+			Bank:         r.PaymentBank,
+			DeliveryCost: money.New(money.Amount(r.PaymentDeliveryCost), currency),
+			GoodsTotal:   money.New(money.Amount(r.PaymentGoodsTotal), currency),
+			CustomFee:    money.New(money.Amount(r.PaymentCustomFee), currency),
+		},
+	}
+	for _, r := range rows {
+		if r.ItemCHRTID == 0 && r.ItemName == "" {
+			continue
+		}
+		order.Items = append(order.Items, models.Item{
+			OrderUID:    r.OrderUID,
+			CHRTID:      int(r.ItemCHRTID),
+			TrackNumber: r.ItemTrackNumber,
+			Price:       money.New(money.Amount(r.ItemPrice), currency),
+			RID:         r.ItemRID,
+			Name:        r.ItemName,
+			Sale:        int(r.ItemSale),
+			Size:        r.ItemSize,
+			TotalPrice:  money.New(money.Amount(r.ItemTotalPrice), currency),
+			NMID:        int(r.ItemNMID),
+			Brand:       r.ItemBrand,
+			Status:      int(r.ItemStatus),
+		})
+	}
+	return order
+}
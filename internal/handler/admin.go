@@ -0,0 +1,253 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"orderkeeper/internal/cache"
+	"orderkeeper/internal/kafka"
+	"orderkeeper/pkg/utils"
+)
+
+// ReloadFunc перечитывает настройки и применяет их к работающему приложению.
+type ReloadFunc func() (any, error)
+
+// ErrNoConfigFile возвращает ReloadFunc, если настройки неоткуда
+// перечитать: без CONFIG_FILE они берутся из окружения процесса, а оно после
+// запуска не меняется.
+var ErrNoConfigFile = errors.New("CONFIG_FILE is not set, nothing to reload")
+
+// CacheStatsFunc возвращает состояние кеша заказов.
+type CacheStatsFunc func() cache.Stats
+
+// ConsumerAdmin — операции управления Kafka-консьюмером.
+type ConsumerAdmin interface {
+	Pause()
+	Resume()
+	Seek(ctx context.Context, req kafka.SeekRequest) ([]kafka.PartitionOffset, error)
+	Replay(ctx context.Context, req kafka.ReplayRequest) (kafka.ReplayResult, error)
+	Position(ctx context.Context) (kafka.Position, error)
+	ValidationReport() *kafka.ValidationSummary
+}
+
+type AdminHandler struct {
+	reload     ReloadFunc
+	consumer   ConsumerAdmin
+	cacheStats CacheStatsFunc
+}
+
+func NewAdminHandler(reload ReloadFunc, consumer ConsumerAdmin, cacheStats CacheStatsFunc) *AdminHandler {
+	return &AdminHandler{reload: reload, consumer: consumer, cacheStats: cacheStats}
+}
+
+// ReloadHandler godoc
+// @Summary Reload runtime settings
+// @Description Re-read runtime-tunable settings (cache capacity, log level, consumer concurrency) from CONFIG_FILE and apply them without restart. Returns 409 if CONFIG_FILE is not set.
+// @Tags admin
+// @Produce  json
+// @Success 200 {object} map[string]interface{}
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /admin/reload [post]
+func (h *AdminHandler) ReloadHandler(w http.ResponseWriter, r *http.Request) {
+	settings, err := h.reload()
+	if errors.Is(err, ErrNoConfigFile) {
+		utils.JSONResponse(w, http.StatusConflict, map[string]string{
+			"error": err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		utils.JSONResponse(w, http.StatusInternalServerError, map[string]string{
+			"error": "Reload failed: " + err.Error(),
+		})
+		return
+	}
+	utils.JSONResponse(w, http.StatusOK, map[string]any{
+		"message":  "Settings reloaded",
+		"settings": settings,
+	})
+}
+
+// PauseConsumerHandler godoc
+// @Summary Pause the Kafka consumer
+// @Description Stop fetching new messages; messages already fetched are still processed
+// @Tags admin
+// @Produce  json
+// @Success 200 {object} map[string]string
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /admin/consumer/pause [post]
+func (h *AdminHandler) PauseConsumerHandler(w http.ResponseWriter, r *http.Request) {
+	h.consumer.Pause()
+	utils.JSONResponse(w, http.StatusOK, map[string]string{
+		"message": "Consumer paused",
+	})
+}
+
+// ResumeConsumerHandler godoc
+// @Summary Resume the Kafka consumer
+// @Tags admin
+// @Produce  json
+// @Success 200 {object} map[string]string
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /admin/consumer/resume [post]
+func (h *AdminHandler) ResumeConsumerHandler(w http.ResponseWriter, r *http.Request) {
+	h.consumer.Resume()
+	utils.JSONResponse(w, http.StatusOK, map[string]string{
+		"message": "Consumer resumed",
+	})
+}
+
+// SeekConsumerHandler godoc
+// @Summary Seek consumer group offsets
+// @Description Move committed offsets of the consumer group to an offset or to the first offset at a timestamp. Other instances of the group must be stopped.
+// @Tags admin
+// @Accept  json
+// @Produce  json
+// @Param request body kafka.SeekRequest true "Seek target"
+// @Success 200 {array} kafka.PartitionOffset
+// @Failure 400 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /admin/consumer/seek [post]
+func (h *AdminHandler) SeekConsumerHandler(w http.ResponseWriter, r *http.Request) {
+	var req kafka.SeekRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.JSONResponse(w, http.StatusBadRequest, map[string]string{
+			"error": "Invalid request body: " + err.Error(),
+		})
+		return
+	}
+	offsets, err := h.consumer.Seek(r.Context(), req)
+	if err != nil {
+		adminError(w, err)
+		return
+	}
+	utils.JSONResponse(w, http.StatusOK, offsets)
+}
+
+// ReplayConsumerHandler godoc
+// @Summary Replay an offset range
+// @Description Re-process messages of one partition in [from, to] without touching group offsets. In dry-run mode messages are only decoded and validated.
+// @Tags admin
+// @Accept  json
+// @Produce  json
+// @Param request body kafka.ReplayRequest true "Replay range"
+// @Success 200 {object} kafka.ReplayResult
+// @Failure 400 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /admin/consumer/replay [post]
+func (h *AdminHandler) ReplayConsumerHandler(w http.ResponseWriter, r *http.Request) {
+	var req kafka.ReplayRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.JSONResponse(w, http.StatusBadRequest, map[string]string{
+			"error": "Invalid request body: " + err.Error(),
+		})
+		return
+	}
+	result, err := h.consumer.Replay(r.Context(), req)
+	if err != nil {
+		adminError(w, err)
+		return
+	}
+	utils.JSONResponse(w, http.StatusOK, result)
+}
+
+// ConsumerPositionHandler godoc
+// @Summary Consumer position and lag
+// @Description Committed offsets, partition end offsets and lag of the consumer group
+// @Tags admin
+// @Produce  json
+// @Success 200 {object} kafka.Position
+// @Failure 500 {object} map[string]string
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /admin/consumer/position [get]
+func (h *AdminHandler) ConsumerPositionHandler(w http.ResponseWriter, r *http.Request) {
+	pos, err := h.consumer.Position(r.Context())
+	if err != nil {
+		adminError(w, err)
+		return
+	}
+	utils.JSONResponse(w, http.StatusOK, pos)
+}
+
+// DryRunReportHandler godoc
+// @Summary Dry-run validation report
+// @Description Pass/fail counts by validation rule with sample failing offsets. Available only when the consumer runs with DRY_RUN=true.
+// @Tags admin
+// @Produce  json
+// @Produce  plain
+// @Param format query string false "Response format: json (default) or text"
+// @Success 200 {object} kafka.ValidationSummary
+// @Failure 404 {object} map[string]string
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /admin/dry-run/report [get]
+func (h *AdminHandler) DryRunReportHandler(w http.ResponseWriter, r *http.Request) {
+	summary := h.consumer.ValidationReport()
+	if summary == nil {
+		utils.JSONResponse(w, http.StatusNotFound, map[string]string{
+			"error": "Consumer is not running in dry-run mode",
+		})
+		return
+	}
+	if r.URL.Query().Get("format") == "text" {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		summary.WriteText(w)
+		return
+	}
+	utils.JSONResponse(w, http.StatusOK, summary)
+}
+
+// CacheStatsHandler godoc
+// @Summary Order cache statistics
+// @Description Number of cached orders, capacity, indexed track numbers and hit/miss counters since start
+// @Tags admin
+// @Produce  json
+// @Success 200 {object} cache.Stats
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /admin/cache/stats [get]
+func (h *AdminHandler) CacheStatsHandler(w http.ResponseWriter, r *http.Request) {
+	utils.JSONResponse(w, http.StatusOK, h.cacheStats())
+}
+
+// ReadOnly отклоняет запросы с 409, если readOnly: в режиме dry-run сервис
+// ничего не пишет в БД. Ставится на маршруты, которые меняют данные.
+func ReadOnly(readOnly bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if !readOnly {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			utils.JSONResponse(w, http.StatusConflict, map[string]string{
+				"error": "The service runs in dry-run mode and does not modify data",
+			})
+		})
+	}
+}
+
+func adminError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, kafka.ErrInvalidAdminRequest):
+		status = http.StatusBadRequest
+	case errors.Is(err, kafka.ErrDryRun):
+		status = http.StatusConflict
+	}
+	utils.JSONResponse(w, status, map[string]string{
+		"error": err.Error(),
+	})
+}
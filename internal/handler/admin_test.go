@@ -0,0 +1,181 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"orderkeeper/internal/cache"
+	"orderkeeper/internal/kafka"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeConsumerAdmin struct {
+	report *kafka.ValidationSummary
+	paused bool
+	replay kafka.ReplayRequest
+	err    error
+}
+
+func (f *fakeConsumerAdmin) Pause()  { f.paused = true }
+func (f *fakeConsumerAdmin) Resume() { f.paused = false }
+
+func (f *fakeConsumerAdmin) Seek(_ context.Context, req kafka.SeekRequest) ([]kafka.PartitionOffset, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return []kafka.PartitionOffset{{Topic: req.Topic, Partition: 0, Offset: *req.Offset}}, nil
+}
+
+func (f *fakeConsumerAdmin) Replay(_ context.Context, req kafka.ReplayRequest) (kafka.ReplayResult, error) {
+	f.replay = req
+	return kafka.ReplayResult{Topic: req.Topic, Processed: 3, Succeeded: 3, DryRun: req.DryRun}, f.err
+}
+
+func (f *fakeConsumerAdmin) Position(context.Context) (kafka.Position, error) {
+	return kafka.Position{GroupID: "group", Paused: f.paused, TotalLag: 42}, f.err
+}
+
+func (f *fakeConsumerAdmin) ValidationReport() *kafka.ValidationSummary {
+	return f.report
+}
+
+func TestAdminHandler_Reload(t *testing.T) {
+	router := chi.NewRouter()
+	reload := func() (any, error) { return map[string]int{"cache_capacity": 10}, nil }
+	router.Post("/admin/reload", func(w http.ResponseWriter, r *http.Request) {
+		NewAdminHandler(reload, nil, nil).ReloadHandler(w, r)
+	})
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/admin/reload", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	reload = func() (any, error) { return nil, ErrNoConfigFile }
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/admin/reload", nil))
+	assert.Equal(t, http.StatusConflict, rr.Code)
+
+	reload = func() (any, error) { return nil, errors.New("CACHE_CAPACITY must be a positive integer") }
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/admin/reload", nil))
+	assert.Equal(t, http.StatusInternalServerError, rr.Code)
+}
+
+func TestAdminHandler_Consumer(t *testing.T) {
+	consumer := &fakeConsumerAdmin{}
+	adminHandler := NewAdminHandler(func() (any, error) { return nil, nil }, consumer, func() cache.Stats {
+		return cache.Stats{Count: 3, Capacity: 1000}
+	})
+
+	router := chi.NewRouter()
+	router.Post("/admin/consumer/pause", adminHandler.PauseConsumerHandler)
+	router.Post("/admin/consumer/resume", adminHandler.ResumeConsumerHandler)
+	router.Post("/admin/consumer/seek", adminHandler.SeekConsumerHandler)
+	router.Post("/admin/consumer/replay", adminHandler.ReplayConsumerHandler)
+	router.Get("/admin/consumer/position", adminHandler.ConsumerPositionHandler)
+	router.Get("/admin/dry-run/report", adminHandler.DryRunReportHandler)
+	router.Get("/admin/cache/stats", adminHandler.CacheStatsHandler)
+
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	t.Run("pause and position", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, do(http.MethodPost, "/admin/consumer/pause", "").Code)
+
+		rr := do(http.MethodGet, "/admin/consumer/position", "")
+		assert.Equal(t, http.StatusOK, rr.Code)
+		var pos kafka.Position
+		assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &pos))
+		assert.True(t, pos.Paused)
+		assert.Equal(t, int64(42), pos.TotalLag)
+
+		assert.Equal(t, http.StatusOK, do(http.MethodPost, "/admin/consumer/resume", "").Code)
+		assert.False(t, consumer.paused)
+	})
+
+	t.Run("replay dry run", func(t *testing.T) {
+		rr := do(http.MethodPost, "/admin/consumer/replay", `{"topic":"orders","partition":1,"from":10,"to":12,"dry_run":true}`)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, kafka.ReplayRequest{Topic: "orders", Partition: 1, From: 10, To: 12, DryRun: true}, consumer.replay)
+	})
+
+	t.Run("seek", func(t *testing.T) {
+		rr := do(http.MethodPost, "/admin/consumer/seek", `{"topic":"orders","offset":5}`)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.JSONEq(t, `[{"topic":"orders","partition":0,"offset":5}]`, rr.Body.String())
+	})
+
+	t.Run("invalid request", func(t *testing.T) {
+		consumer.err = fmt.Errorf("%w: bad range", kafka.ErrInvalidAdminRequest)
+		defer func() { consumer.err = nil }()
+
+		assert.Equal(t, http.StatusBadRequest, do(http.MethodPost, "/admin/consumer/seek", `{"topic":"orders","offset":5}`).Code)
+		assert.Equal(t, http.StatusBadRequest, do(http.MethodPost, "/admin/consumer/seek", `{`).Code)
+	})
+
+	t.Run("read-only routes", func(t *testing.T) {
+		ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+		do := func(readOnly bool) int {
+			rr := httptest.NewRecorder()
+			ReadOnly(readOnly)(ok).ServeHTTP(rr, httptest.NewRequest(http.MethodDelete, "/order/1", nil))
+			return rr.Code
+		}
+
+		assert.Equal(t, http.StatusOK, do(false))
+		assert.Equal(t, http.StatusConflict, do(true))
+	})
+
+	t.Run("dry-run consumer", func(t *testing.T) {
+		consumer.err = fmt.Errorf("%w: seek would commit offsets", kafka.ErrDryRun)
+		defer func() { consumer.err = nil }()
+
+		assert.Equal(t, http.StatusConflict, do(http.MethodPost, "/admin/consumer/seek", `{"topic":"orders","offset":5}`).Code)
+		assert.Equal(t, http.StatusConflict, do(http.MethodPost, "/admin/consumer/replay", `{"topic":"orders","from":1,"to":2}`).Code)
+	})
+
+	t.Run("dry-run report", func(t *testing.T) {
+		assert.Equal(t, http.StatusNotFound, do(http.MethodGet, "/admin/dry-run/report", "").Code)
+
+		consumer.report = &kafka.ValidationSummary{Total: 2, Passed: 1, Failed: 1, Rules: []kafka.RuleSummary{
+			{Rule: "decode", Failures: 1, SampleOffsets: []kafka.MessageRef{{Topic: "orders", Offset: 7}}},
+		}}
+		defer func() { consumer.report = nil }()
+
+		rr := do(http.MethodGet, "/admin/dry-run/report", "")
+		assert.Equal(t, http.StatusOK, rr.Code)
+		var summary kafka.ValidationSummary
+		assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &summary))
+		assert.Equal(t, 1, summary.Failed)
+
+		rr = do(http.MethodGet, "/admin/dry-run/report?format=text", "")
+		assert.Contains(t, rr.Body.String(), "orders/0@7")
+	})
+
+	t.Run("broker error", func(t *testing.T) {
+		consumer.err = errors.New("broker unavailable")
+		defer func() { consumer.err = nil }()
+
+		assert.Equal(t, http.StatusInternalServerError, do(http.MethodGet, "/admin/consumer/position", "").Code)
+	})
+
+	t.Run("cache stats", func(t *testing.T) {
+		rr := do(http.MethodGet, "/admin/cache/stats", "")
+		assert.Equal(t, http.StatusOK, rr.Code)
+		var stats cache.Stats
+		assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &stats))
+		assert.Equal(t, 3, stats.Count)
+	})
+}
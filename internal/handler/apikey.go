@@ -0,0 +1,121 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"orderkeeper/internal/service"
+	"orderkeeper/pkg/utils"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type APIKeyHandler struct {
+	apiKeyService service.APIKeyService
+}
+
+func NewAPIKeyHandler(apiKeyService service.APIKeyService) *APIKeyHandler {
+	return &APIKeyHandler{apiKeyService: apiKeyService}
+}
+
+type IssueAPIKeyRequest struct {
+	Name string `json:"name" example:"billing-service"`
+	// Scopes — области ключа; без них ключ получает orders:read.
+	Scopes []string `json:"scopes,omitempty" example:"orders:read"`
+	// Tenant — площадка ключа или "*" для всех площадок.
+	Tenant string `json:"tenant" example:"WBIL"`
+}
+
+// IssueAPIKeyHandler godoc
+// @Summary Issue an API key
+// @Description Creates a key and returns it once; only its hash is stored
+// @Tags admin
+// @Accept  json
+// @Produce  json
+// @Param request body IssueAPIKeyRequest true "Key name, scopes and tenant"
+// @Success 201 {object} models.IssuedAPIKey
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /admin/api-keys [post]
+func (h *APIKeyHandler) IssueAPIKeyHandler(w http.ResponseWriter, r *http.Request) {
+	var req IssueAPIKeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.JSONResponse(w, http.StatusBadRequest, map[string]string{
+			"error": "Invalid request body: " + err.Error(),
+		})
+		return
+	}
+	issued, err := h.apiKeyService.IssueAPIKey(req.Name, req.Tenant, req.Scopes)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, service.ErrAPIKeyName) || errors.Is(err, service.ErrAPIKeyScopes) || errors.Is(err, service.ErrAPIKeyTenant) {
+			status = http.StatusBadRequest
+		}
+		utils.JSONResponse(w, status, map[string]string{
+			"error": err.Error(),
+		})
+		return
+	}
+	utils.JSONResponse(w, http.StatusCreated, issued)
+}
+
+// ListAPIKeysHandler godoc
+// @Summary List API keys
+// @Description Issued keys without their secrets, including revoked ones
+// @Tags admin
+// @Produce  json
+// @Success 200 {array} models.APIKey
+// @Failure 500 {object} map[string]string
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /admin/api-keys [get]
+func (h *APIKeyHandler) ListAPIKeysHandler(w http.ResponseWriter, r *http.Request) {
+	keys, err := h.apiKeyService.ListAPIKeys()
+	if err != nil {
+		utils.JSONResponse(w, http.StatusInternalServerError, map[string]string{
+			"error": err.Error(),
+		})
+		return
+	}
+	utils.JSONResponse(w, http.StatusOK, keys)
+}
+
+// RevokeAPIKeyHandler godoc
+// @Summary Revoke an API key
+// @Tags admin
+// @Produce  json
+// @Param id path int true "Key ID"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /admin/api-keys/{id} [delete]
+func (h *APIKeyHandler) RevokeAPIKeyHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		utils.JSONResponse(w, http.StatusBadRequest, map[string]string{
+			"error": "Key ID must be a positive integer",
+		})
+		return
+	}
+	if err := h.apiKeyService.RevokeAPIKey(uint(id)); err != nil {
+		if errors.Is(err, service.ErrAPIKeyNotFound) {
+			utils.JSONResponse(w, http.StatusNotFound, map[string]string{
+				"error": err.Error(),
+			})
+		} else {
+			utils.JSONResponse(w, http.StatusInternalServerError, map[string]string{
+				"error": err.Error(),
+			})
+		}
+		return
+	}
+	utils.JSONResponse(w, http.StatusOK, map[string]string{
+		"message": "API key revoked",
+	})
+}
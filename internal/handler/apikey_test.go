@@ -0,0 +1,84 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"orderkeeper/internal/models"
+	"orderkeeper/internal/service"
+	"orderkeeper/internal/service/mocks"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestAPIKeyHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mocks.NewMockAPIKeyService(ctrl)
+	apiKeyHandler := NewAPIKeyHandler(mockService)
+
+	router := chi.NewRouter()
+	router.Post("/admin/api-keys", apiKeyHandler.IssueAPIKeyHandler)
+	router.Delete("/admin/api-keys/{id}", apiKeyHandler.RevokeAPIKeyHandler)
+
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(method, path, strings.NewReader(body)))
+		return rr
+	}
+
+	t.Run("issue", func(t *testing.T) {
+		mockService.EXPECT().IssueAPIKey("billing", "WBIL", []string{"orders:read"}).Return(models.IssuedAPIKey{
+			APIKey: models.APIKey{ID: 1, Name: "billing", Prefix: "okk_abcdefgh", Hash: "secret-hash"},
+			Key:    "okk_abcdefghijk",
+		}, nil)
+
+		rr := do(http.MethodPost, "/admin/api-keys", `{"name":"billing","scopes":["orders:read"],"tenant":"WBIL"}`)
+
+		assert.Equal(t, http.StatusCreated, rr.Code)
+		assert.Contains(t, rr.Body.String(), `"key":"okk_abcdefghijk"`)
+		assert.NotContains(t, rr.Body.String(), "secret-hash")
+	})
+
+	t.Run("issue without name", func(t *testing.T) {
+		mockService.EXPECT().IssueAPIKey("", "", nil).Return(models.IssuedAPIKey{}, service.ErrAPIKeyName)
+
+		assert.Equal(t, http.StatusBadRequest, do(http.MethodPost, "/admin/api-keys", `{}`).Code)
+	})
+
+	t.Run("issue without tenant", func(t *testing.T) {
+		mockService.EXPECT().IssueAPIKey("billing", "", []string{"orders:read"}).Return(models.IssuedAPIKey{}, service.ErrAPIKeyTenant)
+
+		rr := do(http.MethodPost, "/admin/api-keys", `{"name":"billing","scopes":["orders:read"]}`)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("issue with unknown scope", func(t *testing.T) {
+		mockService.EXPECT().IssueAPIKey("billing", "*", []string{"orders:all"}).Return(models.IssuedAPIKey{}, service.ErrAPIKeyScopes)
+
+		rr := do(http.MethodPost, "/admin/api-keys", `{"name":"billing","scopes":["orders:all"],"tenant":"*"}`)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("revoke", func(t *testing.T) {
+		mockService.EXPECT().RevokeAPIKey(uint(3)).Return(nil)
+
+		assert.Equal(t, http.StatusOK, do(http.MethodDelete, "/admin/api-keys/3", "").Code)
+	})
+
+	t.Run("revoke unknown key", func(t *testing.T) {
+		mockService.EXPECT().RevokeAPIKey(uint(4)).Return(service.ErrAPIKeyNotFound)
+
+		assert.Equal(t, http.StatusNotFound, do(http.MethodDelete, "/admin/api-keys/4", "").Code)
+	})
+
+	t.Run("invalid ID", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, do(http.MethodDelete, "/admin/api-keys/abc", "").Code)
+	})
+}
@@ -0,0 +1,131 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"orderkeeper/internal/service"
+	"orderkeeper/pkg/utils"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type CustomerHandler struct {
+	customerService service.CustomerService
+}
+
+func NewCustomerHandler(customerService service.CustomerService) *CustomerHandler {
+	return &CustomerHandler{customerService: customerService}
+}
+
+// pageParams читает параметры пагинации limit и offset из запроса.
+func pageParams(r *http.Request) (limit, offset int, err error) {
+	q := r.URL.Query()
+	if v := q.Get("limit"); v != "" {
+		if limit, err = strconv.Atoi(v); err != nil || limit < 0 {
+			return 0, 0, errors.New("limit must be a non-negative integer")
+		}
+	}
+	if v := q.Get("offset"); v != "" {
+		if offset, err = strconv.Atoi(v); err != nil || offset < 0 {
+			return 0, 0, errors.New("offset must be a non-negative integer")
+		}
+	}
+	return limit, offset, nil
+}
+
+// CustomerOrdersHandler godoc
+// @Summary List customer orders
+// @Description Orders of a customer, newest first. Recipient PII is masked without the pii:read scope.
+// @Tags customers
+// @Produce  json
+// @Param id path string true "Customer ID"
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param offset query int false "Number of orders to skip"
+// @Success 200 {object} models.OrderPage
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /customers/{id}/orders [get]
+func (h *CustomerHandler) CustomerOrdersHandler(w http.ResponseWriter, r *http.Request) {
+	limit, offset, err := pageParams(r)
+	if err != nil {
+		utils.JSONResponse(w, http.StatusBadRequest, map[string]string{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	page, err := h.customerService.ForTenant(tenantOf(r)).GetCustomerOrders(chi.URLParam(r, "id"), limit, offset)
+	if err != nil {
+		utils.JSONResponse(w, http.StatusInternalServerError, map[string]string{
+			"error": err.Error(),
+		})
+		return
+	}
+	if !canReadPII(r) {
+		page.Orders = maskOrders(page.Orders)
+	}
+	utils.JSONResponse(w, http.StatusOK, page)
+}
+
+// CustomerSummaryHandler godoc
+// @Summary Customer summary
+// @Description Order count, spend per currency, first and last order dates and preferred delivery service
+// @Tags customers
+// @Produce  json
+// @Param id path string true "Customer ID"
+// @Success 200 {object} models.CustomerSummary
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /customers/{id}/summary [get]
+func (h *CustomerHandler) CustomerSummaryHandler(w http.ResponseWriter, r *http.Request) {
+	summary, err := h.customerService.ForTenant(tenantOf(r)).GetCustomerSummary(chi.URLParam(r, "id"))
+	if err != nil {
+		if errors.Is(err, service.ErrCustomerNotFound) {
+			utils.JSONResponse(w, http.StatusNotFound, map[string]string{
+				"error": err.Error(),
+			})
+		} else {
+			utils.JSONResponse(w, http.StatusInternalServerError, map[string]string{
+				"error": err.Error(),
+			})
+		}
+		return
+	}
+	utils.JSONResponse(w, http.StatusOK, summary)
+}
+
+// EraseCustomerHandler godoc
+// @Summary Erase customer personal data
+// @Description Replaces recipient name, phone, email and address in all orders of the customer, including deleted ones. Payments and items are kept.
+// @Tags customers
+// @Produce  json
+// @Param id path string true "Customer ID"
+// @Success 200 {object} map[string]int
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /customers/{id}/personal-data [delete]
+func (h *CustomerHandler) EraseCustomerHandler(w http.ResponseWriter, r *http.Request) {
+	erased, err := h.customerService.ForTenant(tenantOf(r)).EraseCustomer(chi.URLParam(r, "id"))
+	if err != nil {
+		if errors.Is(err, service.ErrCustomerNotFound) {
+			utils.JSONResponse(w, http.StatusNotFound, map[string]string{
+				"error": err.Error(),
+			})
+		} else {
+			utils.JSONResponse(w, http.StatusInternalServerError, map[string]string{
+				"error": err.Error(),
+			})
+		}
+		return
+	}
+	utils.JSONResponse(w, http.StatusOK, map[string]int{
+		"orders": erased,
+	})
+}
@@ -0,0 +1,84 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"orderkeeper/internal/models"
+	"orderkeeper/internal/money"
+	"orderkeeper/internal/service"
+	"orderkeeper/internal/service/mocks"
+	"orderkeeper/internal/tenant"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestCustomerHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mocks.NewMockCustomerService(ctrl)
+	mockService.EXPECT().ForTenant(tenant.All).Return(mockService).AnyTimes()
+	customerHandler := NewCustomerHandler(mockService)
+
+	router := chi.NewRouter()
+	router.Get("/customers/{id}/orders", customerHandler.CustomerOrdersHandler)
+	router.Get("/customers/{id}/summary", customerHandler.CustomerSummaryHandler)
+	router.Delete("/customers/{id}/personal-data", customerHandler.EraseCustomerHandler)
+
+	get := func(path string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, path, nil))
+		return rr
+	}
+
+	t.Run("orders page", func(t *testing.T) {
+		mockService.EXPECT().GetCustomerOrders("cust-1", 10, 20).Return(models.OrderPage{
+			Orders: []models.Order{{OrderUID: "uid-1"}}, Total: 21, Limit: 10, Offset: 20,
+		}, nil)
+
+		rr := get("/customers/cust-1/orders?limit=10&offset=20")
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		var page models.OrderPage
+		assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &page))
+		assert.Equal(t, int64(21), page.Total)
+		assert.Equal(t, "uid-1", page.Orders[0].OrderUID)
+	})
+
+	t.Run("invalid limit", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, get("/customers/cust-1/orders?limit=ten").Code)
+	})
+
+	t.Run("summary", func(t *testing.T) {
+		mockService.EXPECT().GetCustomerSummary("cust-1").Return(models.CustomerSummary{
+			CustomerID: "cust-1",
+			OrderCount: 2,
+			TotalSpend: []money.Money{money.New(1817, "USD")},
+		}, nil)
+
+		rr := get("/customers/cust-1/summary")
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Contains(t, rr.Body.String(), `"total_spend":[{"amount":1817,"currency":"USD"}]`)
+	})
+
+	t.Run("summary of unknown customer", func(t *testing.T) {
+		mockService.EXPECT().GetCustomerSummary("nobody").Return(models.CustomerSummary{}, service.ErrCustomerNotFound)
+
+		assert.Equal(t, http.StatusNotFound, get("/customers/nobody/summary").Code)
+	})
+
+	t.Run("erase personal data", func(t *testing.T) {
+		mockService.EXPECT().EraseCustomer("cust-1").Return(3, nil)
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(http.MethodDelete, "/customers/cust-1/personal-data", nil))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.JSONEq(t, `{"orders":3}`, rr.Body.String())
+	})
+}
@@ -4,65 +4,31 @@ package handler
 import (
 	"encoding/json"
 	"errors"
+	"log"
+	"mime"
 	"net/http"
+	"orderkeeper/internal/auth"
+	"orderkeeper/internal/export"
+	"orderkeeper/internal/importer"
 	"orderkeeper/internal/models"
 	"orderkeeper/internal/service"
+	"orderkeeper/internal/tenant"
+	"orderkeeper/internal/validation"
 	"orderkeeper/pkg/utils"
-	"strings"
 
 	"github.com/go-chi/chi/v5"
 )
 
+// MaxImportSize — наибольший размер тела POST /orders/import.
+const MaxImportSize = 256 << 20
+
 type OrderHandler struct {
-	orderService service.OrderService
+	orderService  service.OrderService
+	maxImportSize int64
 }
 
 func NewOrderHandler(orderService service.OrderService) *OrderHandler {
-	return &OrderHandler{orderService: orderService}
-}
-
-func validateOrder(order *models.Order) error {
-	if order.OrderUID == "" {
-		return errors.New("order_uid is required")
-	}
-	if order.TrackNumber == "" {
-		return errors.New("track_number is required")
-	}
-
-	if order.Delivery.Name == "" {
-		return errors.New("delivery name is required")
-	}
-	if order.Delivery.Phone == "" {
-		return errors.New("delivery phone is required")
-	}
-	if !strings.Contains(order.Delivery.Email, "@") {
-		return errors.New("delivery email is invalid")
-	}
-
-	if order.Payment.Transaction == "" {
-		return errors.New("payment transaction is required")
-	}
-	if order.Payment.Amount <= 0 {
-		return errors.New("payment amount must be positive")
-	}
-
-	if len(order.Items) == 0 {
-		return errors.New("order must contain at least one item")
-	}
-
-	for _, item := range order.Items {
-		if item.CHRTID <= 0 {
-			return errors.New("item chrt_id must be positive")
-		}
-		if item.Price <= 0 {
-			return errors.New("item price must be positive")
-		}
-		if item.Name == "" {
-			return errors.New("item name is required")
-		}
-	}
-
-	return nil
+	return &OrderHandler{orderService: orderService, maxImportSize: MaxImportSize}
 }
 
 // CreateOrderHandler godoc
@@ -75,6 +41,8 @@ func validateOrder(order *models.Order) error {
 // @Success 201 {object} map[string]string
 // @Failure 400 {object} map[string]string
 // @Failure 500 {object} map[string]string
+// @Security ApiKeyAuth
+// @Security BearerAuth
 // @Router /order [post]
 func (h *OrderHandler) CreateOrderHandler(w http.ResponseWriter, r *http.Request) {
 	var order models.Order
@@ -85,14 +53,13 @@ func (h *OrderHandler) CreateOrderHandler(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	if err := validateOrder(&order); err != nil {
-		utils.JSONResponse(w, http.StatusBadRequest, map[string]string{
-			"error": "Validation failed: " + err.Error(),
-		})
+	// Заказ проверяет сервис: так же, как заказы из Kafka и импорта.
+	err := h.orderService.ForTenant(tenantOf(r)).CreateOrder(order)
+	var violations validation.Errors
+	if errors.As(err, &violations) {
+		validationFailed(w, violations)
 		return
 	}
-
-	err := h.orderService.CreateOrder(order)
 	if err != nil {
 		utils.JSONResponse(w, http.StatusInternalServerError, map[string]string{
 			"error": err.Error(),
@@ -104,9 +71,45 @@ func (h *OrderHandler) CreateOrderHandler(w http.ResponseWriter, r *http.Request
 	})
 }
 
+func validationFailed(w http.ResponseWriter, violations validation.Errors) {
+	utils.JSONResponse(w, http.StatusBadRequest, map[string]any{
+		"error":      "Validation failed",
+		"violations": violations,
+	})
+}
+
+// tenantOf возвращает площадку, к заказам которой ограничен Principal
+// запроса; tenant.All для auth.AllTenants. Учетные данные без площадки
+// отклоняет auth.Authenticator.
+func tenantOf(r *http.Request) string {
+	principal, _ := auth.FromContext(r.Context())
+	if principal.Tenant == auth.AllTenants {
+		return tenant.All
+	}
+	return principal.Tenant
+}
+
+// canReadPII сообщает, можно ли показать клиенту персональные данные
+// получателя. Без Principal в контексте данные скрываются.
+func canReadPII(r *http.Request) bool {
+	principal, ok := auth.FromContext(r.Context())
+	return ok && principal.HasScope(auth.ScopePIIRead)
+}
+
+// maskOrders возвращает заказы со скрытыми данными получателя, не трогая
+// исходный срез: он может принадлежать кешу.
+func maskOrders(orders []models.Order) []models.Order {
+	masked := make([]models.Order, len(orders))
+	for i, order := range orders {
+		order.Delivery = order.Delivery.Masked()
+		masked[i] = order
+	}
+	return masked
+}
+
 // GetOrderByIDHandler godoc
 // @Summary Get order by ID
-// @Description Get order details by order_uid
+// @Description Get order details by order_uid. Recipient name, phone, email and address are masked without the pii:read scope.
 // @Tags orders
 // @Accept  json
 // @Produce  json
@@ -115,6 +118,8 @@ func (h *OrderHandler) CreateOrderHandler(w http.ResponseWriter, r *http.Request
 // @Failure 400 {object} map[string]string
 // @Failure 404 {object} map[string]string
 // @Failure 500 {object} map[string]string
+// @Security ApiKeyAuth
+// @Security BearerAuth
 // @Router /order/{id} [get]
 func (h *OrderHandler) GetOrderByIDHandler(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
@@ -125,7 +130,7 @@ func (h *OrderHandler) GetOrderByIDHandler(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	order, err := h.orderService.GetOrderByID(id)
+	order, err := h.orderService.ForTenant(tenantOf(r)).GetOrderByID(id)
 	if err != nil {
 		if errors.Is(err, service.ErrOrderNotFound) {
 			utils.JSONResponse(w, http.StatusNotFound, map[string]string{
@@ -139,5 +144,246 @@ func (h *OrderHandler) GetOrderByIDHandler(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
+	if !canReadPII(r) {
+		order.Delivery = order.Delivery.Masked()
+	}
 	utils.JSONResponse(w, http.StatusOK, order)
 }
+
+// DeleteOrderHandler godoc
+// @Summary Delete order
+// @Description Soft-deletes an order: it disappears from the API, search and reports but stays in the database
+// @Tags orders
+// @Produce  json
+// @Param id path string true "Order ID"
+// @Success 200 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /order/{id} [delete]
+func (h *OrderHandler) DeleteOrderHandler(w http.ResponseWriter, r *http.Request) {
+	err := h.orderService.ForTenant(tenantOf(r)).DeleteOrder(chi.URLParam(r, "id"))
+	if err != nil {
+		if errors.Is(err, service.ErrOrderNotFound) {
+			utils.JSONResponse(w, http.StatusNotFound, map[string]string{
+				"error": err.Error(),
+			})
+		} else {
+			utils.JSONResponse(w, http.StatusInternalServerError, map[string]string{
+				"error": err.Error(),
+			})
+		}
+		return
+	}
+	utils.JSONResponse(w, http.StatusOK, map[string]string{
+		"message": "Order deleted",
+	})
+}
+
+// TrackHandler godoc
+// @Summary Find order by track number
+// @Description Resolves a track number of an order or of its items to the order, with items grouped by shipment. Recipient PII is masked without the pii:read scope.
+// @Tags orders
+// @Produce  json
+// @Param track_number path string true "Track number"
+// @Success 200 {object} models.Tracking
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /track/{track_number} [get]
+func (h *OrderHandler) TrackHandler(w http.ResponseWriter, r *http.Request) {
+	track := chi.URLParam(r, "track_number")
+
+	order, err := h.orderService.ForTenant(tenantOf(r)).GetOrderByTrack(track)
+	if err != nil {
+		if errors.Is(err, service.ErrOrderNotFound) {
+			utils.JSONResponse(w, http.StatusNotFound, map[string]string{
+				"error": err.Error(),
+			})
+		} else {
+			utils.JSONResponse(w, http.StatusInternalServerError, map[string]string{
+				"error": err.Error(),
+			})
+		}
+		return
+	}
+
+	if !canReadPII(r) {
+		order.Delivery = order.Delivery.Masked()
+	}
+	utils.JSONResponse(w, http.StatusOK, models.Tracking{
+		TrackNumber: track,
+		Order:       order,
+		Shipments:   order.Shipments(),
+	})
+}
+
+// ExportCursorTrailer — HTTP-трейлер с order_uid последнего выгруженного
+// заказа; его можно передать в cursor, чтобы продолжить прерванную выгрузку.
+const ExportCursorTrailer = "X-Export-Cursor"
+
+// ExportCompleteTrailer — HTTP-трейлер со значением "true", если выгрузка
+// дошла до конца, и "false", если она оборвалась; причина сбоя приходит в
+// ExportErrorTrailer.
+const (
+	ExportCompleteTrailer = "X-Export-Complete"
+	ExportErrorTrailer    = "X-Export-Error"
+)
+
+// ExportOrdersHandler godoc
+// @Summary Export orders
+// @Description Streams orders as NDJSON (one order per line), flattened CSV or Parquet (one row per item), ordered by order_uid. The last exported order_uid is sent in the X-Export-Cursor trailer; pass it as cursor to resume. X-Export-Complete trailer is true only if the export finished; otherwise X-Export-Error holds the reason. Recipient PII is masked without the pii:read scope.
+// @Tags orders
+// @Produce  application/x-ndjson
+// @Produce  text/csv
+// @Produce  application/vnd.apache.parquet
+// @Param format query string false "ndjson (default), csv or parquet"
+// @Param cursor query string false "Export orders with order_uid greater than this"
+// @Param from query string false "Created at or after, YYYY-MM-DD or RFC 3339"
+// @Param to query string false "Created before, YYYY-MM-DD or RFC 3339"
+// @Param status query string false "Order status"
+// @Param customer_id query string false "Customer ID"
+// @Param needs_review query bool false "Only orders flagged (true) or not flagged (false) for manual review"
+// @Success 200 {string} string
+// @Failure 400 {object} map[string]string
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /orders/export [get]
+func (h *OrderHandler) ExportOrdersHandler(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	filter := models.OrderFilter{
+		After:      q.Get("cursor"),
+		Status:     q.Get("status"),
+		CustomerID: q.Get("customer_id"),
+	}
+	var err error
+	if filter.From, err = export.ParseFilterTime(q.Get("from")); err == nil {
+		filter.To, err = export.ParseFilterTime(q.Get("to"))
+	}
+	if err != nil {
+		utils.JSONResponse(w, http.StatusBadRequest, map[string]string{
+			"error": "from and to must be YYYY-MM-DD or RFC 3339",
+		})
+		return
+	}
+	if filter.NeedsReview, err = export.ParseFilterBool(q.Get("needs_review")); err != nil {
+		utils.JSONResponse(w, http.StatusBadRequest, map[string]string{
+			"error": "needs_review must be true or false",
+		})
+		return
+	}
+
+	format := q.Get("format")
+	if format == "" {
+		format = export.FormatNDJSON
+	}
+	writer, err := export.NewWriter(format, w)
+	if err != nil {
+		utils.JSONResponse(w, http.StatusBadRequest, map[string]string{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", export.ContentType(format))
+	w.Header().Set("Content-Disposition", `attachment; filename="orders.`+format+`"`)
+	w.Header().Set("Trailer", ExportCursorTrailer+", "+ExportCompleteTrailer+", "+ExportErrorTrailer)
+	flusher, _ := w.(http.Flusher)
+
+	cursor := filter.After
+	err = h.orderService.ForTenant(tenantOf(r)).ExportOrders(filter, func(orders []models.Order) error {
+		if err := r.Context().Err(); err != nil {
+			return err
+		}
+		if !canReadPII(r) {
+			orders = maskOrders(orders)
+		}
+		if err := writer.WriteBatch(orders); err != nil {
+			return err
+		}
+		cursor = orders[len(orders)-1].OrderUID
+		if flusher != nil {
+			flusher.Flush()
+		}
+		return nil
+	})
+	if err == nil {
+		err = writer.Close()
+	}
+	// Заголовки уже отправлены, поэтому о сбое клиент узнает из трейлеров.
+	w.Header().Set(ExportCursorTrailer, cursor)
+	if err != nil {
+		log.Printf("Order export stopped at cursor %q: %v", cursor, err)
+		w.Header().Set(ExportCompleteTrailer, "false")
+		w.Header().Set(ExportErrorTrailer, "export failed")
+		return
+	}
+	w.Header().Set(ExportCompleteTrailer, "true")
+}
+
+// ImportOrdersHandler godoc
+// @Summary Import orders
+// @Description Imports orders from an NDJSON (models.Order per line) or flat CSV (as produced by /orders/export) body of up to 256 MiB. Each order is validated and saved like a Kafka message; orders that fail, including NDJSON lines over 1 MiB, are skipped and listed in rejects.
+// @Tags orders
+// @Accept  application/x-ndjson
+// @Accept  text/csv
+// @Produce  json
+// @Param format query string false "ndjson or csv; defaults to the Content-Type of the body"
+// @Success 200 {object} importer.Result
+// @Failure 400 {object} map[string]string
+// @Failure 413 {object} importer.Result
+// @Failure 500 {object} importer.Result
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /orders/import [post]
+func (h *OrderHandler) ImportOrdersHandler(w http.ResponseWriter, r *http.Request) {
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = importFormat(r.Header.Get("Content-Type"))
+	}
+	body := http.MaxBytesReader(w, r.Body, h.maxImportSize)
+
+	reader, err := importer.NewReader(format, body)
+	if err != nil {
+		utils.JSONResponse(w, http.StatusBadRequest, map[string]string{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	result := importer.Result{Rejects: []importer.Reject{}}
+	progress, err := importer.Import(r.Context(), h.orderService.ForTenant(tenantOf(r)), reader, importer.Options{
+		OnProgress: func(p importer.Progress) {
+			log.Printf("Order import: %d read, %d imported, %d skipped", p.Read, p.Imported, p.Skipped)
+		},
+		OnReject: func(reject importer.Reject) {
+			if len(result.Rejects) == importer.MaxResultRejects {
+				result.RejectsTruncated = true
+				return
+			}
+			result.Rejects = append(result.Rejects, reject)
+		},
+	})
+	result.Progress = progress
+	if err != nil {
+		log.Printf("Order import stopped after %d records: %v", progress.Read, err)
+		status := http.StatusInternalServerError
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			status = http.StatusRequestEntityTooLarge
+		}
+		utils.JSONResponse(w, status, result)
+		return
+	}
+	utils.JSONResponse(w, http.StatusOK, result)
+}
+
+func importFormat(contentType string) string {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	if mediaType == "text/csv" {
+		return export.FormatCSV
+	}
+	return export.FormatNDJSON
+}
@@ -5,9 +5,14 @@ import (
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"orderkeeper/internal/auth"
+	"orderkeeper/internal/importer"
 	"orderkeeper/internal/models"
 	"orderkeeper/internal/service"
 	"orderkeeper/internal/service/mocks"
+	"orderkeeper/internal/tenant"
+	"orderkeeper/internal/validation"
+	"strings"
 	"testing"
 
 	"github.com/go-chi/chi/v5"
@@ -20,6 +25,7 @@ func TestOrderHandler_GetOrderByIDHandler(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockService := mocks.NewMockOrderService(ctrl)
+	mockService.EXPECT().ForTenant(tenant.All).Return(mockService).AnyTimes()
 	orderHandler := NewOrderHandler(mockService)
 
 	router := chi.NewRouter()
@@ -43,6 +49,41 @@ func TestOrderHandler_GetOrderByIDHandler(t *testing.T) {
 		assert.Equal(t, testOrder.OrderUID, returnedOrder.OrderUID)
 	})
 
+	t.Run("PII by scope", func(t *testing.T) {
+		testOrder := models.Order{OrderUID: "test-pii", Delivery: models.Delivery{
+			Name: "Test Testov", Phone: "+9720000000", Address: "Ploshad Mira 15", Email: "test@gmail.com",
+		}}
+		get := func(scopes ...string) models.Delivery {
+			mockService.EXPECT().GetOrderByID("test-pii").Return(testOrder, nil)
+			req := httptest.NewRequest(http.MethodGet, "/order/test-pii", nil)
+			req = req.WithContext(auth.NewContext(req.Context(), auth.Principal{Subject: "support", Scopes: scopes}))
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+			assert.Equal(t, http.StatusOK, rr.Code)
+			var returnedOrder models.Order
+			assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &returnedOrder))
+			return returnedOrder.Delivery
+		}
+
+		assert.Equal(t, models.Delivery{
+			Name: "T*** T*****", Phone: "+******0000", Address: "***", Email: "t***@gmail.com",
+		}, get(auth.ScopeOrdersAdmin))
+		assert.Equal(t, testOrder.Delivery, get(auth.ScopeOrdersRead, auth.ScopePIIRead))
+	})
+
+	t.Run("tenant-bound caller", func(t *testing.T) {
+		tenantService := mocks.NewMockOrderService(ctrl)
+		mockService.EXPECT().ForTenant("OZON").Return(tenantService)
+		tenantService.EXPECT().GetOrderByID("test-ok-123").Return(models.Order{}, service.ErrOrderNotFound)
+
+		req := httptest.NewRequest(http.MethodGet, "/order/test-ok-123", nil)
+		req = req.WithContext(auth.NewContext(req.Context(), auth.Principal{Subject: "ozon", Tenant: "OZON"}))
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+
 	t.Run("not found 404", func(t *testing.T) {
 		mockService.EXPECT().GetOrderByID("test-404").Return(models.Order{}, service.ErrOrderNotFound)
 
@@ -65,3 +106,216 @@ func TestOrderHandler_GetOrderByIDHandler(t *testing.T) {
 		assert.Equal(t, http.StatusInternalServerError, rr.Code)
 	})
 }
+
+func TestOrderHandler_CreateOrderHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mocks.NewMockOrderService(ctrl)
+	mockService.EXPECT().ForTenant(tenant.All).Return(mockService).AnyTimes()
+	orderHandler := NewOrderHandler(mockService)
+
+	router := chi.NewRouter()
+	router.Post("/order", orderHandler.CreateOrderHandler)
+
+	t.Run("validation errors 400", func(t *testing.T) {
+		mockService.EXPECT().CreateOrder(gomock.Cond(func(o models.Order) bool {
+			return o.Delivery.Email == "not-an-email"
+		})).Return(validation.Errors{
+			{Field: "delivery.email", Rule: validation.RuleEmail, Message: `must be a valid email address, got "not-an-email"`},
+			{Field: "items", Rule: validation.RuleRequired, Message: "order must contain at least one item"},
+		})
+		body := `{"order_uid":"uid-1","delivery":{"email":"not-an-email"},"payment":{"currency":"usd"}}`
+		req := httptest.NewRequest(http.MethodPost, "/order", strings.NewReader(body))
+		rr := httptest.NewRecorder()
+
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		var resp struct {
+			Violations []validation.FieldError `json:"violations"`
+		}
+		assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+		assert.Contains(t, resp.Violations, validation.FieldError{
+			Field: "delivery.email", Rule: validation.RuleEmail, Message: `must be a valid email address, got "not-an-email"`,
+		})
+		assert.Contains(t, resp.Violations, validation.FieldError{
+			Field: "items", Rule: validation.RuleRequired, Message: "order must contain at least one item",
+		})
+	})
+}
+
+func TestOrderHandler_TrackHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mocks.NewMockOrderService(ctrl)
+	mockService.EXPECT().ForTenant(tenant.All).Return(mockService).AnyTimes()
+	orderHandler := NewOrderHandler(mockService)
+
+	router := chi.NewRouter()
+	router.Get("/track/{track_number}", orderHandler.TrackHandler)
+
+	t.Run("items grouped by shipment", func(t *testing.T) {
+		mockService.EXPECT().GetOrderByTrack("TRACK-2").Return(models.Order{
+			OrderUID:    "uid-1",
+			TrackNumber: "TRACK-1",
+			Items: []models.Item{
+				{CHRTID: 1, TrackNumber: "TRACK-1"},
+				{CHRTID: 2, TrackNumber: "TRACK-2"},
+				{CHRTID: 3},
+			},
+		}, nil)
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/track/TRACK-2", nil))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		var tracking models.Tracking
+		assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &tracking))
+		assert.Equal(t, "uid-1", tracking.Order.OrderUID)
+		assert.Len(t, tracking.Shipments, 2)
+		assert.Equal(t, "TRACK-1", tracking.Shipments[0].TrackNumber)
+		assert.Len(t, tracking.Shipments[0].Items, 2)
+		assert.Equal(t, 2, tracking.Shipments[1].Items[0].CHRTID)
+	})
+
+	t.Run("unknown track 404", func(t *testing.T) {
+		mockService.EXPECT().GetOrderByTrack("NOPE").Return(models.Order{}, service.ErrOrderNotFound)
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/track/NOPE", nil))
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+}
+
+func TestOrderHandler_ExportOrdersHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mocks.NewMockOrderService(ctrl)
+	mockService.EXPECT().ForTenant(tenant.All).Return(mockService).AnyTimes()
+	orderHandler := NewOrderHandler(mockService)
+
+	router := chi.NewRouter()
+	router.Get("/orders/export", orderHandler.ExportOrdersHandler)
+
+	t.Run("streams batches and reports cursor", func(t *testing.T) {
+		filter := models.OrderFilter{After: "uid-0", Status: models.OrderStatusPaid}
+		mockService.EXPECT().ExportOrders(filter, gomock.Any()).DoAndReturn(
+			func(_ models.OrderFilter, fn func([]models.Order) error) error {
+				if err := fn([]models.Order{{OrderUID: "uid-1"}, {OrderUID: "uid-2"}}); err != nil {
+					return err
+				}
+				return fn([]models.Order{{OrderUID: "uid-3"}})
+			})
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/orders/export?cursor=uid-0&status=paid", nil))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "application/x-ndjson", rr.Header().Get("Content-Type"))
+		assert.Equal(t, 3, strings.Count(rr.Body.String(), "\n"))
+		assert.Equal(t, "uid-3", rr.Result().Trailer.Get(ExportCursorTrailer))
+		assert.Equal(t, "true", rr.Result().Trailer.Get(ExportCompleteTrailer))
+	})
+
+	t.Run("filters by review flag", func(t *testing.T) {
+		needsReview := true
+		filter := models.OrderFilter{NeedsReview: &needsReview}
+		mockService.EXPECT().ExportOrders(filter, gomock.Any()).Return(nil)
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/orders/export?needs_review=true", nil))
+		assert.Equal(t, http.StatusOK, rr.Code)
+
+		rr = httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/orders/export?needs_review=maybe", nil))
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("reports failure mid-stream", func(t *testing.T) {
+		mockService.EXPECT().ExportOrders(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ models.OrderFilter, fn func([]models.Order) error) error {
+				if err := fn([]models.Order{{OrderUID: "uid-1"}}); err != nil {
+					return err
+				}
+				return errors.New("connection reset")
+			})
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/orders/export", nil))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, 1, strings.Count(rr.Body.String(), "\n"))
+		trailer := rr.Result().Trailer
+		assert.Equal(t, "uid-1", trailer.Get(ExportCursorTrailer))
+		assert.Equal(t, "false", trailer.Get(ExportCompleteTrailer))
+		assert.Equal(t, "export failed", trailer.Get(ExportErrorTrailer))
+	})
+
+	t.Run("unknown format", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/orders/export?format=xml", nil))
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+}
+
+func TestOrderHandler_ImportOrdersHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mocks.NewMockOrderService(ctrl)
+	mockService.EXPECT().ForTenant(tenant.All).Return(mockService).AnyTimes()
+	orderHandler := NewOrderHandler(mockService)
+
+	router := chi.NewRouter()
+	router.Post("/orders/import", orderHandler.ImportOrdersHandler)
+
+	t.Run("imports and lists rejects", func(t *testing.T) {
+		gomock.InOrder(
+			mockService.EXPECT().CreateOrder(gomock.Any()).Return(nil),
+			mockService.EXPECT().CreateOrder(gomock.Any()).Return(validation.Errors{
+				{Field: "delivery.email", Rule: validation.RuleEmail, Message: "must be a valid email address"},
+			}),
+		)
+		body := "{\"order_uid\":\"uid-1\"}\n{\"order_uid\":\"uid-2\"}\nnot json\n"
+
+		rr := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/orders/import", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/x-ndjson")
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		var result importer.Result
+		assert.NoError(t, json.NewDecoder(rr.Body).Decode(&result))
+		assert.Equal(t, importer.Progress{Read: 3, Imported: 1, Skipped: 2}, result.Progress)
+		if assert.Len(t, result.Rejects, 2) {
+			assert.Equal(t, "uid-2", result.Rejects[0].OrderUID)
+			assert.Len(t, result.Rejects[0].Violations, 1)
+			assert.Equal(t, 3, result.Rejects[1].Line)
+		}
+	})
+
+	t.Run("body too large", func(t *testing.T) {
+		orderHandler.maxImportSize = 10
+		defer func() { orderHandler.maxImportSize = MaxImportSize }()
+
+		rr := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/orders/import", strings.NewReader("{\"order_uid\":\"uid-1\"}\n"))
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusRequestEntityTooLarge, rr.Code)
+	})
+
+	t.Run("bad CSV header", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/orders/import", strings.NewReader("uid,colour\n"))
+		req.Header.Set("Content-Type", "text/csv; charset=utf-8")
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+}
@@ -0,0 +1,108 @@
+package handler
+
+import (
+	"encoding/csv"
+	"errors"
+	"net/http"
+	"orderkeeper/internal/models"
+	"orderkeeper/internal/money"
+	"orderkeeper/internal/service"
+	"orderkeeper/pkg/utils"
+	"strconv"
+	"time"
+)
+
+type ReportHandler struct {
+	reportService service.ReportService
+}
+
+func NewReportHandler(reportService service.ReportService) *ReportHandler {
+	return &ReportHandler{reportService: reportService}
+}
+
+// OrdersReportHandler godoc
+// @Summary Orders and revenue per day
+// @Description Non-cancelled orders and revenue per UTC day, grouped by a dimension and currency. Revenue is in minor currency units.
+// @Tags reports
+// @Produce  json
+// @Produce  text/csv
+// @Param from query string false "First day, YYYY-MM-DD (default: 29 days before to)"
+// @Param to query string false "Last day, YYYY-MM-DD (default: today)"
+// @Param group_by query string false "currency (default), delivery_service, region, provider or brand"
+// @Param format query string false "json (default) or csv"
+// @Success 200 {object} models.Report
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /reports/orders [get]
+func (h *ReportHandler) OrdersReportHandler(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	req := service.ReportRequest{GroupBy: q.Get("group_by")}
+	var err error
+	if req.From, err = parseDay(q.Get("from")); err == nil {
+		req.To, err = parseDay(q.Get("to"))
+	}
+	if err != nil {
+		utils.JSONResponse(w, http.StatusBadRequest, map[string]string{
+			"error": "Dates must be in YYYY-MM-DD format",
+		})
+		return
+	}
+
+	format := q.Get("format")
+	if format != "" && format != "json" && format != "csv" {
+		utils.JSONResponse(w, http.StatusBadRequest, map[string]string{
+			"error": "format must be json or csv",
+		})
+		return
+	}
+
+	report, err := h.reportService.ForTenant(tenantOf(r)).OrdersReport(req)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidReport) {
+			utils.JSONResponse(w, http.StatusBadRequest, map[string]string{
+				"error": err.Error(),
+			})
+		} else {
+			utils.JSONResponse(w, http.StatusInternalServerError, map[string]string{
+				"error": err.Error(),
+			})
+		}
+		return
+	}
+
+	if format == "csv" {
+		writeReportCSV(w, report)
+		return
+	}
+	utils.JSONResponse(w, http.StatusOK, report)
+}
+
+func parseDay(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse("2006-01-02", s)
+}
+
+func writeReportCSV(w http.ResponseWriter, report models.Report) {
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition",
+		`attachment; filename="orders-`+report.GroupBy+`-`+report.From+`-`+report.To+`.csv"`)
+	w.WriteHeader(http.StatusOK)
+
+	cw := csv.NewWriter(w)
+	_ = cw.Write([]string{"day", report.GroupBy, "currency", "orders", "revenue", "revenue_major"})
+	for _, row := range report.Rows {
+		_ = cw.Write([]string{
+			row.Day,
+			row.Group,
+			string(row.Currency),
+			strconv.FormatInt(row.Orders, 10),
+			strconv.FormatInt(int64(row.Revenue), 10),
+			money.New(row.Revenue, row.Currency).Decimal(),
+		})
+	}
+	cw.Flush()
+}
@@ -0,0 +1,77 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"orderkeeper/internal/models"
+	"orderkeeper/internal/service"
+	"orderkeeper/internal/service/mocks"
+	"orderkeeper/internal/tenant"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestReportHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mocks.NewMockReportService(ctrl)
+	mockService.EXPECT().ForTenant(tenant.All).Return(mockService).AnyTimes()
+	reportHandler := NewReportHandler(mockService)
+
+	router := chi.NewRouter()
+	router.Get("/reports/orders", reportHandler.OrdersReportHandler)
+
+	get := func(path string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, path, nil))
+		return rr
+	}
+
+	report := models.Report{
+		From: "2025-03-01", To: "2025-03-02", GroupBy: models.ReportByBrand,
+		Rows: []models.ReportRow{
+			{Day: "2025-03-01", Group: "Vivienne Sabo", Currency: "USD", Orders: 2, Revenue: 634},
+			{Day: "2025-03-02", Group: "Vivienne Sabo", Currency: "JPY", Orders: 1, Revenue: 500},
+		},
+	}
+	req := service.ReportRequest{
+		From:    time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC),
+		To:      time.Date(2025, 3, 2, 0, 0, 0, 0, time.UTC),
+		GroupBy: models.ReportByBrand,
+	}
+
+	t.Run("csv", func(t *testing.T) {
+		mockService.EXPECT().OrdersReport(req).Return(report, nil)
+
+		rr := get("/reports/orders?from=2025-03-01&to=2025-03-02&group_by=brand&format=csv")
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "day,brand,currency,orders,revenue,revenue_major\n"+
+			"2025-03-01,Vivienne Sabo,USD,2,634,6.34\n"+
+			"2025-03-02,Vivienne Sabo,JPY,1,500,500\n", rr.Body.String())
+	})
+
+	t.Run("json", func(t *testing.T) {
+		mockService.EXPECT().OrdersReport(req).Return(report, nil)
+
+		rr := get("/reports/orders?from=2025-03-01&to=2025-03-02&group_by=brand")
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Contains(t, rr.Body.String(), `"revenue":634`)
+	})
+
+	t.Run("bad date", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, get("/reports/orders?from=01.03.2025").Code)
+	})
+
+	t.Run("invalid request", func(t *testing.T) {
+		mockService.EXPECT().OrdersReport(service.ReportRequest{GroupBy: "color"}).Return(models.Report{}, service.ErrInvalidReport)
+
+		assert.Equal(t, http.StatusBadRequest, get("/reports/orders?group_by=color").Code)
+	})
+}
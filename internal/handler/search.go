@@ -0,0 +1,75 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"orderkeeper/internal/models"
+	"orderkeeper/internal/service"
+	"orderkeeper/pkg/utils"
+)
+
+type SearchHandler struct {
+	searchService service.SearchService
+}
+
+func NewSearchHandler(searchService service.SearchService) *SearchHandler {
+	return &SearchHandler{searchService: searchService}
+}
+
+// SearchHandler godoc
+// @Summary Search orders
+// @Description Full-text search over customer name, phone, email, city, address, item names and brands. Words match by prefix; 4+ digits also match any part of the phone number. Without the pii:read scope only city, item names and brands are searched, recipient PII is masked and highlight is empty.
+// @Tags orders
+// @Produce  json
+// @Param q query string true "Search query"
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param offset query int false "Number of hits to skip"
+// @Success 200 {object} models.SearchPage
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /search [get]
+func (h *SearchHandler) SearchHandler(w http.ResponseWriter, r *http.Request) {
+	limit, offset, err := pageParams(r)
+	if err != nil {
+		utils.JSONResponse(w, http.StatusBadRequest, map[string]string{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	searchService := h.searchService.ForTenant(tenantOf(r))
+	if !canReadPII(r) {
+		searchService = searchService.WithoutPII()
+	}
+	page, err := searchService.Search(r.URL.Query().Get("q"), limit, offset)
+	if err != nil {
+		if errors.Is(err, service.ErrEmptyQuery) {
+			utils.JSONResponse(w, http.StatusBadRequest, map[string]string{
+				"error": err.Error(),
+			})
+		} else {
+			utils.JSONResponse(w, http.StatusInternalServerError, map[string]string{
+				"error": err.Error(),
+			})
+		}
+		return
+	}
+	if !canReadPII(r) {
+		page.Hits = maskHits(page.Hits)
+	}
+	utils.JSONResponse(w, http.StatusOK, page)
+}
+
+// maskHits скрывает данные получателя в найденных заказах. Фрагменты
+// совпадений убираются: в них могут попасть имя, телефон или адрес.
+func maskHits(hits []models.SearchHit) []models.SearchHit {
+	masked := make([]models.SearchHit, len(hits))
+	for i, hit := range hits {
+		hit.Order.Delivery = hit.Order.Delivery.Masked()
+		hit.Highlight = ""
+		masked[i] = hit
+	}
+	return masked
+}
@@ -0,0 +1,82 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"orderkeeper/internal/auth"
+	"orderkeeper/internal/models"
+	"orderkeeper/internal/repository/mocks"
+	"orderkeeper/internal/service"
+	"orderkeeper/internal/tenant"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestSearchHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockSearchRepository(ctrl)
+	mockRepo.EXPECT().ForTenant(tenant.All).Return(mockRepo).AnyTimes()
+	searchHandler := NewSearchHandler(service.NewSearchService(mockRepo))
+
+	router := chi.NewRouter()
+	router.Use(auth.Anonymous)
+	router.Get("/search", searchHandler.SearchHandler)
+
+	get := func(path string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, path, nil))
+		return rr
+	}
+
+	t.Run("hits", func(t *testing.T) {
+		mockRepo.EXPECT().SearchOrders("test 0000", service.DefaultPageLimit, 5).Return([]models.SearchHit{{
+			Order:     models.Order{OrderUID: "uid-1"},
+			Rank:      0.6,
+			Highlight: "<mark>Test</mark> Testov | +972<mark>0000</mark>000",
+		}}, int64(6), nil)
+
+		rr := get("/search?q=+test+0000+&offset=5")
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		var page models.SearchPage
+		assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &page))
+		assert.Equal(t, int64(6), page.Total)
+		assert.Equal(t, "uid-1", page.Hits[0].Order.OrderUID)
+		assert.Contains(t, page.Hits[0].Highlight, "<mark>Test</mark>")
+	})
+
+	t.Run("hits without pii:read", func(t *testing.T) {
+		mockRepo.EXPECT().WithoutPII().Return(mockRepo)
+		mockRepo.EXPECT().SearchOrders("test", service.DefaultPageLimit, 0).Return([]models.SearchHit{{
+			Order:     models.Order{OrderUID: "uid-1", Delivery: models.Delivery{Name: "Test Testov"}},
+			Highlight: "<mark>Test</mark> Testov",
+		}}, int64(1), nil)
+
+		rr := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/search?q=test", nil)
+		req = req.WithContext(auth.NewContext(req.Context(), auth.Principal{Scopes: []string{auth.ScopeOrdersRead}}))
+		searchHandler.SearchHandler(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.NotContains(t, rr.Body.String(), "Testov")
+	})
+
+	t.Run("no hits", func(t *testing.T) {
+		mockRepo.EXPECT().SearchOrders("nobody", service.DefaultPageLimit, 0).Return(nil, int64(0), nil)
+
+		rr := get("/search?q=nobody")
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Contains(t, rr.Body.String(), `"hits":[]`)
+	})
+
+	t.Run("empty query", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, get("/search?q=%20").Code)
+	})
+}
@@ -0,0 +1,119 @@
+// Package importer загружает исторические заказы из NDJSON и CSV тем же
+// путем, что и консьюмер Kafka: каждый заказ проходит валидацию и
+// сохраняется через OrderService.CreateOrder.
+package importer
+
+import (
+	"context"
+	"errors"
+	"io"
+	"orderkeeper/internal/metrics"
+	"orderkeeper/internal/models"
+	"orderkeeper/internal/validation"
+)
+
+const DefaultProgressEvery = 500
+
+// Creator сохраняет заказ; его реализует service.OrderService.
+type Creator interface {
+	CreateOrder(order models.Order) error
+}
+
+type Progress struct {
+	Read     int `json:"read"`
+	Imported int `json:"imported"`
+	Skipped  int `json:"skipped"`
+}
+
+// Reject — пропущенная запись и причина отказа.
+type Reject struct {
+	Line       int               `json:"line"`
+	OrderUID   string            `json:"order_uid,omitempty"`
+	Error      string            `json:"error"`
+	Violations validation.Errors `json:"violations,omitempty"`
+	Record     string            `json:"record"`
+}
+
+// Result — итог импорта в ответе POST /orders/import. Отклоненных записей
+// в ответе не больше MaxResultRejects, остальные только посчитаны в Skipped.
+type Result struct {
+	Progress
+	Rejects          []Reject `json:"rejects"`
+	RejectsTruncated bool     `json:"rejects_truncated,omitempty"`
+}
+
+const MaxResultRejects = 1000
+
+type Options struct {
+	// ProgressEvery — через сколько записей вызывается OnProgress. Заказы
+	// сохраняются по одному независимо от него.
+	ProgressEvery int
+	// OnProgress вызывается после каждых ProgressEvery записей и в конце.
+	OnProgress func(Progress)
+	// OnReject вызывается для каждой пропущенной записи.
+	OnReject func(Reject)
+}
+
+// Import читает записи по одной и сохраняет их через creator. Записи,
+// которые не удалось разобрать или сохранить, пропускаются и передаются в
+// OnReject. Ошибка возвращается, только если дальше читать файл нельзя или
+// отменен ctx; Progress в этом случае отражает уже обработанные записи.
+func Import(ctx context.Context, creator Creator, r Reader, opts Options) (Progress, error) {
+	if opts.ProgressEvery <= 0 {
+		opts.ProgressEvery = DefaultProgressEvery
+	}
+	var progress Progress
+	report := func() {
+		if opts.OnProgress != nil {
+			opts.OnProgress(progress)
+		}
+	}
+	for {
+		if err := ctx.Err(); err != nil {
+			return progress, err
+		}
+		rec, err := r.Next()
+		if errors.Is(err, io.EOF) {
+			if progress.Read%opts.ProgressEvery != 0 {
+				report()
+			}
+			return progress, nil
+		}
+		if err != nil {
+			return progress, err
+		}
+
+		progress.Read++
+		if err := importRecord(creator, rec); err != nil {
+			progress.Skipped++
+			metrics.Imports.Add("skipped", 1)
+			if opts.OnReject != nil {
+				opts.OnReject(newReject(rec, err))
+			}
+		} else {
+			progress.Imported++
+			metrics.Imports.Add("imported", 1)
+		}
+		if progress.Read%opts.ProgressEvery == 0 {
+			report()
+		}
+	}
+}
+
+func importRecord(creator Creator, rec Record) error {
+	if rec.Err != nil {
+		return rec.Err
+	}
+	return creator.CreateOrder(rec.Order)
+}
+
+func newReject(rec Record, err error) Reject {
+	reject := Reject{
+		Line:     rec.Line,
+		OrderUID: rec.Order.OrderUID,
+		Error:    err.Error(),
+		Record:   rec.Raw,
+	}
+	errors.As(err, &reject.Violations)
+	return reject
+}
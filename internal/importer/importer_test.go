@@ -0,0 +1,204 @@
+package importer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"orderkeeper/internal/export"
+	"orderkeeper/internal/models"
+	"orderkeeper/internal/money"
+	"orderkeeper/internal/validation"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeCreator повторяет проверки CreateOrder без БД.
+type fakeCreator struct {
+	created []models.Order
+}
+
+func (c *fakeCreator) CreateOrder(order models.Order) error {
+	if err := validation.Order(&order); err != nil {
+		return err
+	}
+	for _, o := range c.created {
+		if o.OrderUID == order.OrderUID {
+			return errors.New("duplicate order_uid")
+		}
+	}
+	c.created = append(c.created, order)
+	return nil
+}
+
+func testOrder(uid string) models.Order {
+	return models.Order{
+		OrderUID:        uid,
+		TrackNumber:     "WBILMTESTTRACK",
+		Entry:           "WBIL",
+		Locale:          "en",
+		CustomerID:      "test",
+		DeliveryService: "meest",
+		DateCreated:     models.Timestamp{Time: time.Date(2021, 11, 26, 6, 22, 19, 0, time.UTC)},
+		Delivery: models.Delivery{
+			ID:      7,
+			Name:    "Test Testov",
+			Phone:   "+9720000000",
+			Zip:     "2639809",
+			City:    "Kiryat Mozkin",
+			Address: "Ploshad Mira 15",
+			Region:  "Kraiot",
+			Email:   "test@gmail.com",
+		},
+		Payment: models.Payment{
+			Transaction:  uid,
+			Currency:     "USD",
+			Provider:     "wbpay",
+			Amount:       money.New(1817, "USD"),
+			PaymentDT:    models.UnixTime{Time: time.Unix(1637907727, 0)},
+			Bank:         "alpha",
+			DeliveryCost: money.New(1500, "USD"),
+			GoodsTotal:   money.New(317, "USD"),
+		},
+		Items: []models.Item{{
+			CHRTID:      9934930,
+			TrackNumber: "WBILMTESTTRACK",
+			Price:       money.New(453, "USD"),
+			Name:        "Mascaras",
+			Sale:        30,
+			TotalPrice:  money.New(317, "USD"),
+			NMID:        2389212,
+		}},
+	}
+}
+
+func ndjson(t *testing.T, lines ...any) string {
+	var b strings.Builder
+	for _, line := range lines {
+		if s, ok := line.(string); ok {
+			b.WriteString(s + "\n")
+			continue
+		}
+		data, err := json.Marshal(line)
+		require.NoError(t, err)
+		b.Write(append(data, '\n'))
+	}
+	return b.String()
+}
+
+func TestImport_NDJSON(t *testing.T) {
+	invalid := testOrder("uid-3")
+	invalid.Delivery.Email = "not-an-email"
+	input := ndjson(t,
+		testOrder("uid-1"),
+		"",
+		`{"order_uid": "uid-2", "colour": "red"}`,
+		invalid,
+		testOrder("uid-1"),
+		testOrder("uid-4"),
+	)
+
+	r, err := NewReader(export.FormatNDJSON, strings.NewReader(input))
+	require.NoError(t, err)
+	creator := &fakeCreator{}
+	var progress []Progress
+	var rejects []Reject
+	result, err := Import(context.Background(), creator, r, Options{
+		ProgressEvery: 2,
+		OnProgress:    func(p Progress) { progress = append(progress, p) },
+		OnReject:      func(rej Reject) { rejects = append(rejects, rej) },
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, Progress{Read: 5, Imported: 2, Skipped: 3}, result)
+	assert.Equal(t, []Progress{
+		{Read: 2, Imported: 1, Skipped: 1},
+		{Read: 4, Imported: 1, Skipped: 3},
+		{Read: 5, Imported: 2, Skipped: 3},
+	}, progress)
+
+	require.Len(t, creator.created, 2)
+	assert.Zero(t, creator.created[0].Delivery.ID, "surrogate keys must be assigned by the database")
+
+	require.Len(t, rejects, 3)
+	assert.Equal(t, 3, rejects[0].Line)
+	assert.Contains(t, rejects[0].Error, "colour")
+	assert.Equal(t, `{"order_uid": "uid-2", "colour": "red"}`, rejects[0].Record)
+	assert.Equal(t, "uid-3", rejects[1].OrderUID)
+	require.Len(t, rejects[1].Violations, 1)
+	assert.Equal(t, "delivery.email:email", rejects[1].Violations[0].Code())
+	assert.Equal(t, 5, rejects[2].Line)
+}
+
+func TestImport_CSV(t *testing.T) {
+	second := testOrder("uid-2")
+	second.Items = append(second.Items, second.Items[0])
+	second.Items[1].Name = "Lipstick, red"
+	broken := testOrder("uid-3")
+	broken.Items = nil
+
+	var buf bytes.Buffer
+	w, err := export.NewWriter(export.FormatCSV, &buf)
+	require.NoError(t, err)
+	require.NoError(t, w.WriteBatch([]models.Order{testOrder("uid-1"), second, broken}))
+	require.NoError(t, w.Close())
+
+	r, err := NewReader(export.FormatCSV, &buf)
+	require.NoError(t, err)
+	creator := &fakeCreator{}
+	var rejects []Reject
+	result, err := Import(context.Background(), creator, r, Options{
+		OnReject: func(rej Reject) { rejects = append(rejects, rej) },
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, Progress{Read: 3, Imported: 2, Skipped: 1}, result)
+	require.Len(t, creator.created, 2)
+	assert.Len(t, creator.created[1].Items, 2)
+	assert.Equal(t, "+9720000000", creator.created[1].Delivery.Phone)
+
+	require.Len(t, rejects, 1)
+	assert.Equal(t, "uid-3", rejects[0].OrderUID)
+	assert.Equal(t, 5, rejects[0].Line)
+	assert.True(t, strings.HasPrefix(rejects[0].Record, "order_uid,"), "rejected CSV keeps its header")
+}
+
+func TestImport_OversizedRecord(t *testing.T) {
+	huge := `{"order_uid": "` + strings.Repeat("x", MaxRecordSize) + `"}`
+	r, err := NewReader(export.FormatNDJSON, strings.NewReader(ndjson(t, huge, testOrder("uid-1"))))
+	require.NoError(t, err)
+	creator := &fakeCreator{}
+	var rejects []Reject
+
+	result, err := Import(context.Background(), creator, r, Options{
+		OnReject: func(rej Reject) { rejects = append(rejects, rej) },
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, Progress{Read: 2, Imported: 1, Skipped: 1}, result)
+	require.Len(t, rejects, 1)
+	assert.Equal(t, 1, rejects[0].Line)
+	assert.Contains(t, rejects[0].Error, "exceeds")
+	assert.Empty(t, rejects[0].Record)
+}
+
+func TestImport_Cancelled(t *testing.T) {
+	r, err := NewReader(export.FormatNDJSON, strings.NewReader(ndjson(t, testOrder("uid-1"))))
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err = Import(ctx, &fakeCreator{}, r, Options{})
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestNewReader(t *testing.T) {
+	_, err := NewReader(export.FormatParquet, strings.NewReader(""))
+	assert.Error(t, err)
+	_, err = NewReader(export.FormatCSV, strings.NewReader(""))
+	assert.Error(t, err)
+}
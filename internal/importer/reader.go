@@ -0,0 +1,205 @@
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"orderkeeper/internal/export"
+	"orderkeeper/internal/models"
+	"strings"
+)
+
+// Record — заказ, прочитанный из файла. Err заполнен, если запись не удалось
+// разобрать; такая запись не импортируется и попадает в отклоненные.
+type Record struct {
+	// Line — номер первой строки записи в файле, начиная с 1.
+	Line  int
+	Order models.Order
+	// Raw — исходный текст записи для файла отклоненных.
+	Raw string
+	Err error
+}
+
+// MaxRecordSize — наибольшая длина строки NDJSON. Более длинные записи
+// отклоняются без чтения в память целиком.
+const MaxRecordSize = 1 << 20
+
+type Reader interface {
+	// Next возвращает следующую запись или io.EOF в конце файла. Прочие
+	// ошибки означают, что файл дальше читать нельзя.
+	Next() (Record, error)
+}
+
+func NewReader(format string, r io.Reader) (Reader, error) {
+	switch format {
+	case "", export.FormatNDJSON:
+		return &ndjsonReader{r: bufio.NewReaderSize(r, 64<<10)}, nil
+	case export.FormatCSV:
+		return newCSVReader(r)
+	default:
+		return nil, fmt.Errorf("unsupported import format %q", format)
+	}
+}
+
+// ndjsonReader читает заказ на строку в формате models.Order — так их
+// выгружает export и принимает консьюмер.
+type ndjsonReader struct {
+	r    *bufio.Reader
+	line int
+}
+
+func (r *ndjsonReader) Next() (Record, error) {
+	for {
+		data, tooLong, err := r.readLine()
+		if len(data) == 0 && !tooLong && err != nil {
+			return Record{}, err
+		}
+		if err != nil && !errors.Is(err, io.EOF) {
+			return Record{}, err
+		}
+		r.line++
+		if tooLong {
+			return Record{Line: r.line, Err: fmt.Errorf("record exceeds %d bytes", MaxRecordSize)}, nil
+		}
+		data = bytes.TrimSpace(data)
+		if len(data) == 0 {
+			continue
+		}
+
+		rec := Record{Line: r.line, Raw: string(data)}
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&rec.Order); err != nil {
+			rec.Err = fmt.Errorf("invalid order JSON: %w", err)
+		}
+		resetIDs(&rec.Order)
+		return rec, nil
+	}
+}
+
+// readLine читает строку до '\n'. Строку длиннее MaxRecordSize она
+// дочитывает и отбрасывает, сообщая tooLong.
+func (r *ndjsonReader) readLine() (line []byte, tooLong bool, err error) {
+	for {
+		chunk, err := r.r.ReadSlice('\n')
+		if !tooLong {
+			if len(line)+len(chunk) > MaxRecordSize {
+				tooLong, line = true, nil
+			} else {
+				line = append(line, chunk...)
+			}
+		}
+		if !errors.Is(err, bufio.ErrBufferFull) {
+			return line, tooLong, err
+		}
+	}
+}
+
+// resetIDs сбрасывает суррогатные ключи из выгрузки другой БД: их
+// назначает база при вставке.
+func resetIDs(order *models.Order) {
+	order.Delivery.ID = 0
+	order.Payment.ID = 0
+	for i := range order.Items {
+		order.Items[i].ID = 0
+	}
+}
+
+// csvReader читает плоский CSV из export: подряд идущие строки с одним
+// order_uid собираются в один заказ.
+type csvReader struct {
+	r      *csv.Reader
+	cols   export.CSVColumns
+	header []string
+
+	// pending — уже прочитанная первая строка следующего заказа.
+	pending     []string
+	pendingLine int
+}
+
+func newCSVReader(r io.Reader) (*csvReader, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	header, err := cr.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, errors.New("CSV file is empty")
+		}
+		return nil, err
+	}
+	cols, err := export.ParseCSVHeader(header)
+	if err != nil {
+		return nil, err
+	}
+	return &csvReader{r: cr, cols: cols, header: header}, nil
+}
+
+func (r *csvReader) Next() (Record, error) {
+	if r.pending == nil {
+		if err := r.read(); err != nil {
+			return Record{}, err
+		}
+	}
+	line := r.pendingLine
+	records := [][]string{r.pending}
+	uid := r.uid(r.pending)
+	for {
+		err := r.read()
+		if errors.Is(err, io.EOF) {
+			r.pending = nil
+			break
+		}
+		if err != nil {
+			return Record{}, err
+		}
+		if r.uid(r.pending) != uid {
+			break
+		}
+		records = append(records, r.pending)
+	}
+
+	rec := Record{Line: line, Raw: r.raw(records)}
+	rows := make([]export.Row, 0, len(records))
+	for _, record := range records {
+		row, err := r.cols.ParseRow(record)
+		if err != nil {
+			rec.Order.OrderUID = uid
+			rec.Err = err
+			return rec, nil
+		}
+		rows = append(rows, row)
+	}
+	rec.Order = export.Assemble(rows)
+	return rec, nil
+}
+
+func (r *csvReader) read() error {
+	record, err := r.r.Read()
+	if err != nil {
+		return err
+	}
+	r.pending = record
+	r.pendingLine, _ = r.r.FieldPos(0)
+	return nil
+}
+
+func (r *csvReader) uid(record []string) string {
+	if i := r.cols["order_uid"]; i < len(record) {
+		return record[i]
+	}
+	return ""
+}
+
+// raw кодирует строки заказа обратно в CSV вместе с заголовком, чтобы
+// отклоненный заказ можно было исправить и импортировать отдельно.
+func (r *csvReader) raw(records [][]string) string {
+	var b strings.Builder
+	w := csv.NewWriter(&b)
+	_ = w.Write(r.header)
+	_ = w.WriteAll(records)
+	return b.String()
+}
@@ -0,0 +1,483 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	kafka "github.com/segmentio/kafka-go"
+)
+
+const (
+	maxReplayMessages = 10000
+	maxReplayFailures = 100
+)
+
+var (
+	ErrInvalidAdminRequest = errors.New("invalid consumer admin request")
+	// ErrDryRun — операция изменила бы офсеты или данные, а консьюмер
+	// работает в режиме dry-run.
+	ErrDryRun = errors.New("consumer is in dry-run mode")
+)
+
+type adminOp struct {
+	fn   func() error
+	done chan error
+}
+
+type dryRunKey struct{}
+
+// WithDryRun помечает контекст так, что обработчики сообщений только
+// декодируют и проверяют сообщение, ничего не меняя в хранилище.
+func WithDryRun(ctx context.Context) context.Context {
+	return context.WithValue(ctx, dryRunKey{}, true)
+}
+
+func IsDryRun(ctx context.Context) bool {
+	v, _ := ctx.Value(dryRunKey{}).(bool)
+	return v
+}
+
+type tenantKey struct{}
+
+// WithTenant помечает обработку сообщения площадкой, из топика которой оно
+// пришло.
+func WithTenant(ctx context.Context, tenant string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenant)
+}
+
+// TenantFrom возвращает площадку сообщения; пустая строка — общий топик.
+func TenantFrom(ctx context.Context) string {
+	v, _ := ctx.Value(tenantKey{}).(string)
+	return v
+}
+
+type SeekRequest struct {
+	Topic string `json:"topic"`
+	// Partition — номер партиции; если не задан, сдвигаются все партиции топика.
+	Partition *int `json:"partition,omitempty"`
+	// Должно быть задано ровно одно из Offset и Timestamp.
+	Offset    *int64     `json:"offset,omitempty"`
+	Timestamp *time.Time `json:"timestamp,omitempty"`
+}
+
+type PartitionOffset struct {
+	Topic     string `json:"topic"`
+	Partition int    `json:"partition"`
+	Offset    int64  `json:"offset"`
+}
+
+type PartitionPosition struct {
+	Topic     string `json:"topic"`
+	Partition int    `json:"partition"`
+	Committed int64  `json:"committed"`
+	End       int64  `json:"end"`
+	Lag       int64  `json:"lag"`
+}
+
+type Position struct {
+	GroupID     string              `json:"group_id"`
+	Paused      bool                `json:"paused"`
+	Concurrency int                 `json:"concurrency"`
+	Partitions  []PartitionPosition `json:"partitions"`
+	TotalLag    int64               `json:"total_lag"`
+}
+
+type ReplayRequest struct {
+	Topic     string `json:"topic"`
+	Partition int    `json:"partition"`
+	From      int64  `json:"from"`
+	// To — последний офсет диапазона включительно.
+	To     int64 `json:"to"`
+	DryRun bool  `json:"dry_run"`
+}
+
+type ReplayFailure struct {
+	Offset int64  `json:"offset"`
+	Error  string `json:"error"`
+}
+
+type ReplayResult struct {
+	Topic     string          `json:"topic"`
+	Partition int             `json:"partition"`
+	From      int64           `json:"from"`
+	To        int64           `json:"to"`
+	DryRun    bool            `json:"dry_run"`
+	Processed int             `json:"processed"`
+	Succeeded int             `json:"succeeded"`
+	Failed    int             `json:"failed"`
+	Failures  []ReplayFailure `json:"failures,omitempty"`
+}
+
+// exec выполняет fn в цикле Run, когда пул воркеров остановлен.
+func (c *Consumer) exec(ctx context.Context, fn func() error) error {
+	op := adminOp{fn: fn, done: make(chan error, 1)}
+	select {
+	case c.ops <- op:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	select {
+	case err := <-op.done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Pause останавливает выборку новых сообщений. Уже полученные сообщения
+// дообрабатываются, членство в consumer group сохраняется.
+func (c *Consumer) Pause() {
+	c.pauseMu.Lock()
+	defer c.pauseMu.Unlock()
+	if c.paused {
+		return
+	}
+	c.paused = true
+	c.resumed = make(chan struct{})
+	log.Println("Kafka consumer paused")
+}
+
+func (c *Consumer) Resume() {
+	c.pauseMu.Lock()
+	defer c.pauseMu.Unlock()
+	if !c.paused {
+		return
+	}
+	c.paused = false
+	close(c.resumed)
+	log.Println("Kafka consumer resumed")
+}
+
+func (c *Consumer) Paused() bool {
+	c.pauseMu.Lock()
+	defer c.pauseMu.Unlock()
+	return c.paused
+}
+
+func (c *Consumer) waitResumed(ctx context.Context) bool {
+	c.pauseMu.Lock()
+	resumed := c.resumed
+	c.pauseMu.Unlock()
+	select {
+	case <-resumed:
+		return ctx.Err() == nil
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// Seek переставляет закоммиченные офсеты группы. Ридер на время операции
+// покидает группу: коммит вне поколения группы брокер принимает, только
+// если в ней не осталось других участников, поэтому остальные инстансы
+// сервиса перед seek нужно остановить.
+func (c *Consumer) Seek(ctx context.Context, req SeekRequest) ([]PartitionOffset, error) {
+	if req.Topic == "" || (req.Offset == nil) == (req.Timestamp == nil) {
+		return nil, fmt.Errorf("%w: topic and exactly one of offset or timestamp are required", ErrInvalidAdminRequest)
+	}
+	if !c.subscribed(req.Topic) {
+		return nil, fmt.Errorf("%w: consumer is not subscribed to topic %q", ErrInvalidAdminRequest, req.Topic)
+	}
+	if c.cfg.DryRun {
+		return nil, fmt.Errorf("%w: seek would commit offsets", ErrDryRun)
+	}
+
+	c.adminMu.Lock()
+	defer c.adminMu.Unlock()
+
+	partitions, err := c.partitions(ctx, req.Topic, req.Partition)
+	if err != nil {
+		return nil, err
+	}
+	offsets, err := c.resolveOffsets(ctx, req, partitions)
+	if err != nil {
+		return nil, err
+	}
+
+	commits := make([]kafka.OffsetCommit, len(offsets))
+	for i, o := range offsets {
+		commits[i] = kafka.OffsetCommit{Partition: o.Partition, Offset: o.Offset}
+	}
+
+	err = c.exec(ctx, func() error {
+		if err := c.reader.Close(); err != nil {
+			log.Printf("Failed to close Kafka reader before seek: %v", err)
+		}
+		defer func() { c.reader = kafka.NewReader(c.readerConfig) }()
+
+		res, err := c.client.OffsetCommit(ctx, &kafka.OffsetCommitRequest{
+			GroupID:      c.groupID,
+			GenerationID: -1,
+			Topics:       map[string][]kafka.OffsetCommit{req.Topic: commits},
+		})
+		if err != nil {
+			return err
+		}
+		for _, p := range res.Topics[req.Topic] {
+			if p.Error != nil {
+				return fmt.Errorf("partition %d: %w", p.Partition, p.Error)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("seek failed: %w", err)
+	}
+	log.Printf("Kafka consumer group %s seeked on topic %s: %v", c.groupID, req.Topic, offsets)
+	return offsets, nil
+}
+
+func (c *Consumer) resolveOffsets(ctx context.Context, req SeekRequest, partitions []int) ([]PartitionOffset, error) {
+	bounds, err := c.bounds(ctx, req.Topic, partitions)
+	if err != nil {
+		return nil, err
+	}
+
+	offsets := make([]PartitionOffset, 0, len(partitions))
+	if req.Offset != nil {
+		for _, p := range partitions {
+			b := bounds[p]
+			if *req.Offset < b.FirstOffset || *req.Offset > b.LastOffset {
+				return nil, fmt.Errorf("%w: offset %d is out of range [%d, %d] for partition %d",
+					ErrInvalidAdminRequest, *req.Offset, b.FirstOffset, b.LastOffset, p)
+			}
+			offsets = append(offsets, PartitionOffset{Topic: req.Topic, Partition: p, Offset: *req.Offset})
+		}
+		return offsets, nil
+	}
+
+	requests := make([]kafka.OffsetRequest, len(partitions))
+	for i, p := range partitions {
+		requests[i] = kafka.TimeOffsetOf(p, *req.Timestamp)
+	}
+	res, err := c.client.ListOffsets(ctx, &kafka.ListOffsetsRequest{Topics: map[string][]kafka.OffsetRequest{req.Topic: requests}})
+	if err != nil {
+		return nil, err
+	}
+	for _, po := range res.Topics[req.Topic] {
+		if po.Error != nil {
+			return nil, fmt.Errorf("partition %d: %w", po.Partition, po.Error)
+		}
+		// Если после момента времени сообщений нет, читаем с конца партиции.
+		offset := bounds[po.Partition].LastOffset
+		for o := range po.Offsets {
+			if o >= 0 {
+				offset = o
+			}
+		}
+		offsets = append(offsets, PartitionOffset{Topic: req.Topic, Partition: po.Partition, Offset: offset})
+	}
+	sort.Slice(offsets, func(i, j int) bool { return offsets[i].Partition < offsets[j].Partition })
+	return offsets, nil
+}
+
+// Position возвращает закоммиченные офсеты группы, концы партиций и лаг.
+func (c *Consumer) Position(ctx context.Context) (Position, error) {
+	pos, err := groupPosition(ctx, c.client, c.cfg.Topics(), c.groupID)
+	if err != nil {
+		return Position{}, err
+	}
+	pos.Paused = c.Paused()
+	pos.Concurrency = c.Concurrency()
+	return pos, nil
+}
+
+// GroupPosition возвращает офсеты и лаг группы консьюмера с настройками cfg
+// (с учетом DryRun), не вступая в группу, — для CLI, которому не нужен
+// работающий консьюмер.
+func GroupPosition(ctx context.Context, cfg Config) (Position, error) {
+	transport, err := cfg.Transport()
+	if err != nil {
+		return Position{}, err
+	}
+	client := &kafka.Client{Addr: kafka.TCP(cfg.Brokers...), Transport: transport}
+	return groupPosition(ctx, client, cfg.Topics(), cfg.groupID())
+}
+
+func groupPosition(ctx context.Context, client *kafka.Client, topics []string, groupID string) (Position, error) {
+	pos := Position{GroupID: groupID}
+
+	topicPartitions := make(map[string][]int)
+	for _, topic := range topics {
+		partitions, err := listPartitions(ctx, client, topic, nil)
+		if err != nil {
+			return Position{}, err
+		}
+		topicPartitions[topic] = partitions
+	}
+
+	committed, err := client.OffsetFetch(ctx, &kafka.OffsetFetchRequest{GroupID: groupID, Topics: topicPartitions})
+	if err != nil {
+		return Position{}, err
+	}
+	if committed.Error != nil {
+		return Position{}, committed.Error
+	}
+
+	for _, topic := range topics {
+		bounds, err := partitionBounds(ctx, client, topic, topicPartitions[topic])
+		if err != nil {
+			return Position{}, err
+		}
+		for _, p := range committed.Topics[topic] {
+			b := bounds[p.Partition]
+			pp := PartitionPosition{Topic: topic, Partition: p.Partition, Committed: p.CommittedOffset, End: b.LastOffset}
+			if pp.Committed >= 0 {
+				pp.Lag = pp.End - pp.Committed
+			} else {
+				pp.Lag = pp.End - b.FirstOffset
+			}
+			pos.TotalLag += pp.Lag
+			pos.Partitions = append(pos.Partitions, pp)
+		}
+	}
+	sort.Slice(pos.Partitions, func(i, j int) bool {
+		a, b := pos.Partitions[i], pos.Partitions[j]
+		return a.Topic < b.Topic || (a.Topic == b.Topic && a.Partition < b.Partition)
+	})
+	return pos, nil
+}
+
+// Replay прогоняет диапазон офсетов партиции через обработчики отдельным
+// ридером без consumer group: офсеты группы не меняются. В режиме dry-run
+// сообщения только декодируются и проверяются; консьюмер в режиме dry-run
+// принимает только такой replay.
+func (c *Consumer) Replay(ctx context.Context, req ReplayRequest) (ReplayResult, error) {
+	if req.Topic == "" || req.From < 0 || req.To < req.From {
+		return ReplayResult{}, fmt.Errorf("%w: topic and 0 <= from <= to are required", ErrInvalidAdminRequest)
+	}
+	if req.To-req.From >= maxReplayMessages {
+		return ReplayResult{}, fmt.Errorf("%w: at most %d messages can be replayed at once", ErrInvalidAdminRequest, maxReplayMessages)
+	}
+	if !c.subscribed(req.Topic) {
+		return ReplayResult{}, fmt.Errorf("%w: consumer is not subscribed to topic %q", ErrInvalidAdminRequest, req.Topic)
+	}
+	if c.cfg.DryRun && !req.DryRun {
+		return ReplayResult{}, fmt.Errorf("%w: only dry_run replay is allowed", ErrDryRun)
+	}
+
+	bounds, err := c.bounds(ctx, req.Topic, []int{req.Partition})
+	if err != nil {
+		return ReplayResult{}, err
+	}
+	b, ok := bounds[req.Partition]
+	if !ok {
+		return ReplayResult{}, fmt.Errorf("%w: partition %d not found", ErrInvalidAdminRequest, req.Partition)
+	}
+	if req.From < b.FirstOffset || req.From >= b.LastOffset {
+		return ReplayResult{}, fmt.Errorf("%w: offset %d is out of range [%d, %d)", ErrInvalidAdminRequest, req.From, b.FirstOffset, b.LastOffset)
+	}
+	req.To = min(req.To, b.LastOffset-1)
+
+	reader := kafka.NewReader(kafka.ReaderConfig{
+		Brokers:   c.cfg.Brokers,
+		Topic:     req.Topic,
+		Partition: req.Partition,
+		Dialer:    c.readerConfig.Dialer,
+		MinBytes:  c.readerConfig.MinBytes,
+		MaxBytes:  c.readerConfig.MaxBytes,
+		MaxWait:   c.readerConfig.MaxWait,
+	})
+	defer reader.Close()
+	if err := reader.SetOffset(req.From); err != nil {
+		return ReplayResult{}, err
+	}
+
+	handlerCtx := ctx
+	if req.DryRun {
+		handlerCtx = WithDryRun(ctx)
+	}
+
+	result := ReplayResult{Topic: req.Topic, Partition: req.Partition, From: req.From, To: req.To, DryRun: req.DryRun}
+	for {
+		msg, err := reader.ReadMessage(ctx)
+		if err != nil {
+			return result, err
+		}
+		if msg.Offset > req.To {
+			break
+		}
+		result.Processed++
+		if err := c.dispatch(handlerCtx, msg); err != nil {
+			result.Failed++
+			if len(result.Failures) < maxReplayFailures {
+				result.Failures = append(result.Failures, ReplayFailure{Offset: msg.Offset, Error: err.Error()})
+			}
+		} else {
+			result.Succeeded++
+		}
+		if msg.Offset == req.To {
+			break
+		}
+	}
+	log.Printf("Replayed %d message(s) from %s[%d] offsets %d..%d (dry run: %t): %d failed",
+		result.Processed, req.Topic, req.Partition, req.From, req.To, req.DryRun, result.Failed)
+	return result, nil
+}
+
+func (c *Consumer) subscribed(topic string) bool {
+	for _, t := range c.cfg.Topics() {
+		if t == topic {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *Consumer) partitions(ctx context.Context, topic string, only *int) ([]int, error) {
+	return listPartitions(ctx, c.client, topic, only)
+}
+
+func listPartitions(ctx context.Context, client *kafka.Client, topic string, only *int) ([]int, error) {
+	meta, err := client.Metadata(ctx, &kafka.MetadataRequest{Topics: []string{topic}})
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range meta.Topics {
+		if t.Name != topic {
+			continue
+		}
+		if t.Error != nil {
+			return nil, t.Error
+		}
+		var partitions []int
+		for _, p := range t.Partitions {
+			if only == nil || p.ID == *only {
+				partitions = append(partitions, p.ID)
+			}
+		}
+		if len(partitions) == 0 {
+			return nil, fmt.Errorf("%w: partition %d not found in topic %q", ErrInvalidAdminRequest, *only, topic)
+		}
+		sort.Ints(partitions)
+		return partitions, nil
+	}
+	return nil, fmt.Errorf("%w: topic %q not found", ErrInvalidAdminRequest, topic)
+}
+
+// bounds возвращает первый и следующий за последним офсеты партиций.
+func (c *Consumer) bounds(ctx context.Context, topic string, partitions []int) (map[int]kafka.PartitionOffsets, error) {
+	return partitionBounds(ctx, c.client, topic, partitions)
+}
+
+func partitionBounds(ctx context.Context, client *kafka.Client, topic string, partitions []int) (map[int]kafka.PartitionOffsets, error) {
+	requests := make([]kafka.OffsetRequest, 0, 2*len(partitions))
+	for _, p := range partitions {
+		requests = append(requests, kafka.FirstOffsetOf(p), kafka.LastOffsetOf(p))
+	}
+	res, err := client.ListOffsets(ctx, &kafka.ListOffsetsRequest{Topics: map[string][]kafka.OffsetRequest{topic: requests}})
+	if err != nil {
+		return nil, err
+	}
+	bounds := make(map[int]kafka.PartitionOffsets, len(partitions))
+	for _, po := range res.Topics[topic] {
+		if po.Error != nil {
+			return nil, fmt.Errorf("partition %d: %w", po.Partition, po.Error)
+		}
+		bounds[po.Partition] = po
+	}
+	return bounds, nil
+}
@@ -0,0 +1,57 @@
+package kafka
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConsumer_PauseResume(t *testing.T) {
+	c := &Consumer{resumed: make(chan struct{})}
+	close(c.resumed)
+
+	assert.True(t, c.waitResumed(context.Background()))
+
+	c.Pause()
+	assert.True(t, c.Paused())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	assert.False(t, c.waitResumed(ctx))
+
+	done := make(chan bool)
+	go func() { done <- c.waitResumed(context.Background()) }()
+	c.Resume()
+	assert.True(t, <-done)
+	assert.False(t, c.Paused())
+}
+
+func TestConsumer_AdminValidation(t *testing.T) {
+	c := &Consumer{cfg: Config{Topic: "orders"}}
+	offset := int64(10)
+	now := time.Now()
+
+	_, err := c.Seek(context.Background(), SeekRequest{Topic: "orders"})
+	assert.ErrorIs(t, err, ErrInvalidAdminRequest)
+
+	_, err = c.Seek(context.Background(), SeekRequest{Topic: "orders", Offset: &offset, Timestamp: &now})
+	assert.ErrorIs(t, err, ErrInvalidAdminRequest)
+
+	_, err = c.Seek(context.Background(), SeekRequest{Topic: "payments", Offset: &offset})
+	assert.ErrorIs(t, err, ErrInvalidAdminRequest)
+
+	_, err = c.Replay(context.Background(), ReplayRequest{Topic: "orders", From: 10, To: 5})
+	assert.ErrorIs(t, err, ErrInvalidAdminRequest)
+
+	_, err = c.Replay(context.Background(), ReplayRequest{Topic: "orders", From: 0, To: maxReplayMessages})
+	assert.ErrorIs(t, err, ErrInvalidAdminRequest)
+
+	c.cfg.DryRun = true
+	_, err = c.Seek(context.Background(), SeekRequest{Topic: "orders", Offset: &offset})
+	assert.ErrorIs(t, err, ErrDryRun)
+
+	_, err = c.Replay(context.Background(), ReplayRequest{Topic: "orders", From: 0, To: 5})
+	assert.ErrorIs(t, err, ErrDryRun)
+}
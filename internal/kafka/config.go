@@ -0,0 +1,319 @@
+package kafka
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"orderkeeper/internal/schemaregistry"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	kafka "github.com/segmentio/kafka-go"
+	"github.com/segmentio/kafka-go/sasl"
+	"github.com/segmentio/kafka-go/sasl/plain"
+	"github.com/segmentio/kafka-go/sasl/scram"
+)
+
+const (
+	SASLPlain       = "PLAIN"
+	SASLScramSHA256 = "SCRAM-SHA-256"
+	SASLScramSHA512 = "SCRAM-SHA-512"
+
+	StartOffsetFirst = "first"
+	StartOffsetLast  = "last"
+)
+
+// Config описывает подключение консьюмера к кластеру и тюнинг ридера.
+// Нулевые значения параметров тюнинга означают значения по умолчанию kafka-go.
+type Config struct {
+	Brokers []string
+	Topic   string
+	GroupID string
+
+	// Необязательные топики для остальных типов сообщений. Сообщения любого
+	// типа можно также писать в Topic с заголовком MessageTypeHeader.
+	StatusTopic       string
+	PaymentTopic      string
+	CancellationTopic string
+
+	// TenantTopics сопоставляет площадкам (Order.Entry) их собственные
+	// топики. Сообщения из такого топика меняют только заказы его площадки;
+	// сообщения без MessageTypeHeader считаются заказами.
+	TenantTopics map[string]string
+
+	// DLQTopic — топик для сообщений, которые не удалось обработать. Без
+	// него такие сообщения только логируются.
+	DLQTopic string
+
+	TLS  TLSConfig
+	SASL SASLConfig
+
+	MinBytes       int
+	MaxBytes       int
+	MaxWait        time.Duration
+	StartOffset    string
+	SessionTimeout time.Duration
+
+	// DryRun включает режим проверки: сообщения декодируются и валидируются,
+	// но ничего не пишется в БД и офсеты не коммитятся.
+	DryRun bool
+
+	// SchemaRegistry нужен для сообщений в Avro и Protobuf. Без него
+	// консьюмер принимает только JSON.
+	SchemaRegistry schemaregistry.Client
+}
+
+type TLSConfig struct {
+	Enabled            bool
+	CAFile             string
+	CertFile           string
+	KeyFile            string
+	InsecureSkipVerify bool
+}
+
+type SASLConfig struct {
+	Mechanism string
+	Username  string
+	Password  string
+}
+
+// Topics возвращает все топики, на которые подписывается консьюмер.
+func (c Config) Topics() []string {
+	seen := make(map[string]bool)
+	var topics []string
+	shared := []string{c.Topic, c.StatusTopic, c.PaymentTopic, c.CancellationTopic}
+	for _, topic := range append(shared, c.tenantTopics()...) {
+		if topic != "" && !seen[topic] {
+			seen[topic] = true
+			topics = append(topics, topic)
+		}
+	}
+	return topics
+}
+
+func (c Config) tenantTopics() []string {
+	topics := make([]string, 0, len(c.TenantTopics))
+	for _, topic := range c.TenantTopics {
+		topics = append(topics, topic)
+	}
+	slices.Sort(topics)
+	return topics
+}
+
+// TenantOf возвращает площадку, которой принадлежит topic, или пустую
+// строку для общих топиков.
+func (c Config) TenantOf(topic string) string {
+	for tenant, t := range c.TenantTopics {
+		if t == topic {
+			return tenant
+		}
+	}
+	return ""
+}
+
+func (c Config) topicsByType() map[string]string {
+	topics := map[string]string{MessageTypeOrder: c.Topic}
+	if c.StatusTopic != "" {
+		topics[MessageTypeStatusUpdate] = c.StatusTopic
+	}
+	if c.PaymentTopic != "" {
+		topics[MessageTypePaymentConfirmation] = c.PaymentTopic
+	}
+	if c.CancellationTopic != "" {
+		topics[MessageTypeCancellation] = c.CancellationTopic
+	}
+	return topics
+}
+
+func (c Config) Validate() error {
+	if len(c.Brokers) == 0 {
+		return errors.New("kafka brokers string is not set")
+	}
+	if c.Topic == "" {
+		return errors.New("kafka topic is not set")
+	}
+	if c.GroupID == "" {
+		return errors.New("kafka groupID is not set")
+	}
+	if len(c.Topics()) != len(c.topicsByType())+len(c.TenantTopics) {
+		return errors.New("kafka topics for different message types and tenants must be distinct")
+	}
+	if slices.Contains(c.Topics(), c.DLQTopic) {
+		return errors.New("kafka DLQ topic must differ from consumed topics")
+	}
+	if c.MinBytes < 0 || c.MaxBytes < 0 {
+		return errors.New("kafka min/max bytes must not be negative")
+	}
+	if c.MaxBytes > 0 && c.MinBytes > c.MaxBytes {
+		return errors.New("kafka min bytes must not exceed max bytes")
+	}
+	if _, err := parseStartOffset(c.StartOffset); err != nil {
+		return err
+	}
+	return nil
+}
+
+// ParseBrokers разбирает список брокеров вида "host1:9092,host2:9092".
+func ParseBrokers(brokersStr string) []string {
+	var brokers []string
+	for _, b := range strings.Split(brokersStr, ",") {
+		if b = strings.TrimSpace(b); b != "" {
+			brokers = append(brokers, b)
+		}
+	}
+	return brokers
+}
+
+// ParseTenantTopics разбирает сопоставление площадок и топиков вида
+// "WBIL=orders-wbil,OZON=orders-ozon".
+func ParseTenantTopics(s string) (map[string]string, error) {
+	topics := make(map[string]string)
+	for _, pair := range strings.Split(s, ",") {
+		if pair = strings.TrimSpace(pair); pair == "" {
+			continue
+		}
+		tenant, topic, ok := strings.Cut(pair, "=")
+		tenant, topic = strings.TrimSpace(tenant), strings.TrimSpace(topic)
+		if !ok || tenant == "" || topic == "" {
+			return nil, fmt.Errorf("invalid tenant topic %q, expected TENANT=TOPIC", pair)
+		}
+		if _, dup := topics[tenant]; dup {
+			return nil, fmt.Errorf("duplicate topic for tenant %q", tenant)
+		}
+		topics[tenant] = topic
+	}
+	return topics, nil
+}
+
+func parseStartOffset(s string) (int64, error) {
+	switch strings.ToLower(s) {
+	case "", StartOffsetFirst:
+		return kafka.FirstOffset, nil
+	case StartOffsetLast:
+		return kafka.LastOffset, nil
+	default:
+		return 0, fmt.Errorf("unknown kafka start offset %q, expected %q or %q", s, StartOffsetFirst, StartOffsetLast)
+	}
+}
+
+func (c TLSConfig) build() (*tls.Config, error) {
+	if !c.Enabled {
+		return nil, nil
+	}
+	tlsCfg := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: c.InsecureSkipVerify,
+	}
+	if c.CAFile != "" {
+		pem, err := os.ReadFile(c.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("could not read kafka CA file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in kafka CA file %s", c.CAFile)
+		}
+		tlsCfg.RootCAs = pool
+	}
+	if c.CertFile != "" || c.KeyFile != "" {
+		if c.CertFile == "" || c.KeyFile == "" {
+			return nil, errors.New("both kafka TLS cert and key files must be set")
+		}
+		cert, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("could not load kafka client certificate: %w", err)
+		}
+		tlsCfg.Certificates = []tls.Certificate{cert}
+	}
+	return tlsCfg, nil
+}
+
+func (c SASLConfig) build() (sasl.Mechanism, error) {
+	switch strings.ToUpper(c.Mechanism) {
+	case "":
+		return nil, nil
+	case SASLPlain:
+		return plain.Mechanism{Username: c.Username, Password: c.Password}, nil
+	case SASLScramSHA256:
+		return scram.Mechanism(scram.SHA256, c.Username, c.Password)
+	case SASLScramSHA512:
+		return scram.Mechanism(scram.SHA512, c.Username, c.Password)
+	default:
+		return nil, fmt.Errorf("unsupported kafka SASL mechanism %q", c.Mechanism)
+	}
+}
+
+// Dialer возвращает dialer с настроенными TLS и SASL для ридеров.
+func (c Config) Dialer() (*kafka.Dialer, error) {
+	tlsCfg, err := c.TLS.build()
+	if err != nil {
+		return nil, err
+	}
+	mechanism, err := c.SASL.build()
+	if err != nil {
+		return nil, err
+	}
+	return &kafka.Dialer{
+		Timeout:       10 * time.Second,
+		DualStack:     true,
+		TLS:           tlsCfg,
+		SASLMechanism: mechanism,
+	}, nil
+}
+
+// Transport возвращает транспорт с теми же TLS и SASL для писателей и
+// административного клиента.
+func (c Config) Transport() (*kafka.Transport, error) {
+	tlsCfg, err := c.TLS.build()
+	if err != nil {
+		return nil, err
+	}
+	mechanism, err := c.SASL.build()
+	if err != nil {
+		return nil, err
+	}
+	return &kafka.Transport{
+		TLS:  tlsCfg,
+		SASL: mechanism,
+	}, nil
+}
+
+// groupID — группа, в которую вступает консьюмер. В режиме dry-run это
+// отдельная группа: она не отнимает партиции у рабочих инстансов, а без
+// коммитов каждый запуск начинается со StartOffset.
+func (c Config) groupID() string {
+	if c.DryRun {
+		return c.GroupID + "-dry-run"
+	}
+	return c.GroupID
+}
+
+func (c Config) readerConfig() (kafka.ReaderConfig, error) {
+	dialer, err := c.Dialer()
+	if err != nil {
+		return kafka.ReaderConfig{}, err
+	}
+	startOffset, err := parseStartOffset(c.StartOffset)
+	if err != nil {
+		return kafka.ReaderConfig{}, err
+	}
+	rc := kafka.ReaderConfig{
+		Brokers:        c.Brokers,
+		GroupID:        c.groupID(),
+		Dialer:         dialer,
+		MinBytes:       c.MinBytes,
+		MaxBytes:       c.MaxBytes,
+		MaxWait:        c.MaxWait,
+		StartOffset:    startOffset,
+		SessionTimeout: c.SessionTimeout,
+	}
+	if topics := c.Topics(); len(topics) == 1 {
+		rc.Topic = topics[0]
+	} else {
+		rc.GroupTopics = topics
+	}
+	return rc, nil
+}
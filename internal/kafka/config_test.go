@@ -0,0 +1,86 @@
+package kafka
+
+import (
+	"testing"
+
+	kafka "github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfig_Validate(t *testing.T) {
+	valid := Config{Brokers: []string{"localhost:9092"}, Topic: "orders", GroupID: "group"}
+	assert.NoError(t, valid.Validate())
+
+	noBrokers := valid
+	noBrokers.Brokers = ParseBrokers(" , ")
+	assert.Error(t, noBrokers.Validate())
+
+	badOffset := valid
+	badOffset.StartOffset = "middle"
+	assert.Error(t, badOffset.Validate())
+
+	badBytes := valid
+	badBytes.MinBytes, badBytes.MaxBytes = 10e6, 1e6
+	assert.Error(t, badBytes.Validate())
+
+	sharedTenantTopic := valid
+	sharedTenantTopic.TenantTopics = map[string]string{"WBIL": "orders"}
+	assert.Error(t, sharedTenantTopic.Validate())
+}
+
+func TestParseTenantTopics(t *testing.T) {
+	topics, err := ParseTenantTopics(" WBIL=orders-wbil, OZON = orders-ozon ,")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"WBIL": "orders-wbil", "OZON": "orders-ozon"}, topics)
+
+	cfg := Config{Topic: "orders", TenantTopics: topics}
+	assert.Equal(t, []string{"orders", "orders-ozon", "orders-wbil"}, cfg.Topics())
+	assert.Equal(t, "OZON", cfg.TenantOf("orders-ozon"))
+	assert.Equal(t, "", cfg.TenantOf("orders"))
+
+	for _, bad := range []string{"WBIL", "=orders", "WBIL=a,WBIL=b"} {
+		_, err := ParseTenantTopics(bad)
+		assert.Error(t, err, bad)
+	}
+}
+
+func TestConfig_Dialer(t *testing.T) {
+	t.Run("sasl mechanisms", func(t *testing.T) {
+		for _, mechanism := range []string{SASLPlain, SASLScramSHA256, "scram-sha-512"} {
+			cfg := Config{SASL: SASLConfig{Mechanism: mechanism, Username: "user", Password: "secret"}}
+			dialer, err := cfg.Dialer()
+			assert.NoError(t, err)
+			assert.NotNil(t, dialer.SASLMechanism)
+		}
+	})
+
+	t.Run("unknown sasl mechanism", func(t *testing.T) {
+		_, err := Config{SASL: SASLConfig{Mechanism: "GSSAPI"}}.Dialer()
+		assert.Error(t, err)
+	})
+
+	t.Run("tls with skip verify", func(t *testing.T) {
+		dialer, err := Config{TLS: TLSConfig{Enabled: true, InsecureSkipVerify: true}}.Dialer()
+		assert.NoError(t, err)
+		assert.True(t, dialer.TLS.InsecureSkipVerify)
+	})
+
+	t.Run("tls with missing key", func(t *testing.T) {
+		_, err := Config{TLS: TLSConfig{Enabled: true, CertFile: "client.pem"}}.Dialer()
+		assert.Error(t, err)
+	})
+}
+
+func TestConfig_readerConfig(t *testing.T) {
+	cfg := Config{Brokers: []string{"b1", "b2"}, Topic: "orders", GroupID: "group", StartOffset: StartOffsetLast}
+	rc, err := cfg.readerConfig()
+	assert.NoError(t, err)
+	assert.Equal(t, kafka.LastOffset, rc.StartOffset)
+	assert.Equal(t, []string{"b1", "b2"}, rc.Brokers)
+	assert.Equal(t, "group", rc.GroupID)
+
+	cfg.DryRun = true
+	rc, err = cfg.readerConfig()
+	assert.NoError(t, err)
+	assert.Equal(t, "group-dry-run", rc.GroupID)
+}
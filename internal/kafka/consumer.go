@@ -3,105 +3,248 @@ package kafka
 
 import (
 	"context"
-	"encoding/json"
-	"errors"
 	"log"
-	"orderkeeper/internal/models"
+	"log/slog"
 	"orderkeeper/internal/service"
-	"strings"
+	"sync"
+	"sync/atomic"
 
 	kafka "github.com/segmentio/kafka-go"
 )
 
+const defaultConcurrency = 1
+
 type Consumer struct {
-	reader *kafka.Reader
-	svc    service.OrderService
+	cfg Config
+	// groupID — группа, в которой состоит ридер; в режиме dry-run она
+	// отличается от cfg.GroupID.
+	groupID      string
+	readerConfig kafka.ReaderConfig
+	reader       *kafka.Reader
+	client       *kafka.Client
+	registry     *Registry
+	decoder      *PayloadDecoder
+	// dlq пишет необработанные сообщения в cfg.DLQTopic; nil без DLQ и в
+	// режиме dry-run.
+	dlq         *kafka.Writer
+	concurrency atomic.Int32
+	reconfigure chan struct{}
+	report      *ValidationReport
+
+	// ops выполняются циклом Run между остановкой и перезапуском пула
+	// воркеров, когда ридер никем не используется.
+	ops     chan adminOp
+	adminMu sync.Mutex
+
+	pauseMu sync.Mutex
+	paused  bool
+	resumed chan struct{}
 }
 
-func NewConsumer(
-	brokers []string,
-	topic string,
-	groupID string,
-	svc service.OrderService,
-) *Consumer {
-	log.Printf("Initializing Kafka consumer with brokers: %v, topic: %s, groupID: %s", brokers, topic, groupID)
-	return &Consumer{
-		reader: kafka.NewReader(kafka.ReaderConfig{
-			Brokers: brokers,
-			Topic:   topic,
-			GroupID: groupID,
-		}),
-		svc: svc,
+func NewConsumer(cfg Config, svc service.OrderService) (*Consumer, error) {
+	log.Printf("Initializing Kafka consumer with brokers: %v, topics: %v, groupID: %s, TLS: %t, SASL: %s",
+		cfg.Brokers, cfg.Topics(), cfg.GroupID, cfg.TLS.Enabled, cfg.SASL.Mechanism)
+	readerConfig, err := cfg.readerConfig()
+	if err != nil {
+		return nil, err
+	}
+	if cfg.DryRun {
+		log.Printf("Kafka consumer is in dry-run mode: group %s, nothing is written or committed", readerConfig.GroupID)
 	}
+	transport, err := cfg.Transport()
+	if err != nil {
+		return nil, err
+	}
+	c := &Consumer{
+		cfg:          cfg,
+		groupID:      readerConfig.GroupID,
+		readerConfig: readerConfig,
+		reader:       kafka.NewReader(readerConfig),
+		client:       &kafka.Client{Addr: kafka.TCP(cfg.Brokers...), Transport: transport},
+		registry:     NewServiceRegistry(cfg, svc),
+		decoder:      NewPayloadDecoder(cfg.SchemaRegistry),
+		reconfigure:  make(chan struct{}, 1),
+		ops:          make(chan adminOp),
+		resumed:      make(chan struct{}),
+	}
+	close(c.resumed)
+	if cfg.DryRun {
+		c.report = NewValidationReport()
+	} else if cfg.DLQTopic != "" {
+		if c.dlq, err = newDLQWriter(cfg); err != nil {
+			return nil, err
+		}
+	}
+	c.concurrency.Store(defaultConcurrency)
+	return c, nil
 }
 
+// SetConcurrency меняет число обработчиков сообщений. Работающий консьюмер
+// дожидается обработки уже полученных сообщений и перезапускает пул.
+func (c *Consumer) SetConcurrency(n int) {
+	if n < 1 {
+		n = 1
+	}
+	if int(c.concurrency.Swap(int32(n))) == n {
+		return
+	}
+	select {
+	case c.reconfigure <- struct{}{}:
+	default:
+	}
+}
+
+func (c *Consumer) Concurrency() int {
+	return int(c.concurrency.Load())
+}
+
+// handleMessage передает сообщение зарегистрированному обработчику.
+// Сообщения, которые не удалось обработать, логируются и пишутся в DLQ, если
+// он настроен, а затем пропускаются.
 func (c *Consumer) handleMessage(ctx context.Context, msg kafka.Message) {
-	log.Printf("Message received on topic %s, partition %d, offset %d", msg.Topic, msg.Partition, msg.Offset)
+	slog.Debug("Message received", "topic", msg.Topic, "partition", msg.Partition, "offset", msg.Offset)
 
-	var order models.Order
-	if err := json.Unmarshal(msg.Value, &order); err != nil {
-		log.Printf("Failed to unmarshal order: %v. Message: %s. Skipping message.", err, string(msg.Value))
-		if err := c.reader.CommitMessages(ctx, msg); err != nil {
-			log.Printf("Failed to commit bad message: %v", err)
+	if c.report != nil {
+		err := c.dispatch(WithDryRun(ctx), msg)
+		c.report.Record(MessageRef{Topic: msg.Topic, Partition: msg.Partition, Offset: msg.Offset}, err)
+		if err != nil {
+			slog.Debug("Dry-run validation failed", "topic", msg.Topic, "partition", msg.Partition, "offset", msg.Offset, "error", err)
 		}
 		return
 	}
 
-	if err := c.svc.CreateOrder(order); err != nil {
-		log.Printf("Failed to process order '%s': %v. Skipping message.", order.OrderUID, err)
-		if err := c.reader.CommitMessages(ctx, msg); err != nil {
-			log.Printf("Failed to commit bad message: %v", err)
+	if err := c.dispatch(ctx, msg); err != nil {
+		log.Printf("Failed to handle message on topic %s, partition %d, offset %d: %v. Message: %q. Skipping message.",
+			msg.Topic, msg.Partition, msg.Offset, err, msg.Value)
+		if c.dlq != nil {
+			if err := c.dlq.WriteMessages(ctx, deadLetter(msg, err)); err != nil {
+				log.Printf("Failed to write message from topic %s, partition %d, offset %d to DLQ: %v",
+					msg.Topic, msg.Partition, msg.Offset, err)
+			}
 		}
-		return
 	}
 
-	log.Printf("Order '%s' processed and saved successfully.", order.OrderUID)
-
 	if err := c.reader.CommitMessages(ctx, msg); err != nil {
-		log.Printf("Failed to commit message for order '%s': %v", order.OrderUID, err)
+		log.Printf("Failed to commit message on topic %s, partition %d, offset %d: %v", msg.Topic, msg.Partition, msg.Offset, err)
 	}
 }
 
+func (c *Consumer) dispatch(ctx context.Context, msg kafka.Message) error {
+	value, err := c.decoder.Decode(ctx, msg)
+	if err != nil {
+		return withRule(RuleDecode, err)
+	}
+	msg.Value = value
+
+	h, err := c.registry.Resolve(msg)
+	if err != nil {
+		return withRule(RuleRouting, err)
+	}
+	return h.Handle(WithTenant(ctx, c.cfg.TenantOf(msg.Topic)), msg)
+}
+
 func (c *Consumer) Run(ctx context.Context) {
-	defer c.reader.Close()
+	defer func() {
+		c.reader.Close()
+		if c.dlq != nil {
+			c.dlq.Close()
+		}
+	}()
 	log.Println("Kafka consumer is running and waiting for messages...")
 
-	for {
-		select {
-		case <-ctx.Done():
-			log.Println("Stopping Kafka consumer due to context cancellation")
-			return
-		default:
-			msg, err := c.reader.FetchMessage(ctx)
-			if err != nil {
-				if errors.Is(err, context.Canceled) {
-					return
-				}
-				log.Printf("Error fetching message: %v", err)
-				continue
-			}
-			c.handleMessage(ctx, msg)
+	for ctx.Err() == nil {
+		if op := c.runWorkers(ctx); op != nil {
+			op.done <- op.fn()
 		}
 	}
+	log.Println("Stopping Kafka consumer due to context cancellation")
 }
 
-func InitKafkaConsumer(brokersStr, topic, groupID string, orderService service.OrderService) (*Consumer, error) {
-	if brokersStr == "" {
-		return nil, errors.New("kafka brokers string is not set")
+// runWorkers обрабатывает сообщения пулом из Concurrency() воркеров и
+// возвращается при отмене ctx, изменении числа воркеров или поступлении
+// административной операции, которую нужно выполнить без воркеров.
+// Сообщения распределяются по номеру партиции, чтобы коммиты внутри
+// партиции оставались упорядоченными.
+func (c *Consumer) runWorkers(ctx context.Context) *adminOp {
+	n := c.Concurrency()
+	fetchCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	pending := make(chan adminOp, 1)
+	watcherDone := make(chan struct{})
+	go func() {
+		defer close(watcherDone)
+		select {
+		case <-c.reconfigure:
+			cancel()
+		case op := <-c.ops:
+			pending <- op
+			cancel()
+		case <-fetchCtx.Done():
+		}
+	}()
+
+	queues := make([]chan kafka.Message, n)
+	var wg sync.WaitGroup
+	for i := range queues {
+		queues[i] = make(chan kafka.Message)
+		wg.Add(1)
+		go func(queue <-chan kafka.Message) {
+			defer wg.Done()
+			for msg := range queue {
+				c.handleMessage(ctx, msg)
+			}
+		}(queues[i])
+	}
+
+	log.Printf("Kafka consumer started with %d worker(s)", n)
+	c.fetchLoop(fetchCtx, queues)
+
+	cancel()
+	<-watcherDone
+	for _, queue := range queues {
+		close(queue)
 	}
-	if topic == "" {
-		return nil, errors.New("kafka topic is not set")
+	wg.Wait()
+
+	select {
+	case op := <-pending:
+		return &op
+	default:
+		return nil
 	}
-	if groupID == "" {
-		return nil, errors.New("kafka groupID is not set")
+}
+
+func (c *Consumer) fetchLoop(ctx context.Context, queues []chan kafka.Message) {
+	for {
+		if !c.waitResumed(ctx) {
+			return
+		}
+		msg, err := c.reader.FetchMessage(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			log.Printf("Error fetching message: %v", err)
+			continue
+		}
+		queues[msg.Partition%len(queues)] <- msg
 	}
+}
 
-	brokers := strings.Split(brokersStr, ",")
+// ValidationReport возвращает отчет dry-run или nil, если консьюмер
+// работает в обычном режиме.
+func (c *Consumer) ValidationReport() *ValidationSummary {
+	if c.report == nil {
+		return nil
+	}
+	summary := c.report.Summary()
+	return &summary
+}
 
-	return NewConsumer(
-		brokers,
-		topic,
-		groupID,
-		orderService,
-	), nil
+func InitKafkaConsumer(cfg Config, orderService service.OrderService) (*Consumer, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return NewConsumer(cfg, orderService)
 }
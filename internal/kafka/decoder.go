@@ -0,0 +1,293 @@
+package kafka
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"orderkeeper/internal/schemaregistry"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/hamba/avro/v2"
+	kafka "github.com/segmentio/kafka-go"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+const (
+	ContentTypeHeader = "content-type"
+	// SchemaIDHeader задает схему для бинарных сообщений без wire format
+	// Confluent. Без него берется последняя схема subject "<topic>-value".
+	SchemaIDHeader = "schema-id"
+
+	ContentTypeJSON     = "application/json"
+	ContentTypeAvro     = "application/avro"
+	ContentTypeProtobuf = "application/x-protobuf"
+
+	wireFormatMagicByte = 0x00
+)
+
+var ErrNoSchemaRegistry = errors.New("binary payload received but no schema registry is configured")
+
+// PayloadDecoder приводит тело сообщения к JSON, который понимают
+// обработчики. Формат задает заголовок content-type, а без него — magic
+// byte wire format Confluent; иначе тело считается JSON. Тело с
+// content-type всегда читается без wire format: Avro вполне может начинаться
+// с нулевого байта.
+type PayloadDecoder struct {
+	registry schemaregistry.Client
+
+	mu       sync.Mutex
+	avro     map[int]avro.Schema
+	protobuf map[int]*protoSchema
+}
+
+type protoSchema struct {
+	main  protoreflect.FileDescriptor
+	named protoreflect.MessageDescriptor
+}
+
+func NewPayloadDecoder(registry schemaregistry.Client) *PayloadDecoder {
+	return &PayloadDecoder{
+		registry: registry,
+		avro:     make(map[int]avro.Schema),
+		protobuf: make(map[int]*protoSchema),
+	}
+}
+
+func (d *PayloadDecoder) Decode(ctx context.Context, msg kafka.Message) ([]byte, error) {
+	contentType := strings.ToLower(headerValue(msg, ContentTypeHeader))
+	value := msg.Value
+
+	if contentType == "" && len(value) > 5 && value[0] == wireFormatMagicByte {
+		id := int(binary.BigEndian.Uint32(value[1:5]))
+		schema, err := d.schemaByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		return d.decodeWithSchema(schema, value[5:], true)
+	}
+
+	switch {
+	case contentType == "" || strings.Contains(contentType, "json"):
+		return value, nil
+	case strings.Contains(contentType, "avro"), strings.Contains(contentType, "protobuf"):
+		schema, err := d.schemaForMessage(ctx, msg)
+		if err != nil {
+			return nil, err
+		}
+		wantType := schemaregistry.TypeAvro
+		if strings.Contains(contentType, "protobuf") {
+			wantType = schemaregistry.TypeProtobuf
+		}
+		if schema.Type != wantType {
+			return nil, fmt.Errorf("content type %q does not match %s schema %d", contentType, schema.Type, schema.ID)
+		}
+		return d.decodeWithSchema(schema, value, false)
+	default:
+		return nil, fmt.Errorf("unsupported content type %q", contentType)
+	}
+}
+
+func (d *PayloadDecoder) schemaByID(ctx context.Context, id int) (schemaregistry.Schema, error) {
+	if d.registry == nil {
+		return schemaregistry.Schema{}, ErrNoSchemaRegistry
+	}
+	return d.registry.SchemaByID(ctx, id)
+}
+
+func (d *PayloadDecoder) schemaForMessage(ctx context.Context, msg kafka.Message) (schemaregistry.Schema, error) {
+	if d.registry == nil {
+		return schemaregistry.Schema{}, ErrNoSchemaRegistry
+	}
+	if raw := headerValue(msg, SchemaIDHeader); raw != "" {
+		id, err := strconv.Atoi(raw)
+		if err != nil {
+			return schemaregistry.Schema{}, fmt.Errorf("invalid %s header %q", SchemaIDHeader, raw)
+		}
+		return d.registry.SchemaByID(ctx, id)
+	}
+	return d.registry.LatestBySubject(ctx, msg.Topic+"-value")
+}
+
+func (d *PayloadDecoder) decodeWithSchema(schema schemaregistry.Schema, data []byte, wireFormat bool) ([]byte, error) {
+	switch schema.Type {
+	case schemaregistry.TypeJSON:
+		return data, nil
+	case schemaregistry.TypeAvro:
+		return d.decodeAvro(schema, data)
+	case schemaregistry.TypeProtobuf:
+		var indexes []int
+		if wireFormat {
+			var err error
+			if indexes, data, err = readMessageIndexes(data); err != nil {
+				return nil, err
+			}
+		}
+		return d.decodeProtobuf(schema, indexes, data)
+	default:
+		return nil, fmt.Errorf("unsupported schema type %q", schema.Type)
+	}
+}
+
+func (d *PayloadDecoder) decodeAvro(schema schemaregistry.Schema, data []byte) ([]byte, error) {
+	d.mu.Lock()
+	parsed, ok := d.avro[schema.ID]
+	if !ok {
+		var err error
+		if parsed, err = avro.Parse(string(schema.Definition)); err != nil {
+			d.mu.Unlock()
+			return nil, fmt.Errorf("invalid avro schema %d: %w", schema.ID, err)
+		}
+		d.avro[schema.ID] = parsed
+	}
+	d.mu.Unlock()
+
+	var v any
+	if err := avro.Unmarshal(parsed, data, &v); err != nil {
+		return nil, fmt.Errorf("failed to decode avro payload with schema %d: %w", schema.ID, err)
+	}
+	return json.Marshal(v)
+}
+
+func (d *PayloadDecoder) decodeProtobuf(schema schemaregistry.Schema, indexes []int, data []byte) ([]byte, error) {
+	files, err := d.protoFiles(schema)
+	if err != nil {
+		return nil, err
+	}
+
+	desc := files.named
+	if len(indexes) > 0 || desc == nil {
+		if len(indexes) == 0 {
+			indexes = []int{0}
+		}
+		msgs := files.main.Messages()
+		for i, idx := range indexes {
+			if idx < 0 || idx >= msgs.Len() {
+				return nil, fmt.Errorf("protobuf message index %v is out of range for schema %d", indexes, schema.ID)
+			}
+			desc = msgs.Get(idx)
+			if i < len(indexes)-1 {
+				msgs = desc.Messages()
+			}
+		}
+	}
+
+	m := dynamicpb.NewMessage(desc)
+	if err := proto.Unmarshal(data, m); err != nil {
+		return nil, fmt.Errorf("failed to decode protobuf payload with schema %d: %w", schema.ID, err)
+	}
+	return json.Marshal(protoToMap(m))
+}
+
+func (d *PayloadDecoder) protoFiles(schema schemaregistry.Schema) (*protoSchema, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if files, ok := d.protobuf[schema.ID]; ok {
+		return files, nil
+	}
+
+	var set descriptorpb.FileDescriptorSet
+	if err := proto.Unmarshal(schema.Definition, &set); err != nil {
+		return nil, fmt.Errorf("invalid protobuf descriptor set %d: %w", schema.ID, err)
+	}
+	if len(set.File) == 0 {
+		return nil, fmt.Errorf("protobuf descriptor set %d is empty", schema.ID)
+	}
+	registry, err := protodesc.NewFiles(&set)
+	if err != nil {
+		return nil, fmt.Errorf("invalid protobuf descriptor set %d: %w", schema.ID, err)
+	}
+
+	// protoc кладет основной файл последним, после зависимостей.
+	main, err := registry.FindFileByPath(set.File[len(set.File)-1].GetName())
+	if err != nil {
+		return nil, err
+	}
+	files := &protoSchema{main: main}
+	if schema.Message != "" {
+		desc, err := registry.FindDescriptorByName(protoreflect.FullName(schema.Message))
+		if err != nil {
+			return nil, fmt.Errorf("protobuf message %q not found in schema %d: %w", schema.Message, schema.ID, err)
+		}
+		md, ok := desc.(protoreflect.MessageDescriptor)
+		if !ok {
+			return nil, fmt.Errorf("%q in schema %d is not a message", schema.Message, schema.ID)
+		}
+		files.named = md
+	}
+	d.protobuf[schema.ID] = files
+	return files, nil
+}
+
+// readMessageIndexes разбирает индексы сообщения Protobuf из wire format
+// Confluent: число индексов и сами индексы в zigzag varint. Одиночный
+// нулевой байт означает первое сообщение файла.
+func readMessageIndexes(data []byte) ([]int, []byte, error) {
+	count, n := binary.Varint(data)
+	if n <= 0 || count < 0 || count > int64(len(data)) {
+		return nil, nil, errors.New("invalid protobuf message indexes")
+	}
+	data = data[n:]
+	if count == 0 {
+		return []int{0}, data, nil
+	}
+	indexes := make([]int, count)
+	for i := range indexes {
+		idx, n := binary.Varint(data)
+		if n <= 0 {
+			return nil, nil, errors.New("invalid protobuf message indexes")
+		}
+		indexes[i] = int(idx)
+		data = data[n:]
+	}
+	return indexes, data, nil
+}
+
+// protoToMap переводит сообщение в map с исходными именами полей. В отличие
+// от protojson, 64-битные числа остаются числами, а не строками.
+func protoToMap(m protoreflect.Message) map[string]any {
+	out := make(map[string]any)
+	m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		switch {
+		case fd.IsList():
+			list := v.List()
+			values := make([]any, list.Len())
+			for i := range values {
+				values[i] = protoValue(fd, list.Get(i))
+			}
+			out[string(fd.Name())] = values
+		case fd.IsMap():
+			values := make(map[string]any)
+			v.Map().Range(func(k protoreflect.MapKey, mv protoreflect.Value) bool {
+				values[k.String()] = protoValue(fd.MapValue(), mv)
+				return true
+			})
+			out[string(fd.Name())] = values
+		default:
+			out[string(fd.Name())] = protoValue(fd, v)
+		}
+		return true
+	})
+	return out
+}
+
+func protoValue(fd protoreflect.FieldDescriptor, v protoreflect.Value) any {
+	switch fd.Kind() {
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		return protoToMap(v.Message())
+	case protoreflect.EnumKind:
+		if ev := fd.Enum().Values().ByNumber(v.Enum()); ev != nil {
+			return string(ev.Name())
+		}
+		return int32(v.Enum())
+	default:
+		return v.Interface()
+	}
+}
@@ -0,0 +1,169 @@
+package kafka
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"orderkeeper/internal/schemaregistry"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hamba/avro/v2"
+	kafka "github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+const cancellationAvroSchema = `{
+	"type": "record",
+	"name": "Cancellation",
+	"fields": [
+		{"name": "order_uid", "type": "string"},
+		{"name": "reason", "type": ["null", "string"]}
+	]
+}`
+
+func cancellationDescriptorSet(t *testing.T) *descriptorpb.FileDescriptorSet {
+	t.Helper()
+	return &descriptorpb.FileDescriptorSet{File: []*descriptorpb.FileDescriptorProto{{
+		Name:    proto.String("cancellation.proto"),
+		Package: proto.String("orderkeeper"),
+		Syntax:  proto.String("proto3"),
+		MessageType: []*descriptorpb.DescriptorProto{{
+			Name: proto.String("Cancellation"),
+			Field: []*descriptorpb.FieldDescriptorProto{
+				{Name: proto.String("order_uid"), Number: proto.Int32(1), Type: descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(), Label: descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum()},
+				{Name: proto.String("reason"), Number: proto.Int32(2), Type: descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(), Label: descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum()},
+				{Name: proto.String("amount"), Number: proto.Int32(3), Type: descriptorpb.FieldDescriptorProto_TYPE_INT64.Enum(), Label: descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum()},
+			},
+		}},
+	}}}
+}
+
+func newFileRegistry(t *testing.T) schemaregistry.Client {
+	t.Helper()
+	dir := t.TempDir()
+
+	set, err := proto.Marshal(cancellationDescriptorSet(t))
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "cancellation.pb"), set, 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "cancellation.avsc"), []byte(cancellationAvroSchema), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, schemaregistry.IndexFile), []byte(`[
+		{"id": 1, "subject": "cancellations-value", "version": 1, "type": "AVRO", "file": "cancellation.avsc"},
+		{"id": 2, "subject": "cancellations-pb-value", "version": 1, "type": "PROTOBUF", "file": "cancellation.pb", "message": "orderkeeper.Cancellation"}
+	]`), 0o600))
+
+	client, err := schemaregistry.NewFileClient(dir)
+	require.NoError(t, err)
+	return client
+}
+
+func wireFormat(schemaID int, prefix []byte, body []byte) []byte {
+	out := []byte{wireFormatMagicByte, 0, 0, 0, 0}
+	binary.BigEndian.PutUint32(out[1:], uint32(schemaID))
+	out = append(out, prefix...)
+	return append(out, body...)
+}
+
+func TestPayloadDecoder(t *testing.T) {
+	ctx := context.Background()
+	decoder := NewPayloadDecoder(newFileRegistry(t))
+
+	t.Run("json by default", func(t *testing.T) {
+		value, err := decoder.Decode(ctx, kafka.Message{Value: []byte(`{"order_uid":"uid-1"}`)})
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{"order_uid":"uid-1"}`, string(value))
+	})
+
+	t.Run("avro by content type and subject", func(t *testing.T) {
+		schema := avro.MustParse(cancellationAvroSchema)
+		reason := "customer request"
+		body, err := avro.Marshal(schema, map[string]any{"order_uid": "uid-1", "reason": &reason})
+		require.NoError(t, err)
+
+		value, err := decoder.Decode(ctx, kafka.Message{
+			Topic:   "cancellations",
+			Value:   body,
+			Headers: []kafka.Header{{Key: ContentTypeHeader, Value: []byte(ContentTypeAvro)}},
+		})
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{"order_uid":"uid-1","reason":"customer request"}`, string(value))
+	})
+
+	t.Run("content type wins over magic byte", func(t *testing.T) {
+		schema := avro.MustParse(cancellationAvroSchema)
+		// Пустой order_uid кодируется нулевым байтом, как magic byte.
+		reason := "duplicate"
+		body, err := avro.Marshal(schema, map[string]any{"order_uid": "", "reason": &reason})
+		require.NoError(t, err)
+		require.Equal(t, byte(wireFormatMagicByte), body[0])
+
+		value, err := decoder.Decode(ctx, kafka.Message{
+			Value: body,
+			Headers: []kafka.Header{
+				{Key: ContentTypeHeader, Value: []byte(ContentTypeAvro)},
+				{Key: SchemaIDHeader, Value: []byte("1")},
+			},
+		})
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{"order_uid":"","reason":"duplicate"}`, string(value))
+	})
+
+	t.Run("avro wire format", func(t *testing.T) {
+		schema := avro.MustParse(cancellationAvroSchema)
+		body, err := avro.Marshal(schema, map[string]any{"order_uid": "uid-2", "reason": nil})
+		require.NoError(t, err)
+
+		value, err := decoder.Decode(ctx, kafka.Message{Value: wireFormat(1, nil, body)})
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{"order_uid":"uid-2","reason":null}`, string(value))
+	})
+
+	t.Run("protobuf wire format", func(t *testing.T) {
+		files, err := protodesc.NewFiles(cancellationDescriptorSet(t))
+		require.NoError(t, err)
+		desc, err := files.FindDescriptorByName("orderkeeper.Cancellation")
+		require.NoError(t, err)
+		m := dynamicpb.NewMessage(desc.(protoreflect.MessageDescriptor))
+		m.Set(m.Descriptor().Fields().ByName("order_uid"), protoreflect.ValueOfString("uid-3"))
+		m.Set(m.Descriptor().Fields().ByName("amount"), protoreflect.ValueOfInt64(1<<40))
+		body, err := proto.Marshal(m)
+		require.NoError(t, err)
+
+		value, err := decoder.Decode(ctx, kafka.Message{Value: wireFormat(2, []byte{0}, body)})
+		assert.NoError(t, err)
+
+		var decoded map[string]any
+		require.NoError(t, json.Unmarshal(value, &decoded))
+		assert.Equal(t, "uid-3", decoded["order_uid"])
+		assert.Equal(t, float64(1<<40), decoded["amount"])
+	})
+
+	t.Run("unknown schema id", func(t *testing.T) {
+		_, err := decoder.Decode(ctx, kafka.Message{Value: wireFormat(42, nil, []byte{1, 2, 3})})
+		assert.True(t, errors.Is(err, schemaregistry.ErrSchemaNotFound))
+	})
+
+	t.Run("content type does not match schema", func(t *testing.T) {
+		_, err := decoder.Decode(ctx, kafka.Message{
+			Value: []byte{1, 2, 3},
+			Headers: []kafka.Header{
+				{Key: ContentTypeHeader, Value: []byte(ContentTypeProtobuf)},
+				{Key: SchemaIDHeader, Value: []byte("1")},
+			},
+		})
+		assert.Error(t, err)
+	})
+
+	t.Run("binary payload without registry", func(t *testing.T) {
+		_, err := NewPayloadDecoder(nil).Decode(ctx, kafka.Message{Value: wireFormat(1, nil, []byte{1})})
+		assert.True(t, errors.Is(err, ErrNoSchemaRegistry))
+	})
+}
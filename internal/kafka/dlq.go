@@ -0,0 +1,42 @@
+package kafka
+
+import (
+	"slices"
+	"strconv"
+
+	kafka "github.com/segmentio/kafka-go"
+)
+
+// Заголовки, которые сообщение получает при записи в DLQ.
+const (
+	DLQErrorHeader     = "dlq-error"
+	DLQTopicHeader     = "dlq-topic"
+	DLQPartitionHeader = "dlq-partition"
+	DLQOffsetHeader    = "dlq-offset"
+)
+
+func newDLQWriter(cfg Config) (*kafka.Writer, error) {
+	transport, err := cfg.Transport()
+	if err != nil {
+		return nil, err
+	}
+	return &kafka.Writer{
+		Addr:         kafka.TCP(cfg.Brokers...),
+		Topic:        cfg.DLQTopic,
+		Balancer:     &kafka.Hash{},
+		RequiredAcks: kafka.RequireAll,
+		Transport:    transport,
+	}, nil
+}
+
+// deadLetter возвращает копию msg для DLQ: с исходными ключом, телом и
+// заголовками, а также с причиной ошибки и исходным положением в Kafka.
+func deadLetter(msg kafka.Message, cause error) kafka.Message {
+	headers := append(slices.Clone(msg.Headers),
+		kafka.Header{Key: DLQErrorHeader, Value: []byte(cause.Error())},
+		kafka.Header{Key: DLQTopicHeader, Value: []byte(msg.Topic)},
+		kafka.Header{Key: DLQPartitionHeader, Value: []byte(strconv.Itoa(msg.Partition))},
+		kafka.Header{Key: DLQOffsetHeader, Value: []byte(strconv.FormatInt(msg.Offset, 10))},
+	)
+	return kafka.Message{Key: msg.Key, Value: msg.Value, Headers: headers, Time: msg.Time}
+}
@@ -0,0 +1,29 @@
+package kafka
+
+import (
+	"errors"
+	"testing"
+
+	kafka "github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeadLetter(t *testing.T) {
+	msg := kafka.Message{
+		Topic: "order-status", Partition: 2, Offset: 41,
+		Key: []byte("uid-1"), Value: []byte(`{"order_uid":"uid-1","status":"paid"}`),
+		Headers: []kafka.Header{{Key: MessageTypeHeader, Value: []byte(MessageTypeStatusUpdate)}},
+	}
+
+	dead := deadLetter(msg, errors.New("order not found"))
+
+	assert.Empty(t, dead.Topic)
+	assert.Equal(t, msg.Key, dead.Key)
+	assert.Equal(t, msg.Value, dead.Value)
+	assert.Equal(t, MessageTypeStatusUpdate, headerValue(dead, MessageTypeHeader))
+	assert.Equal(t, "order not found", headerValue(dead, DLQErrorHeader))
+	assert.Equal(t, "order-status", headerValue(dead, DLQTopicHeader))
+	assert.Equal(t, "2", headerValue(dead, DLQPartitionHeader))
+	assert.Equal(t, "41", headerValue(dead, DLQOffsetHeader))
+	assert.Len(t, msg.Headers, 1)
+}
@@ -0,0 +1,203 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"orderkeeper/internal/dto"
+	"orderkeeper/internal/models"
+	"orderkeeper/internal/service"
+	"orderkeeper/internal/validation"
+	"strings"
+	"time"
+
+	kafka "github.com/segmentio/kafka-go"
+)
+
+type StatusUpdateMessage struct {
+	OrderUID string `json:"order_uid"`
+	Status   string `json:"status"`
+}
+
+type PaymentConfirmationMessage struct {
+	OrderUID    string `json:"order_uid"`
+	Transaction string `json:"transaction"`
+}
+
+type CancellationMessage struct {
+	OrderUID string `json:"order_uid"`
+	Reason   string `json:"reason"`
+}
+
+// Validate проверяет сообщение без обращения к БД, поэтому выполняется и в
+// dry-run.
+func (m StatusUpdateMessage) Validate() error {
+	errs := requireField(nil, "order_uid", m.OrderUID)
+	if !models.IsValidOrderStatus(m.Status) {
+		errs = append(errs, validation.FieldError{
+			Field: "status", Rule: validation.RuleStatus, Message: fmt.Sprintf("unknown order status %q", m.Status),
+		})
+	}
+	return validationError(errs)
+}
+
+func (m PaymentConfirmationMessage) Validate() error {
+	errs := requireField(nil, "order_uid", m.OrderUID)
+	errs = requireField(errs, "transaction", m.Transaction)
+	return validationError(errs)
+}
+
+func (m CancellationMessage) Validate() error {
+	return validationError(requireField(nil, "order_uid", m.OrderUID))
+}
+
+func requireField(errs validation.Errors, field, value string) validation.Errors {
+	if strings.TrimSpace(value) == "" {
+		errs = append(errs, validation.FieldError{Field: field, Rule: validation.RuleRequired, Message: "is required"})
+	}
+	return errs
+}
+
+func validationError(errs validation.Errors) error {
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+// NewServiceRegistry регистрирует обработчики всех типов сообщений,
+// которые понимает OrderKeeper, по типу и по топикам из cfg. Обработчики
+// работают с заказами площадки из контекста (см. WithTenant).
+func NewServiceRegistry(cfg Config, svc service.OrderService) *Registry {
+	handlers := map[string]MessageHandler{
+		MessageTypeOrder:               forTenant(svc, orderHandler),
+		MessageTypeStatusUpdate:        retryMissingOrder(forTenant(svc, statusUpdateHandler)),
+		MessageTypePaymentConfirmation: retryMissingOrder(forTenant(svc, paymentConfirmationHandler)),
+		MessageTypeCancellation:        retryMissingOrder(forTenant(svc, cancellationHandler)),
+	}
+
+	r := NewRegistry()
+	for messageType, h := range handlers {
+		r.HandleType(messageType, h)
+	}
+	for messageType, topic := range cfg.topicsByType() {
+		r.HandleTopic(topic, handlers[messageType])
+	}
+	for _, topic := range cfg.TenantTopics {
+		r.HandleTopic(topic, handlers[MessageTypeOrder])
+	}
+	return r
+}
+
+// forTenant строит обработчик для сервиса площадки сообщения.
+func forTenant(svc service.OrderService, build func(service.OrderService) HandlerFunc) HandlerFunc {
+	return func(ctx context.Context, msg kafka.Message) error {
+		return build(svc.ForTenant(TenantFrom(ctx))).Handle(ctx, msg)
+	}
+}
+
+// missingOrderDelays — паузы между повторами сообщения о заказе, который
+// еще не создан: заказ и изменения к нему приходят из разных топиков, и
+// изменение может обогнать сам заказ.
+var missingOrderDelays = []time.Duration{100 * time.Millisecond, 500 * time.Millisecond, time.Second, 2 * time.Second, 5 * time.Second}
+
+// retryMissingOrder повторяет h, пока он возвращает service.ErrOrderNotFound,
+// с паузами missingOrderDelays. Пока идут повторы, воркер не берет другие
+// сообщения.
+func retryMissingOrder(h HandlerFunc) HandlerFunc {
+	return func(ctx context.Context, msg kafka.Message) error {
+		err := h(ctx, msg)
+		for _, delay := range missingOrderDelays {
+			if !errors.Is(err, service.ErrOrderNotFound) {
+				return err
+			}
+			select {
+			case <-ctx.Done():
+				return err
+			case <-time.After(delay):
+			}
+			err = h(ctx, msg)
+		}
+		return err
+	}
+}
+
+func orderHandler(svc service.OrderService) HandlerFunc {
+	return func(ctx context.Context, msg kafka.Message) error {
+		order, err := dto.DecodeOrder(msg.Value, headerValue(msg, dto.VersionHeader))
+		if err != nil {
+			return withRule(RuleDecode, fmt.Errorf("failed to decode order: %w", err))
+		}
+		if IsDryRun(ctx) {
+			return svc.ValidateOrder(order)
+		}
+		if err := svc.CreateOrder(order); err != nil {
+			return fmt.Errorf("failed to process order '%s': %w", order.OrderUID, err)
+		}
+		log.Printf("Order '%s' processed and saved successfully.", order.OrderUID)
+		return nil
+	}
+}
+
+func statusUpdateHandler(svc service.OrderService) HandlerFunc {
+	return func(ctx context.Context, msg kafka.Message) error {
+		var update StatusUpdateMessage
+		if err := decodeMessage(msg, &update); err != nil {
+			return err
+		}
+		if IsDryRun(ctx) {
+			return nil
+		}
+		if err := svc.UpdateOrderStatus(update.OrderUID, update.Status); err != nil {
+			return fmt.Errorf("failed to update status of order '%s': %w", update.OrderUID, err)
+		}
+		log.Printf("Order '%s' status updated to '%s'.", update.OrderUID, update.Status)
+		return nil
+	}
+}
+
+func paymentConfirmationHandler(svc service.OrderService) HandlerFunc {
+	return func(ctx context.Context, msg kafka.Message) error {
+		var confirmation PaymentConfirmationMessage
+		if err := decodeMessage(msg, &confirmation); err != nil {
+			return err
+		}
+		if IsDryRun(ctx) {
+			return nil
+		}
+		if err := svc.ConfirmPayment(confirmation.OrderUID, confirmation.Transaction); err != nil {
+			return fmt.Errorf("failed to confirm payment of order '%s': %w", confirmation.OrderUID, err)
+		}
+		log.Printf("Payment of order '%s' confirmed.", confirmation.OrderUID)
+		return nil
+	}
+}
+
+func cancellationHandler(svc service.OrderService) HandlerFunc {
+	return func(ctx context.Context, msg kafka.Message) error {
+		var cancellation CancellationMessage
+		if err := decodeMessage(msg, &cancellation); err != nil {
+			return err
+		}
+		if IsDryRun(ctx) {
+			return nil
+		}
+		if err := svc.CancelOrder(cancellation.OrderUID, cancellation.Reason); err != nil {
+			return fmt.Errorf("failed to cancel order '%s': %w", cancellation.OrderUID, err)
+		}
+		log.Printf("Order '%s' cancelled.", cancellation.OrderUID)
+		return nil
+	}
+}
+
+func decodeMessage(msg kafka.Message, v interface{ Validate() error }) error {
+	if err := json.Unmarshal(msg.Value, v); err != nil {
+		return withRule(RuleDecode, fmt.Errorf("failed to unmarshal %s message: %w", msg.Topic, err))
+	}
+	if err := v.Validate(); err != nil {
+		return fmt.Errorf("invalid %s message: %w", msg.Topic, err)
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package kafka
+
+import (
+	"context"
+	"fmt"
+
+	kafka "github.com/segmentio/kafka-go"
+)
+
+// MessageTypeHeader — заголовок, по которому сообщение маршрутизируется,
+// если продюсер пишет разные типы сообщений в один топик.
+const MessageTypeHeader = "message-type"
+
+const (
+	MessageTypeOrder               = "order"
+	MessageTypeStatusUpdate        = "order.status"
+	MessageTypePaymentConfirmation = "payment.confirmed"
+	MessageTypeCancellation        = "order.cancelled"
+)
+
+type MessageHandler interface {
+	Handle(ctx context.Context, msg kafka.Message) error
+}
+
+type HandlerFunc func(ctx context.Context, msg kafka.Message) error
+
+func (f HandlerFunc) Handle(ctx context.Context, msg kafka.Message) error {
+	return f(ctx, msg)
+}
+
+// Registry сопоставляет сообщениям обработчики: сначала по заголовку
+// MessageTypeHeader, затем по топику.
+type Registry struct {
+	byType  map[string]MessageHandler
+	byTopic map[string]MessageHandler
+}
+
+func NewRegistry() *Registry {
+	return &Registry{
+		byType:  make(map[string]MessageHandler),
+		byTopic: make(map[string]MessageHandler),
+	}
+}
+
+func (r *Registry) HandleType(messageType string, h MessageHandler) {
+	r.byType[messageType] = h
+}
+
+func (r *Registry) HandleTopic(topic string, h MessageHandler) {
+	r.byTopic[topic] = h
+}
+
+func (r *Registry) Resolve(msg kafka.Message) (MessageHandler, error) {
+	if messageType := headerValue(msg, MessageTypeHeader); messageType != "" {
+		if h, ok := r.byType[messageType]; ok {
+			return h, nil
+		}
+		return nil, fmt.Errorf("no handler registered for message type %q", messageType)
+	}
+	if h, ok := r.byTopic[msg.Topic]; ok {
+		return h, nil
+	}
+	return nil, fmt.Errorf("no handler registered for topic %q", msg.Topic)
+}
+
+func headerValue(msg kafka.Message, key string) string {
+	for _, h := range msg.Headers {
+		if h.Key == key {
+			return string(h.Value)
+		}
+	}
+	return ""
+}
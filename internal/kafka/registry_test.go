@@ -0,0 +1,173 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"orderkeeper/internal/models"
+	"orderkeeper/internal/service"
+	"orderkeeper/internal/service/mocks"
+	"testing"
+	"time"
+
+	kafka "github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestRegistry_Resolve(t *testing.T) {
+	var called string
+	handler := func(name string) HandlerFunc {
+		return func(context.Context, kafka.Message) error {
+			called = name
+			return nil
+		}
+	}
+
+	r := NewRegistry()
+	r.HandleTopic("orders", handler("orders-topic"))
+	r.HandleType(MessageTypeCancellation, handler("cancellation-type"))
+
+	t.Run("by topic", func(t *testing.T) {
+		h, err := r.Resolve(kafka.Message{Topic: "orders"})
+		assert.NoError(t, err)
+		assert.NoError(t, h.Handle(context.Background(), kafka.Message{}))
+		assert.Equal(t, "orders-topic", called)
+	})
+
+	t.Run("header takes precedence over topic", func(t *testing.T) {
+		msg := kafka.Message{
+			Topic:   "orders",
+			Headers: []kafka.Header{{Key: MessageTypeHeader, Value: []byte(MessageTypeCancellation)}},
+		}
+		h, err := r.Resolve(msg)
+		assert.NoError(t, err)
+		assert.NoError(t, h.Handle(context.Background(), msg))
+		assert.Equal(t, "cancellation-type", called)
+	})
+
+	t.Run("unknown type", func(t *testing.T) {
+		_, err := r.Resolve(kafka.Message{
+			Topic:   "orders",
+			Headers: []kafka.Header{{Key: MessageTypeHeader, Value: []byte("refund")}},
+		})
+		assert.Error(t, err)
+	})
+
+	t.Run("unknown topic", func(t *testing.T) {
+		_, err := r.Resolve(kafka.Message{Topic: "refunds"})
+		assert.Error(t, err)
+	})
+}
+
+func TestNewServiceRegistry(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mocks.NewMockOrderService(ctrl)
+	mockService.EXPECT().ForTenant("").Return(mockService).AnyTimes()
+	cfg := Config{
+		Topic: "orders", StatusTopic: "order-status", PaymentTopic: "payments", CancellationTopic: "cancellations",
+		TenantTopics: map[string]string{"WBIL": "orders-wbil"},
+	}
+	r := NewServiceRegistry(cfg, mockService)
+
+	handle := func(topic, value string) error {
+		msg := kafka.Message{Topic: topic, Value: []byte(value)}
+		h, err := r.Resolve(msg)
+		if err != nil {
+			return err
+		}
+		return h.Handle(context.Background(), msg)
+	}
+
+	t.Run("order", func(t *testing.T) {
+		mockService.EXPECT().CreateOrder(gomock.Cond(func(o models.Order) bool {
+			return o.OrderUID == "uid-1" && o.Status == models.OrderStatusCreated
+		})).Return(nil)
+		assert.NoError(t, handle("orders", `{"order_uid":"uid-1"}`))
+	})
+
+	t.Run("status update", func(t *testing.T) {
+		mockService.EXPECT().UpdateOrderStatus("uid-1", models.OrderStatusShipped).Return(nil)
+		assert.NoError(t, handle("order-status", `{"order_uid":"uid-1","status":"shipped"}`))
+	})
+
+	t.Run("payment confirmation", func(t *testing.T) {
+		mockService.EXPECT().ConfirmPayment("uid-1", "tx-1").Return(service.ErrTransactionMismatch)
+		err := handle("payments", `{"order_uid":"uid-1","transaction":"tx-1"}`)
+		assert.True(t, errors.Is(err, service.ErrTransactionMismatch))
+	})
+
+	t.Run("missing order is retried", func(t *testing.T) {
+		defer func(delays []time.Duration) { missingOrderDelays = delays }(missingOrderDelays)
+		missingOrderDelays = []time.Duration{time.Millisecond, time.Millisecond}
+		gomock.InOrder(
+			mockService.EXPECT().UpdateOrderStatus("uid-9", models.OrderStatusPaid).Return(service.ErrOrderNotFound),
+			mockService.EXPECT().UpdateOrderStatus("uid-9", models.OrderStatusPaid).Return(nil),
+		)
+		assert.NoError(t, handle("order-status", `{"order_uid":"uid-9","status":"paid"}`))
+
+		mockService.EXPECT().CancelOrder("uid-9", "").Return(service.ErrOrderNotFound).Times(3)
+		err := handle("cancellations", `{"order_uid":"uid-9"}`)
+		assert.True(t, errors.Is(err, service.ErrOrderNotFound))
+	})
+
+	t.Run("cancellation", func(t *testing.T) {
+		mockService.EXPECT().CancelOrder("uid-1", "customer request").Return(nil)
+		assert.NoError(t, handle("cancellations", `{"order_uid":"uid-1","reason":"customer request"}`))
+	})
+
+	t.Run("dry run does not call the service", func(t *testing.T) {
+		msg := kafka.Message{Topic: "cancellations", Value: []byte(`{"order_uid":"uid-1"}`)}
+		h, err := r.Resolve(msg)
+		assert.NoError(t, err)
+		assert.NoError(t, h.Handle(WithDryRun(context.Background()), msg))
+	})
+
+	t.Run("dry run validates orders", func(t *testing.T) {
+		mockService.EXPECT().ValidateOrder(gomock.Any()).Return(errors.New("order must contain at least one item"))
+		msg := kafka.Message{Topic: "orders", Value: []byte(`{"order_uid":"uid-1"}`)}
+		h, err := r.Resolve(msg)
+		assert.NoError(t, err)
+
+		err = h.Handle(WithDryRun(context.Background()), msg)
+
+		assert.Equal(t, []string{"order must contain at least one item"}, rulesOf(err))
+	})
+
+	t.Run("dry run checks message rules", func(t *testing.T) {
+		for value, want := range map[string][]string{
+			`{"order_uid":"uid-1","status":"lost"}`: {"status:status"},
+			`{"status":"paid"}`:                     {"order_uid:required"},
+		} {
+			msg := kafka.Message{Topic: "order-status", Value: []byte(value)}
+			h, err := r.Resolve(msg)
+			assert.NoError(t, err)
+
+			assert.Equal(t, want, rulesOf(h.Handle(WithDryRun(context.Background()), msg)), value)
+		}
+
+		msg := kafka.Message{Topic: "payments", Value: []byte(`{"order_uid":"uid-1"}`)}
+		h, err := r.Resolve(msg)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"transaction:required"}, rulesOf(h.Handle(WithDryRun(context.Background()), msg)))
+	})
+
+	t.Run("missing order uid", func(t *testing.T) {
+		err := handle("cancellations", `{"reason":"customer request"}`)
+		assert.Equal(t, []string{"order_uid:required"}, rulesOf(err))
+	})
+
+	t.Run("tenant topic", func(t *testing.T) {
+		tenantService := mocks.NewMockOrderService(ctrl)
+		mockService.EXPECT().ForTenant("WBIL").Return(tenantService)
+		tenantService.EXPECT().CreateOrder(gomock.Cond(func(o models.Order) bool {
+			return o.OrderUID == "uid-2"
+		})).Return(nil)
+		msg := kafka.Message{Topic: "orders-wbil", Value: []byte(`{"order_uid":"uid-2"}`)}
+		h, err := r.Resolve(msg)
+		assert.NoError(t, err)
+
+		assert.NoError(t, h.Handle(WithTenant(context.Background(), cfg.TenantOf(msg.Topic)), msg))
+	})
+}
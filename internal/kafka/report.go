@@ -0,0 +1,160 @@
+package kafka
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"orderkeeper/internal/validation"
+	"sort"
+	"sync"
+	"time"
+)
+
+const maxSampleOffsets = 10
+
+const (
+	RuleDecode  = "decode"
+	RuleRouting = "routing"
+)
+
+type MessageRef struct {
+	Topic     string `json:"topic"`
+	Partition int    `json:"partition"`
+	Offset    int64  `json:"offset"`
+}
+
+type RuleSummary struct {
+	Rule          string       `json:"rule"`
+	Failures      int          `json:"failures"`
+	SampleOffsets []MessageRef `json:"sample_offsets"`
+}
+
+// ValidationSummary — итог dry-run: сколько сообщений прошло проверку и
+// какие правила нарушались, с примерами офсетов.
+type ValidationSummary struct {
+	StartedAt time.Time     `json:"started_at"`
+	Total     int           `json:"total"`
+	Passed    int           `json:"passed"`
+	Failed    int           `json:"failed"`
+	Rules     []RuleSummary `json:"rules"`
+}
+
+// ValidationReport накапливает результаты dry-run из нескольких воркеров.
+type ValidationReport struct {
+	mu        sync.Mutex
+	startedAt time.Time
+	total     int
+	passed    int
+	rules     map[string]*RuleSummary
+}
+
+func NewValidationReport() *ValidationReport {
+	return &ValidationReport{
+		startedAt: time.Now().UTC(),
+		rules:     make(map[string]*RuleSummary),
+	}
+}
+
+// ruleError связывает ошибку обработки с правилом, по которому она
+// учитывается в отчете.
+type ruleError struct {
+	rule string
+	err  error
+}
+
+func (e *ruleError) Error() string { return e.err.Error() }
+func (e *ruleError) Unwrap() error { return e.err }
+
+func withRule(rule string, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &ruleError{rule: rule, err: err}
+}
+
+// rulesOf возвращает правила, нарушение которых привело к err. Для ошибок
+// валидации это коды всех нарушенных правил, иначе — явно указанное правило
+// или исходная ошибка без обертки.
+func rulesOf(err error) []string {
+	var violations validation.Errors
+	if errors.As(err, &violations) {
+		seen := make(map[string]bool)
+		var rules []string
+		for _, v := range violations {
+			if code := v.Code(); !seen[code] {
+				seen[code] = true
+				rules = append(rules, code)
+			}
+		}
+		return rules
+	}
+	var re *ruleError
+	if errors.As(err, &re) {
+		return []string{re.rule}
+	}
+	for {
+		inner := errors.Unwrap(err)
+		if inner == nil {
+			return []string{err.Error()}
+		}
+		err = inner
+	}
+}
+
+func (r *ValidationReport) Record(ref MessageRef, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.total++
+	if err == nil {
+		r.passed++
+		return
+	}
+	for _, rule := range rulesOf(err) {
+		s, ok := r.rules[rule]
+		if !ok {
+			s = &RuleSummary{Rule: rule}
+			r.rules[rule] = s
+		}
+		s.Failures++
+		if len(s.SampleOffsets) < maxSampleOffsets {
+			s.SampleOffsets = append(s.SampleOffsets, ref)
+		}
+	}
+}
+
+func (r *ValidationReport) Summary() ValidationSummary {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	summary := ValidationSummary{
+		StartedAt: r.startedAt,
+		Total:     r.total,
+		Passed:    r.passed,
+		Failed:    r.total - r.passed,
+		Rules:     make([]RuleSummary, 0, len(r.rules)),
+	}
+	for _, s := range r.rules {
+		rs := *s
+		rs.SampleOffsets = append([]MessageRef(nil), s.SampleOffsets...)
+		summary.Rules = append(summary.Rules, rs)
+	}
+	sort.Slice(summary.Rules, func(i, j int) bool {
+		a, b := summary.Rules[i], summary.Rules[j]
+		return a.Failures > b.Failures || (a.Failures == b.Failures && a.Rule < b.Rule)
+	})
+	return summary
+}
+
+// WriteText печатает отчет в человекочитаемом виде.
+func (s ValidationSummary) WriteText(w io.Writer) {
+	fmt.Fprintf(w, "Dry-run validation report (since %s)\n", s.StartedAt.Format(time.RFC3339))
+	fmt.Fprintf(w, "  messages: %d, passed: %d, failed: %d\n", s.Total, s.Passed, s.Failed)
+	for _, rule := range s.Rules {
+		fmt.Fprintf(w, "  %-40s %6d  e.g.", rule.Rule, rule.Failures)
+		for _, ref := range rule.SampleOffsets {
+			fmt.Fprintf(w, " %s/%d@%d", ref.Topic, ref.Partition, ref.Offset)
+		}
+		fmt.Fprintln(w)
+	}
+}
@@ -0,0 +1,48 @@
+package kafka
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"orderkeeper/internal/validation"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidationReport(t *testing.T) {
+	report := NewValidationReport()
+	itemsRule := errors.New("order must contain at least one item")
+
+	report.Record(MessageRef{Topic: "orders", Offset: 1}, nil)
+	report.Record(MessageRef{Topic: "orders", Offset: 2}, withRule(RuleDecode, errors.New("unexpected EOF")))
+	for i := 0; i < maxSampleOffsets+2; i++ {
+		report.Record(MessageRef{Topic: "orders", Offset: int64(10 + i)}, fmt.Errorf("failed to process order: %w", itemsRule))
+	}
+
+	summary := report.Summary()
+
+	assert.Equal(t, 14, summary.Total)
+	assert.Equal(t, 1, summary.Passed)
+	assert.Equal(t, 13, summary.Failed)
+	assert.Len(t, summary.Rules, 2)
+	assert.Equal(t, itemsRule.Error(), summary.Rules[0].Rule)
+	assert.Equal(t, maxSampleOffsets+2, summary.Rules[0].Failures)
+	assert.Len(t, summary.Rules[0].SampleOffsets, maxSampleOffsets)
+	assert.Equal(t, RuleDecode, summary.Rules[1].Rule)
+
+	var buf bytes.Buffer
+	summary.WriteText(&buf)
+	assert.Contains(t, buf.String(), "messages: 14, passed: 1, failed: 13")
+	assert.Contains(t, buf.String(), "orders/0@2")
+}
+
+func TestRulesOf_ValidationErrors(t *testing.T) {
+	err := fmt.Errorf("failed to process order: %w", validation.Errors{
+		{Field: "items[0].price", Rule: validation.RulePositive},
+		{Field: "items[2].price", Rule: validation.RulePositive},
+		{Field: "delivery.email", Rule: validation.RuleEmail},
+	})
+
+	assert.Equal(t, []string{"items[].price:positive", "delivery.email:email"}, rulesOf(err))
+}
@@ -0,0 +1,191 @@
+// Package loadgen генерирует правдоподобные валидные и невалидные заказы и
+// подает их в Kafka с заданной частотой, измеряя время до появления заказа
+// в API.
+package loadgen
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"orderkeeper/internal/models"
+	"orderkeeper/internal/money"
+	"strings"
+	"time"
+)
+
+// Sample — сгенерированный заказ. Defect — код правила валидации
+// ("поле:правило"), которое заказ нарушает, или пустая строка для валидного.
+type Sample struct {
+	Order  models.Order
+	Defect string
+}
+
+func (s Sample) Valid() bool { return s.Defect == "" }
+
+// Generator выдает одну и ту же последовательность заказов для одного seed и
+// start. Не потокобезопасен.
+type Generator struct {
+	rng         *rand.Rand
+	prefix      string
+	start       time.Time
+	invalidRate float64
+}
+
+// NewGenerator создает генератор. Доля невалидных заказов invalidRate — от 0
+// до 1; order_uid начинаются с prefix; даты создания приходятся на 30 дней
+// до start.
+func NewGenerator(seed uint64, prefix string, start time.Time, invalidRate float64) *Generator {
+	return &Generator{
+		rng:         rand.New(rand.NewPCG(seed, seed^0x9e3779b97f4a7c15)),
+		prefix:      prefix,
+		start:       start.UTC(),
+		invalidRate: invalidRate,
+	}
+}
+
+func (g *Generator) Next() Sample {
+	order := g.order()
+	if g.rng.Float64() >= g.invalidRate {
+		return Sample{Order: order}
+	}
+	d := defects[g.rng.IntN(len(defects))]
+	d.apply(&order)
+	return Sample{Order: order, Defect: d.code}
+}
+
+var (
+	firstNames = []string{"Ivan", "Anna", "Dmitry", "Olga", "Sergey", "Maria", "Aleksei", "Elena", "John", "Emma"}
+	lastNames  = []string{"Petrov", "Ivanova", "Smirnov", "Kuznetsova", "Popov", "Sokolova", "Smith", "Brown"}
+	cities     = []struct{ city, region, zip, phone string }{
+		{"Moscow", "Moscow", "101000", "+7495"},
+		{"Saint Petersburg", "Leningrad Oblast", "190000", "+7812"},
+		{"Kazan", "Tatarstan", "420000", "+7843"},
+		{"Almaty", "Almaty", "050000", "+7727"},
+		{"Kiryat Mozkin", "Kraiot", "2639809", "+972"},
+		{"Berlin", "Berlin", "10115", "+4930"},
+	}
+	streets    = []string{"Lenina", "Mira", "Sadovaya", "Tverskaya", "Pushkina", "Hauptstrasse"}
+	entries    = []string{"WBIL", "WBMP", "WBKZ"}
+	locales    = []string{"en", "ru", "ru-RU", "kk", "de"}
+	currencies = []money.Currency{"USD", "EUR", "RUB", "KZT"}
+	services   = []string{"meest", "cdek", "boxberry", "dhl"}
+	providers  = []string{"wbpay", "sbp", "card"}
+	banks      = []string{"alpha", "sber", "tinkoff", "vtb"}
+	products   = []struct{ name, brand string }{
+		{"Mascaras", "Vivienne Sabo"},
+		{"Lipstick", "Maybelline"},
+		{"T-shirt", "Befree"},
+		{"Sneakers", "Nike"},
+		{"Backpack", "Xiaomi"},
+		{"Phone case", "Spigen"},
+		{"Coffee beans", "Lavazza"},
+	}
+	sizes = []string{"0", "S", "M", "L", "XL", "42"}
+	sales = []int{0, 0, 0, 10, 15, 25, 30, 50}
+)
+
+func pick[T any](rng *rand.Rand, values []T) T {
+	return values[rng.IntN(len(values))]
+}
+
+func (g *Generator) hex(n int) string {
+	const digits = "0123456789abcdef"
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = digits[g.rng.IntN(len(digits))]
+	}
+	return string(b)
+}
+
+func (g *Generator) digits(n int) string {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = byte('0' + g.rng.IntN(10))
+	}
+	return string(b)
+}
+
+// order собирает валидный заказ с согласованными суммами.
+func (g *Generator) order() models.Order {
+	uid := g.prefix + g.hex(16)
+	track := "WB" + strings.ToUpper(g.hex(10))
+	first, last := pick(g.rng, firstNames), pick(g.rng, lastNames)
+	place := pick(g.rng, cities)
+	created := g.start.Add(-time.Duration(g.rng.Int64N(int64(30 * 24 * time.Hour))).Truncate(time.Second))
+
+	items := make([]models.Item, 1+g.rng.IntN(5))
+	var goodsTotal money.Amount
+	for i := range items {
+		product := pick(g.rng, products)
+		price := money.Amount(100 + g.rng.IntN(99900))
+		sale := pick(g.rng, sales)
+		total, _ := price.Discount(sale)
+		goodsTotal += total
+		items[i] = models.Item{
+			CHRTID:      1 + g.rng.IntN(9999999),
+			TrackNumber: track,
+			Price:       money.Money{Amount: price},
+			RID:         g.hex(20),
+			Name:        product.name,
+			Sale:        sale,
+			Size:        pick(g.rng, sizes),
+			TotalPrice:  money.Money{Amount: total},
+			NMID:        1 + g.rng.IntN(9999999),
+			Brand:       product.brand,
+			Status:      202,
+		}
+	}
+	deliveryCost := money.Amount(pick(g.rng, []int{0, 500, 1500}))
+
+	order := models.Order{
+		OrderUID:    uid,
+		TrackNumber: track,
+		Entry:       pick(g.rng, entries),
+		Delivery: models.Delivery{
+			Name:    first + " " + last,
+			Phone:   place.phone + g.digits(7),
+			Zip:     place.zip,
+			City:    place.city,
+			Address: fmt.Sprintf("%s %d", pick(g.rng, streets), 1+g.rng.IntN(150)),
+			Region:  place.region,
+			Email:   strings.ToLower(first+"."+last) + "@example.com",
+		},
+		Payment: models.Payment{
+			Transaction:  uid,
+			Currency:     pick(g.rng, currencies),
+			Provider:     pick(g.rng, providers),
+			Amount:       money.Money{Amount: goodsTotal + deliveryCost},
+			PaymentDT:    models.UnixTime{Time: created.Add(time.Duration(g.rng.IntN(600)) * time.Second)},
+			Bank:         pick(g.rng, banks),
+			DeliveryCost: money.Money{Amount: deliveryCost},
+			GoodsTotal:   money.Money{Amount: goodsTotal},
+		},
+		Items:           items,
+		Locale:          pick(g.rng, locales),
+		CustomerID:      fmt.Sprintf("customer-%03d", g.rng.IntN(500)),
+		DeliveryService: pick(g.rng, services),
+		Shardkey:        fmt.Sprint(g.rng.IntN(10)),
+		SMID:            99,
+		DateCreated:     models.Timestamp{Time: created},
+		OOFShard:        "1",
+		Status:          models.OrderStatusCreated,
+	}
+	order.SetCurrency(order.Payment.Currency)
+	return order
+}
+
+// defects портят валидный заказ так, чтобы он нарушал ровно одно правило
+// validation.Order.
+var defects = []struct {
+	code  string
+	apply func(*models.Order)
+}{
+	{"delivery.email:email", func(o *models.Order) { o.Delivery.Email = "not-an-email" }},
+	{"delivery.phone:phone", func(o *models.Order) { o.Delivery.Phone = "call me" }},
+	{"delivery.zip:zip", func(o *models.Order) { o.Delivery.Zip = "?" }},
+	{"payment.currency:currency", func(o *models.Order) { o.Payment.Currency = "ZZZ" }},
+	{"locale:locale", func(o *models.Order) { o.Locale = "not a locale" }},
+	{"customer_id:required", func(o *models.Order) { o.CustomerID = "" }},
+	{"items:required", func(o *models.Order) { o.Items = nil }},
+	{"items[].sale:range", func(o *models.Order) { o.Items[0].Sale = 150 }},
+	{"items[].track_number:match", func(o *models.Order) { o.Items[0].TrackNumber = "OTHERTRACK" }},
+}
@@ -0,0 +1,133 @@
+package loadgen
+
+import (
+	"context"
+	"errors"
+	"orderkeeper/internal/models"
+	"orderkeeper/internal/validation"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var start = time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+
+func TestGenerator_Deterministic(t *testing.T) {
+	a := NewGenerator(42, "lg-", start, 0.3)
+	b := NewGenerator(42, "lg-", start, 0.3)
+	other := NewGenerator(43, "lg-", start, 0.3)
+
+	for range 50 {
+		sa, sb := a.Next(), b.Next()
+		assert.Equal(t, sa, sb)
+		assert.NotEqual(t, sa.Order.OrderUID, other.Next().Order.OrderUID)
+	}
+}
+
+func TestGenerator_Samples(t *testing.T) {
+	gen := NewGenerator(7, "lg-", start, 0.5)
+	valid, invalid := 0, 0
+	for range 500 {
+		sample := gen.Next()
+		order := sample.Order
+		err := validation.Order(&order)
+		if sample.Valid() {
+			valid++
+			require.NoError(t, err, order.OrderUID)
+			require.NoError(t, validation.Reconcile(&order, 0), order.OrderUID)
+			assert.Less(t, order.DateCreated.Time, start)
+			continue
+		}
+		invalid++
+		var violations validation.Errors
+		require.True(t, errors.As(err, &violations), sample.Defect)
+		require.Len(t, violations, 1, sample.Defect)
+		assert.Equal(t, sample.Defect, violations[0].Code())
+	}
+	assert.InDelta(t, 250, valid, 50)
+	assert.Equal(t, 500, valid+invalid)
+}
+
+// store — Kafka и API в одном: опубликованные валидные заказы становятся
+// видны после задержки.
+type store struct {
+	mu     sync.Mutex
+	orders map[string]time.Time
+	delay  time.Duration
+	fail   bool
+}
+
+func (s *store) Write(_ context.Context, orders ...models.Order) error {
+	if s.fail {
+		return errors.New("broker unavailable")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, o := range orders {
+		if validation.Order(&o) == nil {
+			s.orders[o.OrderUID] = time.Now().Add(s.delay)
+		}
+	}
+	return nil
+}
+
+func (s *store) Exists(_ context.Context, uid string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	at, ok := s.orders[uid]
+	return ok && time.Now().After(at), nil
+}
+
+func TestRun(t *testing.T) {
+	s := &store{orders: map[string]time.Time{}, delay: 20 * time.Millisecond}
+	report := Run(context.Background(), NewGenerator(1, "lg-", start, 0.2), s, s, Options{
+		Rate:         500,
+		Concurrency:  4,
+		Count:        40,
+		PollInterval: 5 * time.Millisecond,
+	})
+
+	assert.Equal(t, 40, report.Sent)
+	assert.Equal(t, 40, report.Valid+report.Invalid)
+	assert.Equal(t, report.Valid, report.Readable)
+	assert.Zero(t, report.NotReadable)
+	total := 0
+	for _, n := range report.Defects {
+		total += n
+	}
+	assert.Equal(t, report.Invalid, total)
+	assert.GreaterOrEqual(t, report.Latency.Min, 20*time.Millisecond)
+	assert.LessOrEqual(t, report.Latency.P50, report.Latency.P99)
+}
+
+func TestRun_Failures(t *testing.T) {
+	t.Run("not readable", func(t *testing.T) {
+		s := &store{orders: map[string]time.Time{}, delay: time.Hour}
+		report := Run(context.Background(), NewGenerator(1, "lg-", start, 0), s, s, Options{
+			Count:        3,
+			ReadTimeout:  30 * time.Millisecond,
+			PollInterval: 5 * time.Millisecond,
+		})
+		assert.Equal(t, 3, report.NotReadable)
+		assert.Zero(t, report.Readable)
+	})
+
+	t.Run("publish errors", func(t *testing.T) {
+		s := &store{orders: map[string]time.Time{}, fail: true}
+		report := Run(context.Background(), NewGenerator(1, "lg-", start, 0), s, s, Options{Count: 3})
+		assert.Equal(t, 3, report.PublishErrors)
+		assert.Zero(t, report.Valid)
+	})
+
+	t.Run("duration", func(t *testing.T) {
+		s := &store{orders: map[string]time.Time{}}
+		report := Run(context.Background(), NewGenerator(1, "lg-", start, 0), s, s, Options{
+			Rate:     100,
+			Duration: 50 * time.Millisecond,
+		})
+		assert.InDelta(t, 5, report.Sent, 4)
+	})
+}
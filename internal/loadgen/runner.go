@@ -0,0 +1,286 @@
+package loadgen
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"orderkeeper/internal/models"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Publisher отправляет заказы в Kafka; его реализует kafka.OrderWriter.
+type Publisher interface {
+	Write(ctx context.Context, orders ...models.Order) error
+}
+
+// Prober проверяет, отдает ли API заказ.
+type Prober interface {
+	Exists(ctx context.Context, uid string) (bool, error)
+}
+
+// HTTPProber опрашивает GET /order/{id} работающего инстанса.
+type HTTPProber struct {
+	BaseURL string
+	APIKey  string
+	Client  *http.Client
+}
+
+func (p HTTPProber) Exists(ctx context.Context, uid string) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.BaseURL+"/order/"+url.PathEscape(uid), nil)
+	if err != nil {
+		return false, err
+	}
+	if p.APIKey != "" {
+		req.Header.Set("X-API-Key", p.APIKey)
+	}
+	client := p.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return true, nil
+	case http.StatusNotFound:
+		return false, nil
+	default:
+		return false, fmt.Errorf("GET /order/%s: %s", uid, resp.Status)
+	}
+}
+
+type Options struct {
+	// Rate — заказов в секунду; 0 — без ограничения.
+	Rate float64
+	// Concurrency — число параллельных отправителей.
+	Concurrency int
+	// Генерация останавливается после Count заказов или по истечении
+	// Duration — что наступит раньше; нулевое значение не ограничивает.
+	Count    int
+	Duration time.Duration
+	// ReadTimeout — сколько ждать появления валидного заказа в API.
+	ReadTimeout  time.Duration
+	PollInterval time.Duration
+}
+
+const (
+	defaultReadTimeout  = 30 * time.Second
+	defaultPollInterval = 50 * time.Millisecond
+)
+
+// Report — итог прогона. Невалидные заказы в API не ищутся: консьюмер должен
+// их отклонить.
+type Report struct {
+	Sent          int            `json:"sent"`
+	Valid         int            `json:"valid"`
+	Invalid       int            `json:"invalid"`
+	Defects       map[string]int `json:"defects"`
+	PublishErrors int            `json:"publish_errors"`
+	Readable      int            `json:"readable"`
+	NotReadable   int            `json:"not_readable"`
+	Elapsed       time.Duration  `json:"elapsed_ns"`
+	Rate          float64        `json:"rate"`
+	Latency       Latency        `json:"latency"`
+}
+
+// Latency — время от отправки заказа до первого успешного GET /order/{id}.
+type Latency struct {
+	Min  time.Duration `json:"min_ns"`
+	P50  time.Duration `json:"p50_ns"`
+	P90  time.Duration `json:"p90_ns"`
+	P99  time.Duration `json:"p99_ns"`
+	Max  time.Duration `json:"max_ns"`
+	Mean time.Duration `json:"mean_ns"`
+}
+
+func newLatency(samples []time.Duration) Latency {
+	if len(samples) == 0 {
+		return Latency{}
+	}
+	sort.Slice(samples, func(i, j int) bool { return samples[i] < samples[j] })
+	at := func(q float64) time.Duration {
+		return samples[int(q*float64(len(samples)-1))]
+	}
+	var total time.Duration
+	for _, s := range samples {
+		total += s
+	}
+	return Latency{
+		Min:  samples[0],
+		P50:  at(0.50),
+		P90:  at(0.90),
+		P99:  at(0.99),
+		Max:  samples[len(samples)-1],
+		Mean: total / time.Duration(len(samples)),
+	}
+}
+
+func (r Report) WriteText(w io.Writer) {
+	fmt.Fprintf(w, "sent %d orders in %s (%.1f/s): %d valid, %d invalid, %d publish errors\n",
+		r.Sent, r.Elapsed.Round(time.Millisecond), r.Rate, r.Valid, r.Invalid, r.PublishErrors)
+	codes := make([]string, 0, len(r.Defects))
+	for code := range r.Defects {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	for _, code := range codes {
+		fmt.Fprintf(w, "  invalid %-30s %d\n", code, r.Defects[code])
+	}
+	fmt.Fprintf(w, "readable through the API: %d, not readable in time: %d\n", r.Readable, r.NotReadable)
+	if r.Readable > 0 {
+		l := r.Latency
+		fmt.Fprintf(w, "end-to-end latency: min %s  p50 %s  p90 %s  p99 %s  max %s  mean %s\n",
+			l.Min.Round(time.Millisecond), l.P50.Round(time.Millisecond), l.P90.Round(time.Millisecond),
+			l.P99.Round(time.Millisecond), l.Max.Round(time.Millisecond), l.Mean.Round(time.Millisecond))
+	}
+}
+
+type collector struct {
+	mu        sync.Mutex
+	report    Report
+	latencies []time.Duration
+}
+
+func (c *collector) update(fn func(r *Report)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	fn(&c.report)
+}
+
+// Run отправляет заказы gen через publisher и ждет появления валидных в
+// API через prober. Заказы генерируются в одной горутине, поэтому
+// последовательность не зависит от Concurrency.
+func Run(ctx context.Context, gen *Generator, publisher Publisher, prober Prober, opts Options) Report {
+	if opts.Concurrency < 1 {
+		opts.Concurrency = 1
+	}
+	if opts.ReadTimeout <= 0 {
+		opts.ReadTimeout = defaultReadTimeout
+	}
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = defaultPollInterval
+	}
+	genCtx := ctx
+	if opts.Duration > 0 {
+		var cancel context.CancelFunc
+		genCtx, cancel = context.WithTimeout(ctx, opts.Duration)
+		defer cancel()
+	}
+
+	c := &collector{report: Report{Defects: map[string]int{}}}
+	samples := make(chan Sample)
+	started := time.Now()
+	go func() {
+		defer close(samples)
+		var tick <-chan time.Time
+		if opts.Rate > 0 {
+			ticker := time.NewTicker(time.Duration(float64(time.Second) / opts.Rate))
+			defer ticker.Stop()
+			tick = ticker.C
+		}
+		for i := 0; opts.Count == 0 || i < opts.Count; i++ {
+			if tick != nil {
+				select {
+				case <-genCtx.Done():
+					return
+				case <-tick:
+				}
+			}
+			select {
+			case <-genCtx.Done():
+				return
+			case samples <- gen.Next():
+			}
+		}
+	}()
+
+	var senders, probes sync.WaitGroup
+	for range opts.Concurrency {
+		senders.Add(1)
+		go func() {
+			defer senders.Done()
+			for sample := range samples {
+				sent := time.Now()
+				err := publisher.Write(ctx, sample.Order)
+				c.update(func(r *Report) {
+					r.Sent++
+					if err != nil {
+						r.PublishErrors++
+						return
+					}
+					if sample.Valid() {
+						r.Valid++
+					} else {
+						r.Invalid++
+						r.Defects[sample.Defect]++
+					}
+				})
+				if err != nil {
+					log.Printf("Failed to publish order %s: %v", sample.Order.OrderUID, err)
+					continue
+				}
+				if sample.Valid() {
+					probes.Add(1)
+					go func() {
+						defer probes.Done()
+						c.probe(ctx, prober, sample.Order.OrderUID, sent, opts)
+					}()
+				}
+			}
+		}()
+	}
+	senders.Wait()
+	sendElapsed := time.Since(started)
+	probes.Wait()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	report := c.report
+	report.Elapsed = sendElapsed
+	if sendElapsed > 0 {
+		report.Rate = float64(report.Sent) / sendElapsed.Seconds()
+	}
+	report.Latency = newLatency(c.latencies)
+	return report
+}
+
+// probe опрашивает API, пока заказ не появится или не истечет ReadTimeout.
+func (c *collector) probe(ctx context.Context, prober Prober, uid string, sent time.Time, opts Options) {
+	ctx, cancel := context.WithDeadline(ctx, sent.Add(opts.ReadTimeout))
+	defer cancel()
+	ticker := time.NewTicker(opts.PollInterval)
+	defer ticker.Stop()
+	var lastErr error
+	for {
+		ok, err := prober.Exists(ctx, uid)
+		if ok {
+			latency := time.Since(sent)
+			c.mu.Lock()
+			c.report.Readable++
+			c.latencies = append(c.latencies, latency)
+			c.mu.Unlock()
+			return
+		}
+		if err != nil && ctx.Err() == nil {
+			lastErr = err
+		}
+		select {
+		case <-ctx.Done():
+			if lastErr != nil {
+				log.Printf("Order %s is not readable: %v", uid, lastErr)
+			}
+			c.update(func(r *Report) { r.NotReadable++ })
+			return
+		case <-ticker.C:
+		}
+	}
+}
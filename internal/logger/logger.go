@@ -0,0 +1,50 @@
+// Package logger настраивает уровень логирования приложения.
+//
+// Стандартный log перенаправляется в slog, поэтому существующие вызовы
+// log.Printf пишутся с уровнем INFO и подчиняются текущему уровню.
+package logger
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+var level = new(slog.LevelVar)
+
+func Setup(lvl string) error {
+	if err := SetLevel(lvl); err != nil {
+		return err
+	}
+	slog.SetDefault(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: level})))
+	return nil
+}
+
+func SetLevel(lvl string) error {
+	parsed, err := ParseLevel(lvl)
+	if err != nil {
+		return err
+	}
+	level.Set(parsed)
+	return nil
+}
+
+func Level() string {
+	return strings.ToLower(level.Level().String())
+}
+
+func ParseLevel(lvl string) (slog.Level, error) {
+	switch strings.ToLower(strings.TrimSpace(lvl)) {
+	case "", "info":
+		return slog.LevelInfo, nil
+	case "debug":
+		return slog.LevelDebug, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	default:
+		return 0, fmt.Errorf("unknown log level %q", lvl)
+	}
+}
@@ -0,0 +1,37 @@
+// Package metrics публикует счетчики приложения через expvar (/debug/vars).
+package metrics
+
+import (
+	"expvar"
+	"time"
+)
+
+var (
+	ConfigReloads    = expvar.NewMap("config_reloads")
+	lastReloadAt     = expvar.NewString("config_last_reload_at")
+	lastReloadStatus = expvar.NewString("config_last_reload_status")
+
+	OutboxEvents = expvar.NewMap("outbox_events")
+
+	// Reconciliation считает заказы с расхождением сумм: "flagged" сохранены
+	// с пометкой для проверки, "rejected" отклонены в строгом режиме.
+	Reconciliation = expvar.NewMap("reconciliation")
+
+	// Imports считает записи массового импорта: "imported" и "skipped".
+	Imports = expvar.NewMap("order_imports")
+
+	// Retention считает заказы, обработанные политикой хранения:
+	// "archived" и "purged".
+	Retention = expvar.NewMap("order_retention")
+)
+
+func RecordReload(err error) {
+	lastReloadAt.Set(time.Now().UTC().Format(time.RFC3339))
+	if err != nil {
+		ConfigReloads.Add("failure", 1)
+		lastReloadStatus.Set("failure: " + err.Error())
+		return
+	}
+	ConfigReloads.Add("success", 1)
+	lastReloadStatus.Set("success")
+}
@@ -0,0 +1,44 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"orderkeeper/internal/money"
+	"reflect"
+
+	"gorm.io/gorm/schema"
+)
+
+// AmountSerializerName — имя сериализатора GORM для тега
+// `gorm:"serializer:amount"`. Он хранит money.Money целым числом
+// минимальных единиц без валюты: валюта лежит в отдельной колонке оплаты и
+// проставляется хуками AfterFind.
+const AmountSerializerName = "amount"
+
+func init() {
+	schema.RegisterSerializer(AmountSerializerName, AmountSerializer{})
+}
+
+type AmountSerializer struct{}
+
+func (AmountSerializer) Scan(ctx context.Context, field *schema.Field, dst reflect.Value, dbValue any) error {
+	var amount int64
+	switch v := dbValue.(type) {
+	case nil:
+	case int64:
+		amount = v
+	case int32:
+		amount = int64(v)
+	default:
+		return fmt.Errorf("unsupported type %T for %s", dbValue, field.DBName)
+	}
+	return field.Set(ctx, dst, money.Money{Amount: money.Amount(amount)})
+}
+
+func (AmountSerializer) Value(ctx context.Context, field *schema.Field, dst reflect.Value, fieldValue any) (any, error) {
+	m, ok := fieldValue.(money.Money)
+	if !ok {
+		return nil, fmt.Errorf("unsupported type %T for %s", fieldValue, field.DBName)
+	}
+	return int64(m.Amount), nil
+}
@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// APIKey — выданный API-ключ. Сам ключ не хранится: по Hash его можно
+// проверить, а по Prefix — узнать в списке.
+type APIKey struct {
+	ID        uint       `json:"id" gorm:"primaryKey"`
+	Name      string     `json:"name" gorm:"not null"`
+	Prefix    string     `json:"prefix" gorm:"not null"`
+	Scopes    []string   `json:"scopes" gorm:"serializer:json;not null;default:'[]'"`
+	Tenant    string     `json:"tenant,omitempty" gorm:"not null;default:''"`
+	Hash      string     `json:"-" gorm:"not null;uniqueIndex"`
+	CreatedAt time.Time  `json:"created_at" gorm:"not null"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+}
+
+// IssuedAPIKey — ответ на выпуск ключа: Key показывается только один раз.
+type IssuedAPIKey struct {
+	APIKey
+	Key string `json:"key"`
+}
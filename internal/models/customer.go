@@ -0,0 +1,37 @@
+package models
+
+import (
+	"orderkeeper/internal/money"
+	"time"
+)
+
+// OrderPage — страница заказов с общим числом найденных.
+type OrderPage struct {
+	Orders []Order `json:"orders"`
+	Total  int64   `json:"total"`
+	Limit  int     `json:"limit"`
+	Offset int     `json:"offset"`
+}
+
+// CustomerSummary — сводка по заказам покупателя. TotalSpend считается
+// по неотмененным заказам отдельно для каждой валюты.
+type CustomerSummary struct {
+	CustomerID               string        `json:"customer_id"`
+	OrderCount               int64         `json:"order_count"`
+	TotalSpend               []money.Money `json:"total_spend"`
+	FirstOrderAt             Timestamp     `json:"first_order_at" swaggertype:"string" format:"date-time"`
+	LastOrderAt              Timestamp     `json:"last_order_at" swaggertype:"string" format:"date-time"`
+	PreferredDeliveryService string        `json:"preferred_delivery_service"`
+}
+
+// OrderFilter отбирает заказы для выгрузки. After — курсор: выгружаются
+// заказы с order_uid больше него. NeedsReview, если задан, отбирает заказы
+// с таким флагом проверки. Нулевые поля не ограничивают выборку.
+type OrderFilter struct {
+	After       string
+	From        time.Time
+	To          time.Time
+	Status      string
+	CustomerID  string
+	NeedsReview *bool
+}
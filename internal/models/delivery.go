@@ -1,13 +1,51 @@
 package models
 
+import (
+	"orderkeeper/internal/pii"
+
+	"gorm.io/gorm"
+)
+
+// Delivery — получатель заказа. Имя, телефон, email и адрес хранятся
+// зашифрованными, если задан ключ (см. пакет pii); телефон и email ищутся
+// по слепым индексам PhoneIndex и EmailIndex.
 type Delivery struct {
-	ID       uint   `gorm:"primaryKey"`
-	OrderUID string `gorm:"unique;not null"`
-	Name     string `json:"name" gorm:"not null"`
-	Phone    string `json:"phone" gorm:"not null"`
-	Zip      string `json:"zip" gorm:"not null"`
-	City     string `json:"city" gorm:"not null"`
-	Address  string `json:"address" gorm:"not null"`
-	Region   string `json:"region" gorm:"not null"`
-	Email    string `json:"email" gorm:"not null"`
+	ID         uint   `gorm:"primaryKey"`
+	OrderUID   string `gorm:"unique;not null"`
+	Name       string `json:"name" gorm:"not null;serializer:pii"`
+	Phone      string `json:"phone" gorm:"not null;serializer:pii"`
+	Zip        string `json:"zip" gorm:"not null"`
+	City       string `json:"city" gorm:"not null"`
+	Address    string `json:"address" gorm:"not null;serializer:pii"`
+	Region     string `json:"region" gorm:"not null"`
+	Email      string `json:"email" gorm:"not null;serializer:pii"`
+	PhoneIndex string `json:"-" gorm:"not null;default:'';index" swaggerignore:"true"`
+	EmailIndex string `json:"-" gorm:"not null;default:'';index" swaggerignore:"true"`
+}
+
+// BeforeSave пересчитывает слепые индексы из открытых значений.
+func (d *Delivery) BeforeSave(*gorm.DB) error {
+	d.PhoneIndex = pii.PhoneIndex(d.Phone)
+	d.EmailIndex = pii.EmailIndex(d.Email)
+	return nil
+}
+
+// ErasedValue заменяет персональные данные получателя после их стирания по
+// запросу покупателя.
+const ErasedValue = "[erased]"
+
+// Masked возвращает копию получателя со скрытыми персональными данными для
+// клиентов без права их читать. Стертые значения остаются как есть.
+func (d Delivery) Masked() Delivery {
+	mask := func(v string, f func(string) string) string {
+		if v == ErasedValue {
+			return v
+		}
+		return f(v)
+	}
+	d.Name = mask(d.Name, pii.MaskName)
+	d.Phone = mask(d.Phone, pii.MaskPhone)
+	d.Address = mask(d.Address, pii.MaskAddress)
+	d.Email = mask(d.Email, pii.MaskEmail)
+	return d
 }
@@ -1,17 +1,38 @@
 package models
 
+import (
+	"encoding/json"
+	"orderkeeper/internal/money"
+)
+
+// Item хранит цены в валюте оплаты заказа. Как и в Payment, в БД и JSON
+// цены — целые числа в минимальных единицах без кода валюты; валюту
+// проставляет Order.SetCurrency.
 type Item struct {
-	ID          uint   `gorm:"primaryKey"`
-	OrderUID    string `gorm:"index;not null"`
-	CHRTID      int    `json:"chrt_id" gorm:"not null"`
-	TrackNumber string `json:"track_number" gorm:"not null"`
-	Price       int    `json:"price" gorm:"not null"`
-	RID         string `json:"rid" gorm:"not null"`
-	Name        string `json:"name" gorm:"not null"`
-	Sale        int    `json:"sale" gorm:"not null"`
-	Size        string `json:"size" gorm:"not null"`
-	TotalPrice  int    `json:"total_price" gorm:"not null"`
-	NMID        int    `json:"nm_id" gorm:"not null"`
-	Brand       string `json:"brand" gorm:"not null"`
-	Status      int    `json:"status" gorm:"not null"`
+	ID          uint        `gorm:"primaryKey"`
+	OrderUID    string      `gorm:"index;not null"`
+	CHRTID      int         `json:"chrt_id" gorm:"not null"`
+	TrackNumber string      `json:"track_number" gorm:"not null;index"`
+	Price       money.Money `json:"price" gorm:"not null;type:bigint;serializer:amount" swaggertype:"integer"`
+	RID         string      `json:"rid" gorm:"not null"`
+	Name        string      `json:"name" gorm:"not null"`
+	Sale        int         `json:"sale" gorm:"not null"`
+	Size        string      `json:"size" gorm:"not null"`
+	TotalPrice  money.Money `json:"total_price" gorm:"not null;type:bigint;serializer:amount" swaggertype:"integer"`
+	NMID        int         `json:"nm_id" gorm:"not null"`
+	Brand       string      `json:"brand" gorm:"not null"`
+	Status      int         `json:"status" gorm:"not null"`
+}
+
+// item — Item без MarshalJSON, чтобы не зациклить кодирование.
+type item Item
+
+type itemJSON struct {
+	item
+	Price      money.Amount `json:"price"`
+	TotalPrice money.Amount `json:"total_price"`
+}
+
+func (it Item) MarshalJSON() ([]byte, error) {
+	return json.Marshal(itemJSON{item: item(it), Price: it.Price.Amount, TotalPrice: it.TotalPrice.Amount})
 }
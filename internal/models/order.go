@@ -1,19 +1,82 @@
 // Package models
 package models
 
+import (
+	"orderkeeper/internal/money"
+	"slices"
+
+	"gorm.io/gorm"
+)
+
+const (
+	OrderStatusCreated   = "created"
+	OrderStatusPaid      = "paid"
+	OrderStatusShipped   = "shipped"
+	OrderStatusDelivered = "delivered"
+	OrderStatusCancelled = "cancelled"
+)
+
+// IsValidOrderStatus сообщает, является ли status известным статусом заказа.
+func IsValidOrderStatus(status string) bool {
+	switch status {
+	case OrderStatusCreated, OrderStatusPaid, OrderStatusShipped, OrderStatusDelivered, OrderStatusCancelled:
+		return true
+	}
+	return false
+}
+
+// orderStatusFlow — порядок статусов: заказ переходит только к более
+// позднему статусу. Отменить можно любой недоставленный заказ; доставленный
+// и отмененный заказ больше не меняется.
+var orderStatusFlow = []string{OrderStatusCreated, OrderStatusPaid, OrderStatusShipped, OrderStatusDelivered}
+
+// OrderStatusesBefore возвращает статусы, из которых заказ может перейти в
+// status.
+func OrderStatusesBefore(status string) []string {
+	if status == OrderStatusCancelled {
+		return orderStatusFlow[:len(orderStatusFlow)-1]
+	}
+	return orderStatusFlow[:max(slices.Index(orderStatusFlow, status), 0)]
+}
+
 type Order struct {
-	OrderUID          string   `json:"order_uid" gorm:"primaryKey;unique;not null"`
-	TrackNumber       string   `json:"track_number" gorm:"not null"`
-	Entry             string   `json:"entry" gorm:"not null"`
-	Delivery          Delivery `json:"delivery" gorm:"foreignKey:OrderUID;constraint:OnDelete:CASCADE;"`
-	Payment           Payment  `json:"payment" gorm:"foreignKey:OrderUID;constraint:OnDelete:CASCADE;"`
-	Items             []Item   `json:"items" gorm:"foreignKey:OrderUID;constraint:OnDelete:CASCADE;"`
-	Locale            string   `json:"locale" gorm:"not null"`
-	InternalSignature string   `json:"internal_signature"`
-	CustomerID        string   `json:"customer_id" gorm:"not null"`
-	DeliveryService   string   `json:"delivery_service" gorm:"not null"`
-	Shardkey          string   `json:"shardkey" gorm:"not null"`
-	SMID              int      `json:"sm_id" gorm:"not null"`
-	DateCreated       string   `json:"date_created" gorm:"not null"`
-	OOFShard          string   `json:"oof_shard" gorm:"not null"`
+	OrderUID          string    `json:"order_uid" gorm:"primaryKey;unique;not null"`
+	TrackNumber       string    `json:"track_number" gorm:"not null;index"`
+	Entry             string    `json:"entry" gorm:"not null;index"`
+	Delivery          Delivery  `json:"delivery" gorm:"foreignKey:OrderUID;constraint:OnDelete:CASCADE;"`
+	Payment           Payment   `json:"payment" gorm:"foreignKey:OrderUID;constraint:OnDelete:CASCADE;"`
+	Items             []Item    `json:"items" gorm:"foreignKey:OrderUID;constraint:OnDelete:CASCADE;"`
+	Locale            string    `json:"locale" gorm:"not null"`
+	InternalSignature string    `json:"internal_signature"`
+	CustomerID        string    `json:"customer_id" gorm:"not null;index:idx_orders_customer_date,priority:1"`
+	DeliveryService   string    `json:"delivery_service" gorm:"not null"`
+	Shardkey          string    `json:"shardkey" gorm:"not null"`
+	SMID              int       `json:"sm_id" gorm:"not null"`
+	DateCreated       Timestamp `json:"date_created" gorm:"not null;index;index:idx_orders_customer_date,priority:2" swaggertype:"string" format:"date-time"`
+	OOFShard          string    `json:"oof_shard" gorm:"not null"`
+	Status            string    `json:"status" gorm:"not null;default:created"`
+	CancelReason      string    `json:"cancel_reason,omitempty"`
+	NeedsReview       bool      `json:"needs_review" gorm:"not null;default:false;index"`
+	ReviewReason      string    `json:"review_reason,omitempty"`
+	// DeletedAt — время мягкого удаления или архивации. GORM исключает
+	// такие заказы из выборок, пока запрос не помечен Unscoped.
+	DeletedAt gorm.DeletedAt `json:"-" gorm:"index" swaggerignore:"true"`
+}
+
+// SetCurrency проставляет валюту c оплате и ценам позиций: в БД и JSON
+// валюта хранится только у оплаты, поэтому после декодирования заказа из
+// JSON ее нужно проставить.
+func (o *Order) SetCurrency(c money.Currency) {
+	o.Payment.SetCurrency(c)
+	for i := range o.Items {
+		o.Items[i].Price.Currency = c
+		o.Items[i].TotalPrice.Currency = c
+	}
+}
+
+// AfterFind проставляет позициям валюту оплаты. Вызывается после Preload,
+// так что без загруженной оплаты валюта позиций остается пустой.
+func (o *Order) AfterFind(*gorm.DB) error {
+	o.SetCurrency(o.Payment.Currency)
+	return nil
 }
@@ -0,0 +1,65 @@
+package models
+
+import (
+	"context"
+	"encoding/json"
+	"orderkeeper/internal/money"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm/schema"
+)
+
+func TestOrderStatusesBefore(t *testing.T) {
+	assert.Empty(t, OrderStatusesBefore(OrderStatusCreated))
+	assert.Equal(t, []string{OrderStatusCreated, OrderStatusPaid}, OrderStatusesBefore(OrderStatusShipped))
+	assert.Equal(t, []string{OrderStatusCreated, OrderStatusPaid, OrderStatusShipped}, OrderStatusesBefore(OrderStatusDelivered))
+	assert.NotContains(t, OrderStatusesBefore(OrderStatusCancelled), OrderStatusDelivered)
+	assert.Empty(t, OrderStatusesBefore("lost"))
+}
+
+func TestOrderMoneyJSON(t *testing.T) {
+	data := `{"payment":{"currency":"USD","amount":1817,"delivery_cost":1500,"goods_total":317,"custom_fee":0},"items":[{"price":453,"total_price":317}]}`
+
+	var order Order
+	require.NoError(t, json.Unmarshal([]byte(data), &order))
+	order.SetCurrency(order.Payment.Currency)
+
+	assert.Equal(t, money.New(1817, "USD"), order.Payment.Amount)
+	assert.Equal(t, money.New(0, "USD"), order.Payment.CustomFee)
+	assert.Equal(t, money.New(453, "USD"), order.Items[0].Price)
+
+	out, err := json.Marshal(order)
+	require.NoError(t, err)
+	var got struct {
+		Payment map[string]any   `json:"payment"`
+		Items   []map[string]any `json:"items"`
+	}
+	require.NoError(t, json.Unmarshal(out, &got))
+	assert.Equal(t, "USD", got.Payment["currency"])
+	assert.EqualValues(t, 1817, got.Payment["amount"])
+	assert.EqualValues(t, 1500, got.Payment["delivery_cost"])
+	assert.EqualValues(t, 453, got.Items[0]["price"])
+	assert.EqualValues(t, 317, got.Items[0]["total_price"])
+}
+
+func TestAmountSerializer(t *testing.T) {
+	s, err := schema.Parse(&Payment{}, &sync.Map{}, schema.NamingStrategy{})
+	require.NoError(t, err)
+	field := s.LookUpField("amount")
+	require.NotNil(t, field.Serializer)
+
+	ctx := context.Background()
+	value, err := AmountSerializer{}.Value(ctx, field, reflect.Value{}, money.New(1817, "USD"))
+	require.NoError(t, err)
+	assert.Equal(t, int64(1817), value)
+
+	var payment Payment
+	require.NoError(t, AmountSerializer{}.Scan(ctx, field, reflect.ValueOf(&payment).Elem(), int64(1817)))
+	payment.Currency = "USD"
+	require.NoError(t, payment.AfterFind(nil))
+	assert.Equal(t, money.New(1817, "USD"), payment.Amount)
+}
@@ -0,0 +1,77 @@
+package models
+
+import (
+	"encoding/json"
+	"net/url"
+	"time"
+)
+
+// Типы доменных событий. Префикс EventTypePrefix отличает их от типов
+// входящих сообщений консьюмера (order.cancelled и др.), которые передаются
+// в том же заголовке message-type.
+const (
+	EventTypePrefix = "event."
+
+	EventOrderCreated       = EventTypePrefix + "order.created"
+	EventOrderStatusChanged = EventTypePrefix + "order.status_changed"
+	EventOrderCancelled     = EventTypePrefix + "order.cancelled"
+	EventOrderDeleted       = EventTypePrefix + "order.deleted"
+	EventOrderErased        = EventTypePrefix + "order.personal_data_erased"
+)
+
+// OutboxEvent — доменное событие, записанное в одной транзакции с изменением
+// заказа и ожидающее публикации в Kafka. После публикации relay проставляет
+// SentAt.
+type OutboxEvent struct {
+	ID          uint   `gorm:"primaryKey"`
+	AggregateID string `gorm:"index;not null"`
+	// Tenant — площадка заказа, по ней relay выбирает топик.
+	Tenant    string          `gorm:"not null;default:''"`
+	EventType string          `gorm:"not null"`
+	Payload   json.RawMessage `gorm:"type:jsonb;not null"`
+	CreatedAt time.Time       `gorm:"not null"`
+	SentAt    *time.Time      `gorm:"index"`
+	Attempts  int             `gorm:"not null;default:0"`
+	LastError string
+}
+
+// OrderCreatedEvent — заказ для события order.created без имени, телефона,
+// email и адреса получателя: outbox хранится в БД открыто. Вместо них событие
+// несет PersonalData — путь API, по которому потребитель с областью pii:read
+// получает заказ с данными получателя.
+type OrderCreatedEvent struct {
+	Order
+	PersonalData string `json:"personal_data"`
+}
+
+func NewOrderCreatedEvent(order Order) OrderCreatedEvent {
+	d := &order.Delivery
+	d.Name, d.Phone, d.Email, d.Address = "", "", "", ""
+	return OrderCreatedEvent{Order: order, PersonalData: OrderPath(order.OrderUID)}
+}
+
+// OrderPath возвращает путь заказа в API.
+func OrderPath(orderUID string) string {
+	return "/order/" + url.PathEscape(orderUID)
+}
+
+type StatusChangedEvent struct {
+	OrderUID string `json:"order_uid"`
+	Status   string `json:"status"`
+}
+
+type CancelledEvent struct {
+	OrderUID string `json:"order_uid"`
+	Reason   string `json:"reason"`
+}
+
+type DeletedEvent struct {
+	OrderUID string `json:"order_uid"`
+}
+
+// ErasedEvent сообщает потребителям, что персональные данные получателя
+// заказа стерты и их копии тоже нужно удалить.
+type ErasedEvent struct {
+	OrderUID   string `json:"order_uid"`
+	CustomerID string `json:"customer_id"`
+}
@@ -0,0 +1,28 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrderCreatedEvent(t *testing.T) {
+	order := Order{
+		OrderUID: "uid-1",
+		Delivery: Delivery{Name: "Test Testov", Phone: "+79720000000", Email: "test@gmail.com", Address: "Ploshad Mira 15", City: "Kiryat Mozkin"},
+	}
+
+	event := NewOrderCreatedEvent(order)
+
+	assert.Equal(t, Delivery{City: "Kiryat Mozkin"}, event.Delivery)
+	assert.Equal(t, "uid-1", event.OrderUID)
+	assert.Equal(t, "/order/uid-1", event.PersonalData)
+	assert.Equal(t, "Test Testov", order.Delivery.Name)
+
+	data, err := json.Marshal(event)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"order_uid":"uid-1"`)
+	assert.Contains(t, string(data), `"personal_data":"/order/uid-1"`)
+}
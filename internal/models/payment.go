@@ -1,16 +1,61 @@
 package models
 
+import (
+	"encoding/json"
+	"orderkeeper/internal/money"
+
+	"gorm.io/gorm"
+)
+
+// Payment хранит суммы вместе с валютой оплаты. В БД и JSON суммы — целые
+// числа в минимальных единицах, а валюта записывается один раз в Currency и
+// проставляется суммам при чтении из БД и Order.SetCurrency.
 type Payment struct {
-	ID           uint   `gorm:"primaryKey"`
-	OrderUID     string `gorm:"unique;not null"`
-	Transaction  string `json:"transaction" gorm:"not null"`
-	RequestID    string `json:"request_id"`
-	Currency     string `json:"currency" gorm:"not null"`
-	Provider     string `json:"provider" gorm:"not null"`
-	Amount       int    `json:"amount" gorm:"not null"`
-	PaymentDT    int    `json:"payment_dt" gorm:"not null"`
-	Bank         string `json:"bank" gorm:"not null"`
-	DeliveryCost int    `json:"delivery_cost" gorm:"not null"`
-	GoodsTotal   int    `json:"goods_total" gorm:"not null"`
-	CustomFee    int    `json:"custom_fee" gorm:"not null"`
+	ID           uint           `gorm:"primaryKey"`
+	OrderUID     string         `gorm:"unique;not null"`
+	Transaction  string         `json:"transaction" gorm:"not null"`
+	RequestID    string         `json:"request_id"`
+	Currency     money.Currency `json:"currency" gorm:"not null"`
+	Provider     string         `json:"provider" gorm:"not null"`
+	Amount       money.Money    `json:"amount" gorm:"not null;type:bigint;serializer:amount" swaggertype:"integer"`
+	PaymentDT    UnixTime       `json:"payment_dt" gorm:"not null" swaggertype:"integer"`
+	Bank         string         `json:"bank" gorm:"not null"`
+	DeliveryCost money.Money    `json:"delivery_cost" gorm:"not null;type:bigint;serializer:amount" swaggertype:"integer"`
+	GoodsTotal   money.Money    `json:"goods_total" gorm:"not null;type:bigint;serializer:amount" swaggertype:"integer"`
+	CustomFee    money.Money    `json:"custom_fee" gorm:"not null;type:bigint;serializer:amount" swaggertype:"integer"`
+}
+
+// SetCurrency задает валюту оплаты и всех ее сумм.
+func (p *Payment) SetCurrency(c money.Currency) {
+	p.Currency = c
+	for _, m := range []*money.Money{&p.Amount, &p.DeliveryCost, &p.GoodsTotal, &p.CustomFee} {
+		m.Currency = c
+	}
+}
+
+// AfterFind проставляет прочитанным суммам валюту оплаты.
+func (p *Payment) AfterFind(*gorm.DB) error {
+	p.SetCurrency(p.Currency)
+	return nil
+}
+
+// payment — Payment без MarshalJSON, чтобы не зациклить кодирование.
+type payment Payment
+
+type paymentJSON struct {
+	payment
+	Amount       money.Amount `json:"amount"`
+	DeliveryCost money.Amount `json:"delivery_cost"`
+	GoodsTotal   money.Amount `json:"goods_total"`
+	CustomFee    money.Amount `json:"custom_fee"`
+}
+
+func (p Payment) MarshalJSON() ([]byte, error) {
+	return json.Marshal(paymentJSON{
+		payment:      payment(p),
+		Amount:       p.Amount.Amount,
+		DeliveryCost: p.DeliveryCost.Amount,
+		GoodsTotal:   p.GoodsTotal.Amount,
+		CustomFee:    p.CustomFee.Amount,
+	})
 }
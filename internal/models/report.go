@@ -0,0 +1,31 @@
+package models
+
+import "orderkeeper/internal/money"
+
+// Измерения, по которым группируются отчеты.
+const (
+	ReportByCurrency        = "currency"
+	ReportByDeliveryService = "delivery_service"
+	ReportByRegion          = "region"
+	ReportByProvider        = "provider"
+	ReportByBrand           = "brand"
+)
+
+// ReportRow — число заказов и выручка за день по одному значению
+// измерения. Выручка всегда считается в рамках одной валюты; для
+// группировки по бренду это сумма total_price позиций бренда.
+type ReportRow struct {
+	Day      string         `json:"day"`
+	Group    string         `json:"group"`
+	Currency money.Currency `json:"currency"`
+	Orders   int64          `json:"orders"`
+	Revenue  money.Amount   `json:"revenue"`
+}
+
+// Report — отчет за период [From, To] включительно, даты в UTC.
+type Report struct {
+	From    string      `json:"from"`
+	To      string      `json:"to"`
+	GroupBy string      `json:"group_by"`
+	Rows    []ReportRow `json:"rows"`
+}
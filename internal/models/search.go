@@ -0,0 +1,17 @@
+package models
+
+// SearchHit — найденный заказ с релевантностью и фрагментами текста, где
+// совпадения обрамлены <mark></mark>.
+type SearchHit struct {
+	Order     Order   `json:"order"`
+	Rank      float64 `json:"rank"`
+	Highlight string  `json:"highlight"`
+}
+
+type SearchPage struct {
+	Query  string      `json:"query"`
+	Hits   []SearchHit `json:"hits"`
+	Total  int64       `json:"total"`
+	Limit  int         `json:"limit"`
+	Offset int         `json:"offset"`
+}
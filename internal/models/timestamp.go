@@ -0,0 +1,114 @@
+package models
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// ParseTime разбирает время в формате RFC 3339 или в Unix-секундах.
+func ParseTime(s string) (time.Time, error) {
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.Unix(n, 0).UTC(), nil
+	}
+	t, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time %q: expected RFC 3339 or Unix seconds", s)
+	}
+	return t.UTC(), nil
+}
+
+func unmarshalTime(data []byte) (time.Time, error) {
+	if bytes.Equal(data, []byte("null")) {
+		return time.Time{}, nil
+	}
+	if len(data) > 0 && data[0] == '"' {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return time.Time{}, err
+		}
+		if s == "" {
+			return time.Time{}, nil
+		}
+		return ParseTime(s)
+	}
+	return ParseTime(string(data))
+}
+
+func scanTime(src any) (time.Time, error) {
+	switch v := src.(type) {
+	case nil:
+		return time.Time{}, nil
+	case time.Time:
+		return v.UTC(), nil
+	case string:
+		return ParseTime(v)
+	case []byte:
+		return ParseTime(string(v))
+	default:
+		return time.Time{}, fmt.Errorf("cannot scan %T into time", src)
+	}
+}
+
+// Timestamp хранится в БД как timestamptz и сериализуется в JSON строкой
+// RFC 3339 с долями секунды, если они есть, а нулевое время — как null. На
+// входе принимает и Unix-секунды.
+type Timestamp struct {
+	time.Time
+}
+
+func (Timestamp) GormDataType() string { return "timestamptz" }
+
+func (t Timestamp) MarshalJSON() ([]byte, error) {
+	if t.IsZero() {
+		return []byte("null"), nil
+	}
+	return json.Marshal(t.UTC().Format(time.RFC3339Nano))
+}
+
+func (t *Timestamp) UnmarshalJSON(data []byte) error {
+	v, err := unmarshalTime(data)
+	t.Time = v
+	return err
+}
+
+func (t Timestamp) Value() (driver.Value, error) { return t.Time, nil }
+
+func (t *Timestamp) Scan(src any) error {
+	v, err := scanTime(src)
+	t.Time = v
+	return err
+}
+
+// UnixTime хранится в БД как timestamptz и сериализуется в JSON числом
+// Unix-секунд, а нулевое время — как null. На входе принимает и строку
+// RFC 3339.
+type UnixTime struct {
+	time.Time
+}
+
+func (UnixTime) GormDataType() string { return "timestamptz" }
+
+func (t UnixTime) MarshalJSON() ([]byte, error) {
+	if t.IsZero() {
+		return []byte("null"), nil
+	}
+	return []byte(strconv.FormatInt(t.Unix(), 10)), nil
+}
+
+func (t *UnixTime) UnmarshalJSON(data []byte) error {
+	v, err := unmarshalTime(data)
+	t.Time = v
+	return err
+}
+
+func (t UnixTime) Value() (driver.Value, error) { return t.Time, nil }
+
+func (t *UnixTime) Scan(src any) error {
+	v, err := scanTime(src)
+	t.Time = v
+	return err
+}
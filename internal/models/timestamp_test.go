@@ -0,0 +1,64 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimestampJSON(t *testing.T) {
+	want := time.Date(2021, 11, 26, 6, 22, 7, 0, time.UTC)
+
+	for _, data := range []string{
+		`{"date_created":"2021-11-26T06:22:07Z","payment_dt":1637907727}`,
+		`{"date_created":1637907727,"payment_dt":"2021-11-26T09:22:07+03:00"}`,
+		`{"date_created":"1637907727","payment_dt":"1637907727"}`,
+	} {
+		var v struct {
+			DateCreated Timestamp `json:"date_created"`
+			PaymentDT   UnixTime  `json:"payment_dt"`
+		}
+		require.NoError(t, json.Unmarshal([]byte(data), &v), data)
+		assert.True(t, want.Equal(v.DateCreated.Time), data)
+		assert.True(t, want.Equal(v.PaymentDT.Time), data)
+
+		out, err := json.Marshal(v)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"date_created":"2021-11-26T06:22:07Z","payment_dt":1637907727}`, string(out))
+	}
+
+	var ts Timestamp
+	assert.Error(t, json.Unmarshal([]byte(`"yesterday"`), &ts))
+
+	out, err := json.Marshal(Timestamp{Time: want.Add(123 * time.Millisecond)})
+	require.NoError(t, err)
+	assert.Equal(t, `"2021-11-26T06:22:07.123Z"`, string(out))
+
+	out, err = json.Marshal(Timestamp{})
+	require.NoError(t, err)
+	assert.Equal(t, `null`, string(out))
+	require.NoError(t, json.Unmarshal(out, &ts))
+	assert.True(t, ts.IsZero())
+
+	out, err = json.Marshal(UnixTime{})
+	require.NoError(t, err)
+	assert.Equal(t, `null`, string(out))
+	var ut UnixTime
+	require.NoError(t, json.Unmarshal(out, &ut))
+	assert.True(t, ut.IsZero())
+}
+
+func TestTimestampScan(t *testing.T) {
+	want := time.Date(2021, 11, 26, 6, 22, 7, 0, time.UTC)
+
+	var ts Timestamp
+	require.NoError(t, ts.Scan(want.In(time.FixedZone("MSK", 3*3600))))
+	assert.Equal(t, want, ts.Time)
+
+	value, err := UnixTime{Time: want}.Value()
+	require.NoError(t, err)
+	assert.Equal(t, want, value)
+}
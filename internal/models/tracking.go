@@ -0,0 +1,58 @@
+package models
+
+// Shipment — позиции заказа, отправленные под одним трек-номером.
+type Shipment struct {
+	TrackNumber string `json:"track_number"`
+	Items       []Item `json:"items"`
+}
+
+// Tracking — ответ на поиск по трек-номеру: заказ и его позиции,
+// сгруппированные по отправлениям.
+type Tracking struct {
+	TrackNumber string     `json:"track_number"`
+	Order       Order      `json:"order"`
+	Shipments   []Shipment `json:"shipments"`
+}
+
+// TrackNumbers возвращает трек-номер заказа и все отличные от него
+// трек-номера позиций.
+func (o Order) TrackNumbers() []string {
+	var tracks []string
+	seen := make(map[string]bool)
+	for _, track := range append([]string{o.TrackNumber}, itemTracks(o.Items)...) {
+		if track != "" && !seen[track] {
+			seen[track] = true
+			tracks = append(tracks, track)
+		}
+	}
+	return tracks
+}
+
+// Shipments группирует позиции по трек-номерам. Позиции без своего
+// трек-номера относятся к отправлению заказа.
+func (o Order) Shipments() []Shipment {
+	var shipments []Shipment
+	index := make(map[string]int)
+	for _, item := range o.Items {
+		track := item.TrackNumber
+		if track == "" {
+			track = o.TrackNumber
+		}
+		i, ok := index[track]
+		if !ok {
+			i = len(shipments)
+			index[track] = i
+			shipments = append(shipments, Shipment{TrackNumber: track})
+		}
+		shipments[i].Items = append(shipments[i].Items, item)
+	}
+	return shipments
+}
+
+func itemTracks(items []Item) []string {
+	tracks := make([]string, len(items))
+	for i, item := range items {
+		tracks[i] = item.TrackNumber
+	}
+	return tracks
+}
@@ -0,0 +1,182 @@
+// Package money описывает денежные суммы в минимальных единицах валюты
+// (копейках, центах) вместе с кодом валюты ISO 4217.
+package money
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"golang.org/x/text/currency"
+)
+
+var (
+	ErrUnknownCurrency  = errors.New("unknown ISO 4217 currency")
+	ErrCurrencyMismatch = errors.New("currency mismatch")
+	ErrOverflow         = errors.New("money amount overflow")
+)
+
+// Amount — сумма в минимальных единицах валюты. В JSON и БД это целое
+// число, как и раньше.
+type Amount int64
+
+// Currency — трехбуквенный код валюты ISO 4217.
+type Currency string
+
+func ParseCurrency(s string) (Currency, error) {
+	if len(s) != 3 {
+		return "", fmt.Errorf("%w: %q", ErrUnknownCurrency, s)
+	}
+	unit, err := currency.ParseISO(s)
+	if err != nil {
+		return "", fmt.Errorf("%w: %q", ErrUnknownCurrency, s)
+	}
+	return Currency(unit.String()), nil
+}
+
+func (c Currency) Valid() bool {
+	_, err := ParseCurrency(string(c))
+	return err == nil
+}
+
+// Exponent возвращает число знаков минимальной единицы по данным CLDR:
+// 2 для USD, 0 для JPY, 3 для BHD.
+func (c Currency) Exponent() (int, error) {
+	unit, err := currency.ParseISO(string(c))
+	if err != nil || len(c) != 3 {
+		return 0, fmt.Errorf("%w: %q", ErrUnknownCurrency, c)
+	}
+	scale, _ := currency.Standard.Rounding(unit)
+	return scale, nil
+}
+
+// Add складывает суммы с проверкой переполнения.
+func (a Amount) Add(b Amount) (Amount, error) {
+	if (b > 0 && a > math.MaxInt64-b) || (b < 0 && a < math.MinInt64-b) {
+		return 0, ErrOverflow
+	}
+	return a + b, nil
+}
+
+func (a Amount) Sub(b Amount) (Amount, error) {
+	if b == math.MinInt64 {
+		return 0, ErrOverflow
+	}
+	return a.Add(-b)
+}
+
+func (a Amount) Abs() Amount {
+	if a < 0 {
+		return -a
+	}
+	return a
+}
+
+// Discount возвращает сумму со скидкой percent процентов, округленную вниз,
+// и признак того, что результат точный, без дробной части.
+func (a Amount) Discount(percent int) (Amount, bool) {
+	keep := Amount(100 - percent)
+	q, r := a/100, a%100
+	return q*keep + r*keep/100, (r*keep)%100 == 0
+}
+
+// Money — сумма вместе с валютой. Арифметика разрешена только между
+// суммами в одной валюте.
+type Money struct {
+	Amount   Amount   `json:"amount"`
+	Currency Currency `json:"currency"`
+}
+
+// UnmarshalJSON принимает объект {"amount": 1817, "currency": "USD"} или
+// число минимальных единиц без валюты: так суммы приходят в оплате и
+// позициях заказа, а валюту им задает оплата.
+func (m *Money) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] == '{' {
+		type plain Money
+		return json.Unmarshal(data, (*plain)(m))
+	}
+	var amount Amount
+	if err := json.Unmarshal(data, &amount); err != nil {
+		return err
+	}
+	*m = Money{Amount: amount}
+	return nil
+}
+
+func New(amount Amount, currency Currency) Money {
+	return Money{Amount: amount, Currency: currency}
+}
+
+func (m Money) Add(o Money) (Money, error) {
+	if m.Currency != o.Currency {
+		return Money{}, fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, m.Currency, o.Currency)
+	}
+	sum, err := m.Amount.Add(o.Amount)
+	if err != nil {
+		return Money{}, err
+	}
+	return New(sum, m.Currency), nil
+}
+
+func (m Money) Sub(o Money) (Money, error) {
+	if m.Currency != o.Currency {
+		return Money{}, fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, m.Currency, o.Currency)
+	}
+	diff, err := m.Amount.Sub(o.Amount)
+	if err != nil {
+		return Money{}, err
+	}
+	return New(diff, m.Currency), nil
+}
+
+// Decimal форматирует сумму в основных единицах без кода валюты: "18.17".
+func (m Money) Decimal() string {
+	exp, err := m.Currency.Exponent()
+	if err != nil || exp == 0 {
+		return strconv.FormatInt(int64(m.Amount), 10)
+	}
+	sign := ""
+	abs := uint64(m.Amount)
+	if m.Amount < 0 {
+		sign = "-"
+		abs = -abs
+	}
+	div := uint64(math.Pow10(exp))
+	return fmt.Sprintf("%s%d.%0*d", sign, abs/div, exp, abs%div)
+}
+
+// String форматирует сумму в основных единицах: "18.17 USD".
+func (m Money) String() string {
+	return m.Decimal() + " " + string(m.Currency)
+}
+
+// Value хранит сумму с валютой в одной текстовой колонке: "USD 1817".
+func (m Money) Value() (driver.Value, error) {
+	return string(m.Currency) + " " + strconv.FormatInt(int64(m.Amount), 10), nil
+}
+
+func (m *Money) Scan(src any) error {
+	var s string
+	switch v := src.(type) {
+	case string:
+		s = v
+	case []byte:
+		s = string(v)
+	default:
+		return fmt.Errorf("cannot scan %T into money.Money", src)
+	}
+	code, amount, ok := strings.Cut(s, " ")
+	if !ok {
+		return fmt.Errorf("invalid money value %q", s)
+	}
+	n, err := strconv.ParseInt(amount, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid money value %q: %w", s, err)
+	}
+	*m = New(Amount(n), Currency(code))
+	return nil
+}
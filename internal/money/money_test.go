@@ -0,0 +1,74 @@
+package money
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCurrency(t *testing.T) {
+	for code, exp := range map[Currency]int{"USD": 2, "RUB": 2, "JPY": 0, "BHD": 3} {
+		got, err := code.Exponent()
+		require.NoError(t, err)
+		assert.Equal(t, exp, got, code)
+	}
+
+	_, err := ParseCurrency("ABC")
+	assert.True(t, errors.Is(err, ErrUnknownCurrency))
+	assert.False(t, Currency("usd1").Valid())
+}
+
+func TestMoneyArithmetic(t *testing.T) {
+	sum, err := New(1500, "USD").Add(New(317, "USD"))
+	require.NoError(t, err)
+	assert.Equal(t, New(1817, "USD"), sum)
+
+	_, err = New(1, "USD").Add(New(1, "EUR"))
+	assert.True(t, errors.Is(err, ErrCurrencyMismatch))
+
+	_, err = New(math.MaxInt64, "USD").Add(New(1, "USD"))
+	assert.True(t, errors.Is(err, ErrOverflow))
+
+	diff, err := New(300, "USD").Sub(New(317, "USD"))
+	require.NoError(t, err)
+	assert.Equal(t, Amount(17), diff.Amount.Abs())
+}
+
+func TestAmountDiscount(t *testing.T) {
+	got, exact := Amount(453).Discount(30)
+	assert.Equal(t, Amount(317), got)
+	assert.False(t, exact)
+
+	got, exact = Amount(1000).Discount(25)
+	assert.Equal(t, Amount(750), got)
+	assert.True(t, exact)
+}
+
+func TestMoneyEncoding(t *testing.T) {
+	m := New(-1817, "USD")
+	assert.Equal(t, "-18.17 USD", m.String())
+	assert.Equal(t, "500 JPY", New(500, "JPY").String())
+
+	data, err := json.Marshal(m)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"amount":-1817,"currency":"USD"}`, string(data))
+
+	var decoded Money
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, m, decoded)
+	require.NoError(t, json.Unmarshal([]byte(`1817`), &decoded))
+	assert.Equal(t, Money{Amount: 1817}, decoded)
+	assert.Error(t, json.Unmarshal([]byte(`"1817"`), &decoded))
+
+	value, err := m.Value()
+	require.NoError(t, err)
+	var scanned Money
+	require.NoError(t, scanned.Scan([]byte(value.(string))))
+	assert.Equal(t, m, scanned)
+	assert.Error(t, scanned.Scan("1817"))
+	assert.Error(t, scanned.Scan(int64(1817)))
+}
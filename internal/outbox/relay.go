@@ -0,0 +1,94 @@
+// Package outbox публикует доменные события из таблицы outbox в Kafka.
+package outbox
+
+import (
+	"context"
+	"log"
+	"orderkeeper/internal/metrics"
+	"orderkeeper/internal/models"
+	"orderkeeper/internal/repository"
+	"time"
+)
+
+const (
+	defaultInterval  = time.Second
+	defaultBatchSize = 100
+	maxBackoff       = time.Minute
+	// publishTimeout ограничивает публикацию пачки: пока она идет, события
+	// заблокированы в транзакции, а соединение с БД занято.
+	publishTimeout = 30 * time.Second
+)
+
+type Publisher interface {
+	Publish(ctx context.Context, events []models.OutboxEvent) error
+}
+
+// Relay периодически забирает неотправленные события и публикует их.
+// Событие помечается отправленным только после успешной публикации, так что
+// при сбоях возможны повторы, но не потери.
+type Relay struct {
+	repo      repository.OutboxRepository
+	publisher Publisher
+	interval  time.Duration
+	batchSize int
+	timeout   time.Duration
+}
+
+func NewRelay(repo repository.OutboxRepository, publisher Publisher, interval time.Duration, batchSize int) *Relay {
+	if interval <= 0 {
+		interval = defaultInterval
+	}
+	if batchSize <= 0 {
+		batchSize = defaultBatchSize
+	}
+	return &Relay{repo: repo, publisher: publisher, interval: interval, batchSize: batchSize, timeout: publishTimeout}
+}
+
+func (r *Relay) Run(ctx context.Context) {
+	log.Printf("Outbox relay is running (interval %v, batch size %d)", r.interval, r.batchSize)
+	delay := r.interval
+	for {
+		n, err := r.PublishBatch(ctx)
+		switch {
+		case err != nil:
+			log.Printf("Outbox relay failed to publish events: %v. Retrying in %v", err, delay)
+		case n == r.batchSize:
+			// Очередь не разобрана — сразу берем следующую пачку.
+			delay = r.interval
+			if ctx.Err() != nil {
+				return
+			}
+			continue
+		default:
+			delay = r.interval
+		}
+
+		select {
+		case <-ctx.Done():
+			log.Println("Stopping outbox relay due to context cancellation")
+			return
+		case <-time.After(delay):
+		}
+		if err != nil {
+			delay = min(delay*2, maxBackoff)
+		}
+	}
+}
+
+// PublishBatch публикует одну пачку событий и возвращает ее размер.
+// Публикация идет внутри транзакции, которая держит события под SELECT ...
+// FOR UPDATE SKIP LOCKED, поэтому она ограничена таймаутом: зависший брокер
+// не должен держать блокировки и соединение с БД дольше.
+func (r *Relay) PublishBatch(ctx context.Context) (int, error) {
+	n, err := r.repo.PublishPending(r.batchSize, func(events []models.OutboxEvent) error {
+		ctx, cancel := context.WithTimeout(ctx, r.timeout)
+		defer cancel()
+		return r.publisher.Publish(ctx, events)
+	})
+	if err != nil {
+		metrics.OutboxEvents.Add("failed", 1)
+		return 0, err
+	}
+	metrics.OutboxEvents.Add("published", int64(n))
+	return n, nil
+}
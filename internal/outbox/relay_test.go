@@ -0,0 +1,90 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"orderkeeper/internal/models"
+	"orderkeeper/internal/repository"
+	"orderkeeper/internal/repository/mocks"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+type fakePublisher struct {
+	published []models.OutboxEvent
+	err       error
+	// hang — ждать отмены контекста, как при недоступном брокере.
+	hang bool
+}
+
+func (p *fakePublisher) Publish(ctx context.Context, events []models.OutboxEvent) error {
+	if p.hang {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	if p.err != nil {
+		return p.err
+	}
+	p.published = append(p.published, events...)
+	return nil
+}
+
+func TestRelay_PublishBatch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockOutboxRepository(ctrl)
+	pending := []models.OutboxEvent{
+		{ID: 1, AggregateID: "uid-1", EventType: models.EventOrderCreated},
+		{ID: 2, AggregateID: "uid-1", EventType: models.EventOrderCancelled},
+	}
+	passPending := func(limit int, publish repository.PublishFunc) (int, error) {
+		if err := publish(pending); err != nil {
+			return 0, err
+		}
+		return len(pending), nil
+	}
+
+	t.Run("published", func(t *testing.T) {
+		publisher := &fakePublisher{}
+		relay := NewRelay(mockRepo, publisher, 0, 10)
+		mockRepo.EXPECT().PublishPending(10, gomock.Any()).DoAndReturn(passPending)
+
+		n, err := relay.PublishBatch(context.Background())
+
+		assert.NoError(t, err)
+		assert.Equal(t, 2, n)
+		assert.Equal(t, pending, publisher.published)
+	})
+
+	t.Run("publish error is returned", func(t *testing.T) {
+		publisher := &fakePublisher{err: errors.New("broker unavailable")}
+		relay := NewRelay(mockRepo, publisher, 0, 10)
+		mockRepo.EXPECT().PublishPending(10, gomock.Any()).DoAndReturn(passPending)
+
+		n, err := relay.PublishBatch(context.Background())
+
+		assert.EqualError(t, err, "broker unavailable")
+		assert.Zero(t, n)
+	})
+
+	t.Run("publish is bounded by timeout", func(t *testing.T) {
+		relay := NewRelay(mockRepo, &fakePublisher{hang: true}, 0, 10)
+		relay.timeout = 10 * time.Millisecond
+		mockRepo.EXPECT().PublishPending(10, gomock.Any()).DoAndReturn(passPending)
+
+		_, err := relay.PublishBatch(context.Background())
+
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("defaults", func(t *testing.T) {
+		relay := NewRelay(mockRepo, &fakePublisher{}, 0, 0)
+
+		assert.Equal(t, defaultInterval, relay.interval)
+		assert.Equal(t, defaultBatchSize, relay.batchSize)
+	})
+}
@@ -0,0 +1,159 @@
+// Package pii шифрует персональные данные получателя на уровне
+// приложения по схеме envelope encryption: каждое значение шифруется своим
+// ключом данных (AES-256-GCM), а ключ данных — ключом шифрования ключей из
+// KeyProvider. Для поиска по точному совпадению телефона и email строятся
+// слепые индексы — HMAC-SHA256 от нормализованного значения.
+package pii
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Prefix отличает зашифрованные значения от открытых, записанных до
+// включения шифрования.
+const Prefix = "enc:v1:"
+
+var b64 = base64.RawURLEncoding
+
+type Cipher struct {
+	keys KeyProvider
+}
+
+func NewCipher(keys KeyProvider) *Cipher {
+	return &Cipher{keys: keys}
+}
+
+// KeyID — ключ, которым шифруются новые значения.
+func (c *Cipher) KeyID() string {
+	return c.keys.CurrentKeyID()
+}
+
+// KeyPrefix — начало значений, зашифрованных текущим ключом. Значения с
+// другим началом нужно перешифровать.
+func (c *Cipher) KeyPrefix() string {
+	return Prefix + c.keys.CurrentKeyID() + ":"
+}
+
+// Encrypt шифрует plaintext текущим ключом. aad привязывает шифртекст к
+// месту хранения (например, к имени колонки), так что его нельзя
+// незаметно перенести в другое поле.
+func (c *Cipher) Encrypt(plaintext, aad string) (string, error) {
+	dek := make([]byte, keySize)
+	if _, err := rand.Read(dek); err != nil {
+		return "", err
+	}
+	keyID := c.keys.CurrentKeyID()
+	wrapped, err := c.keys.WrapKey(keyID, dek)
+	if err != nil {
+		return "", err
+	}
+	aead, err := newAEAD(dek)
+	if err != nil {
+		return "", err
+	}
+	sealed, err := seal(aead, []byte(plaintext), []byte(aad))
+	if err != nil {
+		return "", err
+	}
+	return Prefix + keyID + ":" + b64.EncodeToString(wrapped) + ":" + b64.EncodeToString(sealed), nil
+}
+
+// Decrypt расшифровывает значение, зашифрованное любым ключом провайдера.
+// Открытые значения без Prefix возвращаются как есть.
+func (c *Cipher) Decrypt(value, aad string) (string, error) {
+	if !IsEncrypted(value) {
+		return value, nil
+	}
+	parts := strings.Split(strings.TrimPrefix(value, Prefix), ":")
+	if len(parts) != 3 {
+		return "", errors.New("malformed encrypted value")
+	}
+	wrapped, err := b64.DecodeString(parts[1])
+	if err != nil {
+		return "", fmt.Errorf("malformed encrypted value: %w", err)
+	}
+	sealed, err := b64.DecodeString(parts[2])
+	if err != nil {
+		return "", fmt.Errorf("malformed encrypted value: %w", err)
+	}
+	dek, err := c.keys.UnwrapKey(parts[0], wrapped)
+	if err != nil {
+		return "", err
+	}
+	aead, err := newAEAD(dek)
+	if err != nil {
+		return "", err
+	}
+	plaintext, err := open(aead, sealed, []byte(aad))
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
+
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, Prefix)
+}
+
+// PhoneIndex — слепой индекс телефона по его цифрам, так что "+7 (495)
+// 123-45-67" и "+74951234567" совпадают. Для телефона без цифр — "".
+func (c *Cipher) PhoneIndex(phone string) string {
+	var b strings.Builder
+	for _, r := range phone {
+		if r >= '0' && r <= '9' {
+			b.WriteRune(r)
+		}
+	}
+	return c.blindIndex("phone", b.String())
+}
+
+// EmailIndex — слепой индекс email без учета регистра и пробелов по краям.
+func (c *Cipher) EmailIndex(email string) string {
+	return c.blindIndex("email", strings.ToLower(strings.TrimSpace(email)))
+}
+
+func (c *Cipher) blindIndex(kind, normalized string) string {
+	if normalized == "" {
+		return ""
+	}
+	mac := hmac.New(sha256.New, c.keys.IndexKey())
+	mac.Write([]byte(kind + ":" + normalized))
+	return b64.EncodeToString(mac.Sum(nil))
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// seal возвращает nonce и шифртекст одним срезом.
+func seal(aead cipher.AEAD, plaintext, aad []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, plaintext, aad), nil
+}
+
+func open(aead cipher.AEAD, sealed, aad []byte) ([]byte, error) {
+	if len(sealed) < aead.NonceSize() {
+		return nil, errors.New("ciphertext is too short")
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, ciphertext, aad)
+	if err != nil {
+		return nil, errors.New("could not decrypt value: wrong key or corrupted data")
+	}
+	return plaintext, nil
+}
@@ -0,0 +1,176 @@
+package pii
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// KeyProvider хранит ключи шифрования ключей (KEK). Значения шифруются
+// собственными ключами данных, а провайдер только заворачивает и
+// разворачивает их, поэтому KEK может жить во внешнем KMS.
+type KeyProvider interface {
+	// CurrentKeyID — ключ, которым заворачиваются новые ключи данных.
+	CurrentKeyID() string
+	WrapKey(keyID string, dek []byte) ([]byte, error)
+	UnwrapKey(keyID string, wrapped []byte) ([]byte, error)
+	// IndexKey — ключ HMAC для слепых индексов. При ротации KEK он не
+	// меняется, иначе пришлось бы пересчитывать все индексы.
+	IndexKey() []byte
+}
+
+const keySize = 32
+
+// Keyfile — формат файла ключей LocalKeyProvider. Ключи хранятся в base64.
+type Keyfile struct {
+	Current  string            `json:"current"`
+	Keys     map[string]string `json:"keys"`
+	IndexKey string            `json:"index_key"`
+}
+
+// LocalKeyProvider держит KEK в JSON-файле. Подходит для разработки и
+// тестов; в проде файл должен лежать в секрете с ограниченным доступом.
+type LocalKeyProvider struct {
+	current  string
+	keys     map[string]cipher.AEAD
+	indexKey []byte
+}
+
+func NewLocalKeyProvider(kf Keyfile) (*LocalKeyProvider, error) {
+	if kf.Current == "" {
+		return nil, errors.New("keyfile has no current key")
+	}
+	p := &LocalKeyProvider{current: kf.Current, keys: make(map[string]cipher.AEAD, len(kf.Keys))}
+	for id, encoded := range kf.Keys {
+		if id == "" || strings.Contains(id, ":") {
+			return nil, fmt.Errorf("invalid key ID %q", id)
+		}
+		key, err := decodeKey(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("key %s: %w", id, err)
+		}
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, err
+		}
+		if p.keys[id], err = cipher.NewGCM(block); err != nil {
+			return nil, err
+		}
+	}
+	if _, ok := p.keys[kf.Current]; !ok {
+		return nil, fmt.Errorf("current key %q is not in the keyfile", kf.Current)
+	}
+	var err error
+	if p.indexKey, err = decodeKey(kf.IndexKey); err != nil {
+		return nil, fmt.Errorf("index key: %w", err)
+	}
+	return p, nil
+}
+
+func decodeKey(encoded string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, err
+	}
+	if len(key) != keySize {
+		return nil, fmt.Errorf("key must be %d bytes, got %d", keySize, len(key))
+	}
+	return key, nil
+}
+
+func (p *LocalKeyProvider) CurrentKeyID() string { return p.current }
+
+func (p *LocalKeyProvider) IndexKey() []byte { return p.indexKey }
+
+func (p *LocalKeyProvider) WrapKey(keyID string, dek []byte) ([]byte, error) {
+	kek, ok := p.keys[keyID]
+	if !ok {
+		return nil, fmt.Errorf("unknown key %q", keyID)
+	}
+	return seal(kek, dek, []byte(keyID))
+}
+
+func (p *LocalKeyProvider) UnwrapKey(keyID string, wrapped []byte) ([]byte, error) {
+	kek, ok := p.keys[keyID]
+	if !ok {
+		return nil, fmt.Errorf("unknown key %q", keyID)
+	}
+	return open(kek, wrapped, []byte(keyID))
+}
+
+// ReadKeyfile читает файл ключей.
+func ReadKeyfile(path string) (Keyfile, error) {
+	var kf Keyfile
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return kf, err
+	}
+	if err := json.Unmarshal(data, &kf); err != nil {
+		return kf, fmt.Errorf("invalid keyfile %s: %w", path, err)
+	}
+	return kf, nil
+}
+
+// LoadLocalKeyProvider читает файл ключей и создает по нему провайдер.
+func LoadLocalKeyProvider(path string) (*LocalKeyProvider, error) {
+	kf, err := ReadKeyfile(path)
+	if err != nil {
+		return nil, err
+	}
+	return NewLocalKeyProvider(kf)
+}
+
+// Rotate добавляет в файл ключей новый KEK и делает его текущим; если файла
+// нет, создает его вместе с ключом слепых индексов. Старые ключи остаются,
+// чтобы читать уже зашифрованные значения. Возвращает ID нового ключа.
+func Rotate(path string, now time.Time) (string, error) {
+	kf, err := ReadKeyfile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		kf = Keyfile{Keys: map[string]string{}, IndexKey: newKey()}
+	} else if err != nil {
+		return "", err
+	}
+	id := now.UTC().Format("20060102T150405Z")
+	if _, exists := kf.Keys[id]; exists {
+		return "", fmt.Errorf("key %s already exists", id)
+	}
+	kf.Keys[id] = newKey()
+	kf.Current = id
+	if _, err := NewLocalKeyProvider(kf); err != nil {
+		return "", err
+	}
+
+	data, err := json.MarshalIndent(kf, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	// Файл заменяется целиком, чтобы читатели не увидели его наполовину
+	// записанным.
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		return "", err
+	}
+	if err := tmp.Close(); err != nil {
+		return "", err
+	}
+	return id, os.Rename(tmp.Name(), path)
+}
+
+func newKey() string {
+	key := make([]byte, keySize)
+	_, _ = rand.Read(key)
+	return base64.StdEncoding.EncodeToString(key)
+}
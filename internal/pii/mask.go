@@ -0,0 +1,58 @@
+package pii
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// maskRune заменяет скрытые символы.
+const maskRune = '*'
+
+// MaskName оставляет первую букву каждого слова: «Test Testov» → «T*** T*****».
+func MaskName(name string) string {
+	words := strings.Fields(name)
+	for i, w := range words {
+		first, size := utf8.DecodeRuneInString(w)
+		words[i] = string(first) + strings.Repeat(string(maskRune), utf8.RuneCountInString(w[size:]))
+	}
+	return strings.Join(words, " ")
+}
+
+// MaskPhone скрывает все цифры, кроме последних четырех, сохраняя формат.
+func MaskPhone(phone string) string {
+	visible := 4
+	runes := []rune(phone)
+	for i := len(runes) - 1; i >= 0; i-- {
+		if !unicode.IsDigit(runes[i]) {
+			continue
+		}
+		if visible > 0 {
+			visible--
+			continue
+		}
+		runes[i] = maskRune
+	}
+	return string(runes)
+}
+
+// MaskEmail оставляет первую букву локальной части и домен.
+func MaskEmail(email string) string {
+	local, domain, ok := strings.Cut(email, "@")
+	if !ok {
+		return MaskAddress(email)
+	}
+	if local == "" {
+		return "@" + domain
+	}
+	first, _ := utf8.DecodeRuneInString(local)
+	return string(first) + "***@" + domain
+}
+
+// MaskAddress скрывает адрес целиком: по частям он слишком легко узнаваем.
+func MaskAddress(address string) string {
+	if address == "" {
+		return ""
+	}
+	return "***"
+}
@@ -0,0 +1,124 @@
+package pii
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestCipher(t *testing.T, path string) *Cipher {
+	t.Helper()
+	keys, err := LoadLocalKeyProvider(path)
+	require.NoError(t, err)
+	return NewCipher(keys)
+}
+
+func TestCipher(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys.json")
+	oldID, err := Rotate(path, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	old := newTestCipher(t, path)
+
+	encrypted, err := old.Encrypt("+7 (495) 123-45-67", "phone")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(encrypted, old.KeyPrefix()))
+	assert.NotContains(t, encrypted, "495")
+
+	t.Run("round trip", func(t *testing.T) {
+		plaintext, err := old.Decrypt(encrypted, "phone")
+		assert.NoError(t, err)
+		assert.Equal(t, "+7 (495) 123-45-67", plaintext)
+	})
+
+	t.Run("value is bound to its column", func(t *testing.T) {
+		_, err := old.Decrypt(encrypted, "email")
+		assert.Error(t, err)
+	})
+
+	t.Run("plaintext passes through", func(t *testing.T) {
+		plaintext, err := old.Decrypt("Test Testov", "name")
+		assert.NoError(t, err)
+		assert.Equal(t, "Test Testov", plaintext)
+	})
+
+	t.Run("rotation keeps old values readable", func(t *testing.T) {
+		newID, err := Rotate(path, time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC))
+		require.NoError(t, err)
+		assert.NotEqual(t, oldID, newID)
+		rotated := newTestCipher(t, path)
+		assert.Equal(t, newID, rotated.KeyID())
+
+		plaintext, err := rotated.Decrypt(encrypted, "phone")
+		assert.NoError(t, err)
+		assert.Equal(t, "+7 (495) 123-45-67", plaintext)
+		assert.False(t, strings.HasPrefix(encrypted, rotated.KeyPrefix()))
+
+		// Ключ слепых индексов при ротации не меняется.
+		assert.Equal(t, old.PhoneIndex("+74951234567"), rotated.PhoneIndex("+74951234567"))
+	})
+
+	t.Run("unknown key", func(t *testing.T) {
+		other := newTestCipher(t, writeKeyfile(t))
+		_, err := other.Decrypt(encrypted, "phone")
+		assert.Error(t, err)
+	})
+}
+
+func writeKeyfile(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "keys.json")
+	_, err := Rotate(path, time.Now())
+	require.NoError(t, err)
+	return path
+}
+
+func TestBlindIndex(t *testing.T) {
+	c := newTestCipher(t, writeKeyfile(t))
+
+	assert.Equal(t, c.PhoneIndex("+7 (495) 123-45-67"), c.PhoneIndex("74951234567"))
+	assert.NotEqual(t, c.PhoneIndex("74951234567"), c.PhoneIndex("74951234568"))
+	assert.Equal(t, c.EmailIndex(" Test@Gmail.com"), c.EmailIndex("test@gmail.com"))
+	assert.Empty(t, c.PhoneIndex("call me"))
+	// Одинаковые строки в разных полях дают разные индексы.
+	assert.NotEqual(t, c.PhoneIndex("123"), c.EmailIndex("123"))
+}
+
+func TestLocalKeyProvider(t *testing.T) {
+	kf, err := ReadKeyfile(writeKeyfile(t))
+	require.NoError(t, err)
+
+	t.Run("missing current key", func(t *testing.T) {
+		broken := kf
+		broken.Current = "nope"
+		_, err := NewLocalKeyProvider(broken)
+		assert.Error(t, err)
+	})
+
+	t.Run("short key", func(t *testing.T) {
+		broken := kf
+		broken.IndexKey = "c2hvcnQ="
+		_, err := NewLocalKeyProvider(broken)
+		assert.Error(t, err)
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		_, err := LoadLocalKeyProvider(filepath.Join(t.TempDir(), "none.json"))
+		assert.ErrorIs(t, err, os.ErrNotExist)
+	})
+}
+
+func TestMask(t *testing.T) {
+	assert.Equal(t, "T*** T*****", MaskName("Test  Testov"))
+	assert.Equal(t, "И*** П*****", MaskName("Иван Петров"))
+	assert.Equal(t, "+* (***) ***-45-67", MaskPhone("+7 (912) 123-45-67"))
+	assert.Equal(t, "12", MaskPhone("12"))
+	assert.Equal(t, "t***@gmail.com", MaskEmail("test@gmail.com"))
+	assert.Equal(t, "***", MaskEmail("not-an-email"))
+	assert.Equal(t, "***", MaskAddress("Ploshad Mira 15"))
+	assert.Equal(t, "", MaskAddress(""))
+}
@@ -0,0 +1,92 @@
+package pii
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync/atomic"
+
+	"gorm.io/gorm/schema"
+)
+
+// SerializerName — имя сериализатора GORM для тега
+// `gorm:"serializer:pii"`. Значение шифруется с именем колонки в AAD.
+const SerializerName = "pii"
+
+var current atomic.Pointer[Cipher]
+
+func init() {
+	schema.RegisterSerializer(SerializerName, Serializer{})
+}
+
+// SetCipher задает шифр для сериализатора и слепых индексов. nil отключает
+// шифрование: новые значения пишутся открыто, а зашифрованные прочитать
+// нельзя.
+func SetCipher(c *Cipher) {
+	current.Store(c)
+}
+
+// Current возвращает шифр, заданный SetCipher, или nil.
+func Current() *Cipher {
+	return current.Load()
+}
+
+// PhoneIndex — слепой индекс телефона текущим шифром или "", если
+// шифрование выключено.
+func PhoneIndex(phone string) string {
+	if c := Current(); c != nil {
+		return c.PhoneIndex(phone)
+	}
+	return ""
+}
+
+// EmailIndex — слепой индекс email текущим шифром или "", если шифрование
+// выключено.
+func EmailIndex(email string) string {
+	if c := Current(); c != nil {
+		return c.EmailIndex(email)
+	}
+	return ""
+}
+
+var ErrNoCipher = errors.New("value is encrypted but PII encryption is not configured")
+
+type Serializer struct{}
+
+func (Serializer) Scan(ctx context.Context, field *schema.Field, dst reflect.Value, dbValue any) error {
+	var value string
+	switch v := dbValue.(type) {
+	case nil:
+	case string:
+		value = v
+	case []byte:
+		value = string(v)
+	default:
+		return fmt.Errorf("unsupported type %T for %s", dbValue, field.DBName)
+	}
+	if IsEncrypted(value) {
+		c := Current()
+		if c == nil {
+			return ErrNoCipher
+		}
+		plaintext, err := c.Decrypt(value, field.DBName)
+		if err != nil {
+			return fmt.Errorf("%s: %w", field.DBName, err)
+		}
+		value = plaintext
+	}
+	return field.Set(ctx, dst, value)
+}
+
+func (Serializer) Value(ctx context.Context, field *schema.Field, dst reflect.Value, fieldValue any) (any, error) {
+	value, ok := fieldValue.(string)
+	if !ok {
+		return nil, fmt.Errorf("unsupported type %T for %s", fieldValue, field.DBName)
+	}
+	c := Current()
+	if c == nil {
+		return value, nil
+	}
+	return c.Encrypt(value, field.DBName)
+}
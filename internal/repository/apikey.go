@@ -0,0 +1,54 @@
+//go:generate go run go.uber.org/mock/mockgen -source=apikey.go -destination=mocks/mock_apikey_repository.go -package=mocks
+package repository
+
+import (
+	"orderkeeper/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type APIKeyRepository interface {
+	CreateAPIKey(key *models.APIKey) error
+	// GetActiveAPIKey ищет неотозванный ключ по хешу.
+	GetActiveAPIKey(hash string) (models.APIKey, error)
+	ListAPIKeys() ([]models.APIKey, error)
+	RevokeAPIKey(id uint, at time.Time) error
+}
+
+type apiKeyRepo struct {
+	db *gorm.DB
+}
+
+func NewAPIKeyRepository(db *gorm.DB) APIKeyRepository {
+	return &apiKeyRepo{db: db}
+}
+
+func (r *apiKeyRepo) CreateAPIKey(key *models.APIKey) error {
+	return r.db.Create(key).Error
+}
+
+func (r *apiKeyRepo) GetActiveAPIKey(hash string) (models.APIKey, error) {
+	var key models.APIKey
+	err := r.db.First(&key, "hash = ? AND revoked_at IS NULL", hash).Error
+	return key, err
+}
+
+func (r *apiKeyRepo) ListAPIKeys() ([]models.APIKey, error) {
+	var keys []models.APIKey
+	err := r.db.Order("id").Find(&keys).Error
+	return keys, err
+}
+
+func (r *apiKeyRepo) RevokeAPIKey(id uint, at time.Time) error {
+	res := r.db.Model(&models.APIKey{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", at)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
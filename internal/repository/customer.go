@@ -0,0 +1,150 @@
+//go:generate go run go.uber.org/mock/mockgen -source=customer.go -destination=mocks/mock_customer_repository.go -package=mocks
+package repository
+
+import (
+	"maps"
+	"orderkeeper/internal/models"
+	"orderkeeper/internal/tenant"
+
+	"gorm.io/gorm"
+)
+
+type CustomerRepository interface {
+	// GetOrdersByCustomer возвращает заказы покупателя от новых к старым
+	// и общее число его заказов.
+	GetOrdersByCustomer(customerID string, limit, offset int) ([]models.Order, int64, error)
+	// GetCustomerSummary возвращает сводку с OrderCount == 0, если у
+	// покупателя нет заказов.
+	GetCustomerSummary(customerID string) (models.CustomerSummary, error)
+	// AnonymizeCustomer заменяет имя, телефон, email и адрес получателя во
+	// всех заказах покупателя, включая удаленные. Платежи и позиции не
+	// меняются. Возвращает order_uid затронутых заказов.
+	AnonymizeCustomer(customerID string) ([]string, error)
+	// ForTenant возвращает репозиторий, который видит только покупателей и
+	// заказы площадки tenant.
+	ForTenant(tenant string) CustomerRepository
+}
+
+type customerRepo struct {
+	db     *gorm.DB
+	tenant string
+}
+
+func NewCustomerRepository(db *gorm.DB) CustomerRepository {
+	return &customerRepo{db: db}
+}
+
+func (r *customerRepo) ForTenant(tenant string) CustomerRepository {
+	return &customerRepo{db: r.db, tenant: tenant}
+}
+
+// orders начинает запрос к заказам площадки репозитория.
+func (r *customerRepo) orders(db *gorm.DB) *gorm.DB {
+	return db.Model(&models.Order{}).Scopes(tenant.Scope(r.tenant))
+}
+
+func (r *customerRepo) GetOrdersByCustomer(customerID string, limit, offset int) ([]models.Order, int64, error) {
+	var total int64
+	if err := r.orders(r.db).Where("customer_id = ?", customerID).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var orders []models.Order
+	err := r.orders(r.db).
+		Preload("Delivery").
+		Preload("Payment").
+		Preload("Items").
+		Where("customer_id = ?", customerID).
+		Order("date_created DESC, order_uid").
+		Limit(limit).
+		Offset(offset).
+		Find(&orders).Error
+	return orders, total, err
+}
+
+func (r *customerRepo) GetCustomerSummary(customerID string) (models.CustomerSummary, error) {
+	summary := models.CustomerSummary{CustomerID: customerID}
+
+	var stats struct {
+		OrderCount   int64
+		FirstOrderAt models.Timestamp
+		LastOrderAt  models.Timestamp
+	}
+	err := r.orders(r.db).
+		Select("count(*) AS order_count, min(date_created) AS first_order_at, max(date_created) AS last_order_at").
+		Where("customer_id = ?", customerID).
+		Scan(&stats).Error
+	if err != nil || stats.OrderCount == 0 {
+		return summary, err
+	}
+	summary.OrderCount = stats.OrderCount
+	summary.FirstOrderAt = stats.FirstOrderAt
+	summary.LastOrderAt = stats.LastOrderAt
+
+	err = r.db.Table("payments").
+		Select("currency, sum(amount) AS amount").
+		Where("order_uid IN (?)", r.orders(r.db).
+			Select("order_uid").
+			Where("customer_id = ? AND status <> ?", customerID, models.OrderStatusCancelled)).
+		Group("currency").
+		Order("currency").
+		Scan(&summary.TotalSpend).Error
+	if err != nil {
+		return summary, err
+	}
+
+	err = r.orders(r.db).
+		Select("delivery_service").
+		Where("customer_id = ?", customerID).
+		Group("delivery_service").
+		Order("count(*) DESC, max(date_created) DESC").
+		Limit(1).
+		Scan(&summary.PreferredDeliveryService).Error
+	return summary, err
+}
+
+// erasedDelivery — поля доставки, которые стираются AnonymizeCustomer.
+var erasedDelivery = map[string]any{
+	"name":    models.ErasedValue,
+	"phone":   models.ErasedValue,
+	"email":   models.ErasedValue,
+	"address": models.ErasedValue,
+}
+
+func (r *customerRepo) AnonymizeCustomer(customerID string) ([]string, error) {
+	var uids []string
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		err := r.orders(tx).Unscoped().
+			Where("customer_id = ?", customerID).
+			Order("order_uid").
+			Pluck("order_uid", &uids).Error
+		if err != nil || len(uids) == 0 {
+			return err
+		}
+
+		// Метки стирания пишутся открыто, а слепые индексы очищаются, чтобы
+		// заказы больше не находились по старому телефону и email.
+		erased := map[string]any{"phone_index": "", "email_index": ""}
+		maps.Copy(erased, erasedDelivery)
+		err = tx.Model(&models.Delivery{}).
+			Where("order_uid IN ?", uids).
+			UpdateColumns(erased).Error
+		if err != nil {
+			return err
+		}
+
+		for _, uid := range uids {
+			err := appendOutboxEvent(tx, uid, models.EventOrderErased, models.ErasedEvent{
+				OrderUID:   uid,
+				CustomerID: customerID,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return uids, nil
+}
@@ -0,0 +1,65 @@
+//go:generate go run go.uber.org/mock/mockgen -source=encryption.go -destination=mocks/mock_encryption_repository.go -package=mocks
+package repository
+
+import (
+	"errors"
+	"orderkeeper/internal/models"
+	"orderkeeper/internal/pii"
+
+	"gorm.io/gorm"
+)
+
+type EncryptionRepository interface {
+	// ReencryptDeliveries перешифровывает текущим ключом до limit записей
+	// доставки с id больше afterID, которые хранятся открыто или
+	// зашифрованы другим ключом, и пересчитывает их слепые индексы.
+	// Возвращает id последней перешифрованной записи и их число; 0 записей
+	// значит, что перешифровывать больше нечего.
+	ReencryptDeliveries(afterID uint, limit int) (uint, int, error)
+}
+
+type encryptionRepo struct {
+	db *gorm.DB
+}
+
+func NewEncryptionRepository(db *gorm.DB) EncryptionRepository {
+	return &encryptionRepo{db: db}
+}
+
+func (r *encryptionRepo) ReencryptDeliveries(afterID uint, limit int) (uint, int, error) {
+	c := pii.Current()
+	if c == nil {
+		return afterID, 0, errors.New("PII encryption is not configured")
+	}
+	prefix := c.KeyPrefix()
+	var deliveries []models.Delivery
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		// Сравнение через left, а не LIKE: в ID ключа могут быть _ и %.
+		var ids []uint
+		err := tx.Model(&models.Delivery{}).
+			Where("id > ?", afterID).
+			Where("left(name, @n) <> @prefix OR left(phone, @n) <> @prefix OR left(email, @n) <> @prefix OR left(address, @n) <> @prefix",
+				map[string]any{"n": len(prefix), "prefix": prefix}).
+			Order("id").
+			Limit(limit).
+			Pluck("id", &ids).Error
+		if err != nil || len(ids) == 0 {
+			return err
+		}
+		if err := tx.Order("id").Find(&deliveries, ids).Error; err != nil {
+			return err
+		}
+		for i := range deliveries {
+			// Save шифрует поля текущим ключом, а BeforeSave пересчитывает
+			// слепые индексы.
+			if err := tx.Save(&deliveries[i]).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil || len(deliveries) == 0 {
+		return afterID, 0, err
+	}
+	return deliveries[len(deliveries)-1].ID, len(deliveries), nil
+}
@@ -0,0 +1,102 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: customer.go
+//
+// Generated by this command:
+//
+//	mockgen -source=customer.go -destination=mocks/mock_customer_repository.go -package=mocks
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	models "orderkeeper/internal/models"
+	repository "orderkeeper/internal/repository"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockCustomerRepository is a mock of CustomerRepository interface.
+type MockCustomerRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockCustomerRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockCustomerRepositoryMockRecorder is the mock recorder for MockCustomerRepository.
+type MockCustomerRepositoryMockRecorder struct {
+	mock *MockCustomerRepository
+}
+
+// NewMockCustomerRepository creates a new mock instance.
+func NewMockCustomerRepository(ctrl *gomock.Controller) *MockCustomerRepository {
+	mock := &MockCustomerRepository{ctrl: ctrl}
+	mock.recorder = &MockCustomerRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCustomerRepository) EXPECT() *MockCustomerRepositoryMockRecorder {
+	return m.recorder
+}
+
+// AnonymizeCustomer mocks base method.
+func (m *MockCustomerRepository) AnonymizeCustomer(customerID string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AnonymizeCustomer", customerID)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AnonymizeCustomer indicates an expected call of AnonymizeCustomer.
+func (mr *MockCustomerRepositoryMockRecorder) AnonymizeCustomer(customerID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AnonymizeCustomer", reflect.TypeOf((*MockCustomerRepository)(nil).AnonymizeCustomer), customerID)
+}
+
+// ForTenant mocks base method.
+func (m *MockCustomerRepository) ForTenant(tenant string) repository.CustomerRepository {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ForTenant", tenant)
+	ret0, _ := ret[0].(repository.CustomerRepository)
+	return ret0
+}
+
+// ForTenant indicates an expected call of ForTenant.
+func (mr *MockCustomerRepositoryMockRecorder) ForTenant(tenant any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ForTenant", reflect.TypeOf((*MockCustomerRepository)(nil).ForTenant), tenant)
+}
+
+// GetCustomerSummary mocks base method.
+func (m *MockCustomerRepository) GetCustomerSummary(customerID string) (models.CustomerSummary, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCustomerSummary", customerID)
+	ret0, _ := ret[0].(models.CustomerSummary)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCustomerSummary indicates an expected call of GetCustomerSummary.
+func (mr *MockCustomerRepositoryMockRecorder) GetCustomerSummary(customerID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCustomerSummary", reflect.TypeOf((*MockCustomerRepository)(nil).GetCustomerSummary), customerID)
+}
+
+// GetOrdersByCustomer mocks base method.
+func (m *MockCustomerRepository) GetOrdersByCustomer(customerID string, limit, offset int) ([]models.Order, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOrdersByCustomer", customerID, limit, offset)
+	ret0, _ := ret[0].([]models.Order)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetOrdersByCustomer indicates an expected call of GetOrdersByCustomer.
+func (mr *MockCustomerRepositoryMockRecorder) GetOrdersByCustomer(customerID, limit, offset any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrdersByCustomer", reflect.TypeOf((*MockCustomerRepository)(nil).GetOrdersByCustomer), customerID, limit, offset)
+}
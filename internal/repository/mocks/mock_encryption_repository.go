@@ -0,0 +1,56 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: encryption.go
+//
+// Generated by this command:
+//
+//	mockgen -source=encryption.go -destination=mocks/mock_encryption_repository.go -package=mocks
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockEncryptionRepository is a mock of EncryptionRepository interface.
+type MockEncryptionRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockEncryptionRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockEncryptionRepositoryMockRecorder is the mock recorder for MockEncryptionRepository.
+type MockEncryptionRepositoryMockRecorder struct {
+	mock *MockEncryptionRepository
+}
+
+// NewMockEncryptionRepository creates a new mock instance.
+func NewMockEncryptionRepository(ctrl *gomock.Controller) *MockEncryptionRepository {
+	mock := &MockEncryptionRepository{ctrl: ctrl}
+	mock.recorder = &MockEncryptionRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockEncryptionRepository) EXPECT() *MockEncryptionRepositoryMockRecorder {
+	return m.recorder
+}
+
+// ReencryptDeliveries mocks base method.
+func (m *MockEncryptionRepository) ReencryptDeliveries(afterID uint, limit int) (uint, int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReencryptDeliveries", afterID, limit)
+	ret0, _ := ret[0].(uint)
+	ret1, _ := ret[1].(int)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ReencryptDeliveries indicates an expected call of ReencryptDeliveries.
+func (mr *MockEncryptionRepositoryMockRecorder) ReencryptDeliveries(afterID, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReencryptDeliveries", reflect.TypeOf((*MockEncryptionRepository)(nil).ReencryptDeliveries), afterID, limit)
+}
@@ -11,6 +11,7 @@ package mocks
 
 import (
 	models "orderkeeper/internal/models"
+	repository "orderkeeper/internal/repository"
 	reflect "reflect"
 
 	gomock "go.uber.org/mock/gomock"
@@ -40,6 +41,20 @@ func (m *MockOrderRepository) EXPECT() *MockOrderRepositoryMockRecorder {
 	return m.recorder
 }
 
+// CancelOrder mocks base method.
+func (m *MockOrderRepository) CancelOrder(id, reason string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelOrder", id, reason)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CancelOrder indicates an expected call of CancelOrder.
+func (mr *MockOrderRepositoryMockRecorder) CancelOrder(id, reason any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelOrder", reflect.TypeOf((*MockOrderRepository)(nil).CancelOrder), id, reason)
+}
+
 // CreateOrder mocks base method.
 func (m *MockOrderRepository) CreateOrder(order models.Order) error {
 	m.ctrl.T.Helper()
@@ -54,19 +69,32 @@ func (mr *MockOrderRepositoryMockRecorder) CreateOrder(order any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOrder", reflect.TypeOf((*MockOrderRepository)(nil).CreateOrder), order)
 }
 
-// GetAllOrders mocks base method.
-func (m *MockOrderRepository) GetAllOrders() ([]models.Order, error) {
+// DeleteOrder mocks base method.
+func (m *MockOrderRepository) DeleteOrder(id string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetAllOrders")
-	ret0, _ := ret[0].([]models.Order)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret := m.ctrl.Call(m, "DeleteOrder", id)
+	ret0, _ := ret[0].(error)
+	return ret0
 }
 
-// GetAllOrders indicates an expected call of GetAllOrders.
-func (mr *MockOrderRepositoryMockRecorder) GetAllOrders() *gomock.Call {
+// DeleteOrder indicates an expected call of DeleteOrder.
+func (mr *MockOrderRepositoryMockRecorder) DeleteOrder(id any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllOrders", reflect.TypeOf((*MockOrderRepository)(nil).GetAllOrders))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteOrder", reflect.TypeOf((*MockOrderRepository)(nil).DeleteOrder), id)
+}
+
+// ForTenant mocks base method.
+func (m *MockOrderRepository) ForTenant(tenant string) repository.OrderRepository {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ForTenant", tenant)
+	ret0, _ := ret[0].(repository.OrderRepository)
+	return ret0
+}
+
+// ForTenant indicates an expected call of ForTenant.
+func (mr *MockOrderRepositoryMockRecorder) ForTenant(tenant any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ForTenant", reflect.TypeOf((*MockOrderRepository)(nil).ForTenant), tenant)
 }
 
 // GetOrderByID mocks base method.
@@ -83,3 +111,46 @@ func (mr *MockOrderRepositoryMockRecorder) GetOrderByID(id any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrderByID", reflect.TypeOf((*MockOrderRepository)(nil).GetOrderByID), id)
 }
+
+// GetOrderByTrack mocks base method.
+func (m *MockOrderRepository) GetOrderByTrack(track string) (models.Order, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOrderByTrack", track)
+	ret0, _ := ret[0].(models.Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOrderByTrack indicates an expected call of GetOrderByTrack.
+func (mr *MockOrderRepositoryMockRecorder) GetOrderByTrack(track any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrderByTrack", reflect.TypeOf((*MockOrderRepository)(nil).GetOrderByTrack), track)
+}
+
+// IterateOrders mocks base method.
+func (m *MockOrderRepository) IterateOrders(filter models.OrderFilter, batchSize int, fn func([]models.Order) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IterateOrders", filter, batchSize, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// IterateOrders indicates an expected call of IterateOrders.
+func (mr *MockOrderRepositoryMockRecorder) IterateOrders(filter, batchSize, fn any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IterateOrders", reflect.TypeOf((*MockOrderRepository)(nil).IterateOrders), filter, batchSize, fn)
+}
+
+// UpdateOrderStatus mocks base method.
+func (m *MockOrderRepository) UpdateOrderStatus(id, status string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateOrderStatus", id, status)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateOrderStatus indicates an expected call of UpdateOrderStatus.
+func (mr *MockOrderRepositoryMockRecorder) UpdateOrderStatus(id, status any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateOrderStatus", reflect.TypeOf((*MockOrderRepository)(nil).UpdateOrderStatus), id, status)
+}
@@ -0,0 +1,72 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: report.go
+//
+// Generated by this command:
+//
+//	mockgen -source=report.go -destination=mocks/mock_report_repository.go -package=mocks
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	models "orderkeeper/internal/models"
+	repository "orderkeeper/internal/repository"
+	reflect "reflect"
+	time "time"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockReportRepository is a mock of ReportRepository interface.
+type MockReportRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockReportRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockReportRepositoryMockRecorder is the mock recorder for MockReportRepository.
+type MockReportRepositoryMockRecorder struct {
+	mock *MockReportRepository
+}
+
+// NewMockReportRepository creates a new mock instance.
+func NewMockReportRepository(ctrl *gomock.Controller) *MockReportRepository {
+	mock := &MockReportRepository{ctrl: ctrl}
+	mock.recorder = &MockReportRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockReportRepository) EXPECT() *MockReportRepositoryMockRecorder {
+	return m.recorder
+}
+
+// ForTenant mocks base method.
+func (m *MockReportRepository) ForTenant(tenant string) repository.ReportRepository {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ForTenant", tenant)
+	ret0, _ := ret[0].(repository.ReportRepository)
+	return ret0
+}
+
+// ForTenant indicates an expected call of ForTenant.
+func (mr *MockReportRepositoryMockRecorder) ForTenant(tenant any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ForTenant", reflect.TypeOf((*MockReportRepository)(nil).ForTenant), tenant)
+}
+
+// OrdersPerDay mocks base method.
+func (m *MockReportRepository) OrdersPerDay(from, to time.Time, groupBy string) ([]models.ReportRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "OrdersPerDay", from, to, groupBy)
+	ret0, _ := ret[0].([]models.ReportRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// OrdersPerDay indicates an expected call of OrdersPerDay.
+func (mr *MockReportRepositoryMockRecorder) OrdersPerDay(from, to, groupBy any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OrdersPerDay", reflect.TypeOf((*MockReportRepository)(nil).OrdersPerDay), from, to, groupBy)
+}
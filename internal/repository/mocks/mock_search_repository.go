@@ -0,0 +1,86 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: search.go
+//
+// Generated by this command:
+//
+//	mockgen -source=search.go -destination=mocks/mock_search_repository.go -package=mocks
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	models "orderkeeper/internal/models"
+	repository "orderkeeper/internal/repository"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockSearchRepository is a mock of SearchRepository interface.
+type MockSearchRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockSearchRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockSearchRepositoryMockRecorder is the mock recorder for MockSearchRepository.
+type MockSearchRepositoryMockRecorder struct {
+	mock *MockSearchRepository
+}
+
+// NewMockSearchRepository creates a new mock instance.
+func NewMockSearchRepository(ctrl *gomock.Controller) *MockSearchRepository {
+	mock := &MockSearchRepository{ctrl: ctrl}
+	mock.recorder = &MockSearchRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSearchRepository) EXPECT() *MockSearchRepositoryMockRecorder {
+	return m.recorder
+}
+
+// ForTenant mocks base method.
+func (m *MockSearchRepository) ForTenant(tenant string) repository.SearchRepository {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ForTenant", tenant)
+	ret0, _ := ret[0].(repository.SearchRepository)
+	return ret0
+}
+
+// ForTenant indicates an expected call of ForTenant.
+func (mr *MockSearchRepositoryMockRecorder) ForTenant(tenant any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ForTenant", reflect.TypeOf((*MockSearchRepository)(nil).ForTenant), tenant)
+}
+
+// SearchOrders mocks base method.
+func (m *MockSearchRepository) SearchOrders(query string, limit, offset int) ([]models.SearchHit, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchOrders", query, limit, offset)
+	ret0, _ := ret[0].([]models.SearchHit)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// SearchOrders indicates an expected call of SearchOrders.
+func (mr *MockSearchRepositoryMockRecorder) SearchOrders(query, limit, offset any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchOrders", reflect.TypeOf((*MockSearchRepository)(nil).SearchOrders), query, limit, offset)
+}
+
+// WithoutPII mocks base method.
+func (m *MockSearchRepository) WithoutPII() repository.SearchRepository {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WithoutPII")
+	ret0, _ := ret[0].(repository.SearchRepository)
+	return ret0
+}
+
+// WithoutPII indicates an expected call of WithoutPII.
+func (mr *MockSearchRepositoryMockRecorder) WithoutPII() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithoutPII", reflect.TypeOf((*MockSearchRepository)(nil).WithoutPII))
+}
@@ -2,50 +2,196 @@
 package repository
 
 import (
+	"fmt"
 	"orderkeeper/internal/models"
+	"orderkeeper/internal/tenant"
 
 	"gorm.io/gorm"
 )
 
 type OrderRepository interface {
 	CreateOrder(order models.Order) error
-	GetAllOrders() ([]models.Order, error)
+	// IterateOrders передает fn заказы, подходящие под filter, пачками по
+	// batchSize в порядке order_uid. Итерация прекращается при ошибке fn.
+	IterateOrders(filter models.OrderFilter, batchSize int, fn func([]models.Order) error) error
 	GetOrderByID(id string) (models.Order, error)
+	// GetOrderByTrack ищет заказ по трек-номеру заказа или одной из позиций.
+	GetOrderByTrack(track string) (models.Order, error)
+	// UpdateOrderStatus и CancelOrder меняют статус одним запросом, только
+	// если текущий статус входит в models.OrderStatusesBefore. Иначе
+	// возвращается *StatusConflictError с текущим статусом.
+	UpdateOrderStatus(id, status string) error
+	CancelOrder(id, reason string) error
+	// DeleteOrder мягко удаляет заказ: строки остаются в БД, но заказ больше
+	// не находится ни одним запросом.
+	DeleteOrder(id string) error
+	// ForTenant возвращает репозиторий, который видит и меняет только заказы
+	// площадки tenant.
+	ForTenant(tenant string) OrderRepository
+}
+
+// StatusConflictError — заказ есть, но из его статуса нельзя перейти в
+// запрошенный.
+type StatusConflictError struct {
+	Status string
+}
+
+func (e *StatusConflictError) Error() string {
+	return fmt.Sprintf("order status is %q", e.Status)
 }
 
 type orderRepo struct {
-	db *gorm.DB
+	db     *gorm.DB
+	tenant string
 }
 
 func NewOrderRepository(db *gorm.DB) OrderRepository {
 	return &orderRepo{db: db}
 }
 
+func (r *orderRepo) ForTenant(tenant string) OrderRepository {
+	return &orderRepo{db: r.db, tenant: tenant}
+}
+
+// orders начинает запрос к заказам площадки репозитория.
+func (r *orderRepo) orders(db *gorm.DB) *gorm.DB {
+	return db.Model(&models.Order{}).Scopes(tenant.Scope(r.tenant))
+}
+
 func (r *orderRepo) CreateOrder(order models.Order) error {
+	if err := tenant.Check(r.tenant, order.Entry); err != nil {
+		return err
+	}
 	return r.db.Transaction(func(tx *gorm.DB) error {
 		if err := tx.Create(&order).Error; err != nil {
 			return err
 		}
-		return nil
+		return appendOutboxEvent(tx, order.OrderUID, models.EventOrderCreated, models.NewOrderCreatedEvent(order))
 	})
 }
 
-func (r *orderRepo) GetAllOrders() ([]models.Order, error) {
-	var orders []models.Order
-	err := r.db.
+func (r *orderRepo) IterateOrders(filter models.OrderFilter, batchSize int, fn func([]models.Order) error) error {
+	after := filter.After
+	for {
+		q := r.orders(r.db).
+			Preload("Delivery").
+			Preload("Payment").
+			Preload("Items").
+			Where("order_uid > ?", after)
+		if !filter.From.IsZero() {
+			q = q.Where("date_created >= ?", filter.From)
+		}
+		if !filter.To.IsZero() {
+			q = q.Where("date_created < ?", filter.To)
+		}
+		if filter.Status != "" {
+			q = q.Where("status = ?", filter.Status)
+		}
+		if filter.CustomerID != "" {
+			q = q.Where("customer_id = ?", filter.CustomerID)
+		}
+		if filter.NeedsReview != nil {
+			q = q.Where("needs_review = ?", *filter.NeedsReview)
+		}
+
+		var orders []models.Order
+		if err := q.Order("order_uid").Limit(batchSize).Find(&orders).Error; err != nil {
+			return err
+		}
+		if len(orders) == 0 {
+			return nil
+		}
+		if err := fn(orders); err != nil {
+			return err
+		}
+		if len(orders) < batchSize {
+			return nil
+		}
+		after = orders[len(orders)-1].OrderUID
+	}
+}
+
+func (r *orderRepo) GetOrderByID(id string) (models.Order, error) {
+	var order models.Order
+	err := r.orders(r.db).
 		Preload("Delivery").
 		Preload("Payment").
 		Preload("Items").
-		Find(&orders).Error
-	return orders, err
+		First(&order, "order_uid = ?", id).Error
+	return order, err
 }
 
-func (r *orderRepo) GetOrderByID(id string) (models.Order, error) {
+func (r *orderRepo) GetOrderByTrack(track string) (models.Order, error) {
 	var order models.Order
-	err := r.db.
+	err := r.orders(r.db).
 		Preload("Delivery").
 		Preload("Payment").
 		Preload("Items").
-		First(&order, "order_uid = ?", id).Error
+		Where("track_number = ? OR order_uid IN (?)", track,
+			r.db.Model(&models.Item{}).Select("order_uid").Where("track_number = ?", track)).
+		Order("date_created DESC").
+		First(&order).Error
 	return order, err
 }
+
+func (r *orderRepo) UpdateOrderStatus(id, status string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		res := r.orders(tx).
+			Where("order_uid = ? AND status IN ?", id, models.OrderStatusesBefore(status)).
+			Update("status", status)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return r.statusConflict(tx, id)
+		}
+		return appendOutboxEvent(tx, id, models.EventOrderStatusChanged, models.StatusChangedEvent{
+			OrderUID: id,
+			Status:   status,
+		})
+	})
+}
+
+func (r *orderRepo) CancelOrder(id, reason string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		res := r.orders(tx).
+			Where("order_uid = ? AND status IN ?", id, models.OrderStatusesBefore(models.OrderStatusCancelled)).
+			Updates(map[string]any{"status": models.OrderStatusCancelled, "cancel_reason": reason})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return r.statusConflict(tx, id)
+		}
+		return appendOutboxEvent(tx, id, models.EventOrderCancelled, models.CancelledEvent{
+			OrderUID: id,
+			Reason:   reason,
+		})
+	})
+}
+
+func (r *orderRepo) DeleteOrder(id string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		res := r.orders(tx).Where("order_uid = ?", id).Delete(&models.Order{})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return appendOutboxEvent(tx, id, models.EventOrderDeleted, models.DeletedEvent{OrderUID: id})
+	})
+}
+
+// statusConflict объясняет, почему смена статуса не затронула ни одной
+// строки: заказа нет или его статус не допускает перехода.
+func (r *orderRepo) statusConflict(tx *gorm.DB, id string) error {
+	var statuses []string
+	if err := r.orders(tx).Where("order_uid = ?", id).Pluck("status", &statuses).Error; err != nil {
+		return err
+	}
+	if len(statuses) == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return &StatusConflictError{Status: statuses[0]}
+}
@@ -0,0 +1,100 @@
+//go:generate go run go.uber.org/mock/mockgen -source=outbox.go -destination=mocks/mock_outbox_repository.go -package=mocks
+package repository
+
+import (
+	"encoding/json"
+	"fmt"
+	"orderkeeper/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PublishFunc публикует пачку событий и возвращает ошибку, если ни одно из
+// них не должно считаться отправленным.
+type PublishFunc func(events []models.OutboxEvent) error
+
+type OutboxRepository interface {
+	// PublishPending блокирует до limit неотправленных событий, передает их
+	// publish и в той же транзакции помечает отправленными. Транзакция открыта, пока идет
+	// publish, так что он должен быть ограничен по времени. Возвращает число
+	// обработанных событий.
+	PublishPending(limit int, publish PublishFunc) (int, error)
+}
+
+type outboxRepo struct {
+	db *gorm.DB
+}
+
+func NewOutboxRepository(db *gorm.DB) OutboxRepository {
+	return &outboxRepo{db: db}
+}
+
+func (r *outboxRepo) PublishPending(limit int, publish PublishFunc) (int, error) {
+	var events []models.OutboxEvent
+	var publishErr error
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		// SKIP LOCKED позволяет нескольким инстансам разбирать outbox параллельно.
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("sent_at IS NULL").
+			Order("id").
+			Limit(limit).
+			Find(&events).Error
+		if err != nil || len(events) == 0 {
+			return err
+		}
+
+		ids := make([]uint, len(events))
+		for i, e := range events {
+			ids[i] = e.ID
+		}
+
+		if publishErr = publish(events); publishErr != nil {
+			return tx.Model(&models.OutboxEvent{}).
+				Where("id IN ?", ids).
+				Updates(map[string]any{
+					"attempts":   gorm.Expr("attempts + 1"),
+					"last_error": publishErr.Error(),
+				}).Error
+		}
+		return tx.Model(&models.OutboxEvent{}).
+			Where("id IN ?", ids).
+			Updates(map[string]any{
+				"sent_at":    time.Now().UTC(),
+				"attempts":   gorm.Expr("attempts + 1"),
+				"last_error": "",
+			}).Error
+	})
+	if err != nil {
+		return 0, err
+	}
+	if publishErr != nil {
+		return 0, publishErr
+	}
+	return len(events), nil
+}
+
+// appendOutboxEvent записывает событие заказа aggregateID в транзакции tx.
+// Площадка берется из заказа, поэтому он должен существовать в tx, в том
+// числе мягко удаленным.
+func appendOutboxEvent(tx *gorm.DB, aggregateID, eventType string, payload any) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	var tenants []string
+	err = tx.Raw(`SELECT entry FROM orders WHERE order_uid = ?`, aggregateID).Scan(&tenants).Error
+	if err != nil {
+		return err
+	}
+	if len(tenants) == 0 {
+		return fmt.Errorf("outbox event %s: %w", eventType, gorm.ErrRecordNotFound)
+	}
+	return tx.Create(&models.OutboxEvent{
+		AggregateID: aggregateID,
+		Tenant:      tenants[0],
+		EventType:   eventType,
+		Payload:     data,
+	}).Error
+}
@@ -0,0 +1,90 @@
+//go:generate go run go.uber.org/mock/mockgen -source=report.go -destination=mocks/mock_report_repository.go -package=mocks
+package repository
+
+import (
+	"fmt"
+	"orderkeeper/internal/models"
+	"orderkeeper/internal/tenant"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type reportDimension struct {
+	column  string
+	revenue string
+	joins   string
+}
+
+var reportDimensions = map[string]reportDimension{
+	models.ReportByCurrency:        {column: "p.currency", revenue: "p.amount"},
+	models.ReportByDeliveryService: {column: "o.delivery_service", revenue: "p.amount"},
+	models.ReportByProvider:        {column: "p.provider", revenue: "p.amount"},
+	models.ReportByRegion: {
+		column:  "d.region",
+		revenue: "p.amount",
+		joins:   "JOIN deliveries d ON d.order_uid = o.order_uid",
+	},
+	models.ReportByBrand: {
+		column:  "i.brand",
+		revenue: "i.total_price",
+		joins:   "JOIN items i ON i.order_uid = o.order_uid",
+	},
+}
+
+// IsReportDimension сообщает, поддерживается ли группировка groupBy.
+func IsReportDimension(groupBy string) bool {
+	_, ok := reportDimensions[groupBy]
+	return ok
+}
+
+type ReportRepository interface {
+	// OrdersPerDay считает неотмененные заказы и выручку по дням UTC в
+	// полуинтервале [from, to), сгруппированные по измерению groupBy и валюте.
+	OrdersPerDay(from, to time.Time, groupBy string) ([]models.ReportRow, error)
+	// ForTenant возвращает репозиторий, который считает только заказы
+	// площадки tenant.
+	ForTenant(tenant string) ReportRepository
+}
+
+type reportRepo struct {
+	db     *gorm.DB
+	tenant string
+}
+
+func NewReportRepository(db *gorm.DB) ReportRepository {
+	return &reportRepo{db: db}
+}
+
+func (r *reportRepo) ForTenant(tenant string) ReportRepository {
+	return &reportRepo{db: r.db, tenant: tenant}
+}
+
+func (r *reportRepo) OrdersPerDay(from, to time.Time, groupBy string) ([]models.ReportRow, error) {
+	dim, ok := reportDimensions[groupBy]
+	if !ok {
+		return nil, fmt.Errorf("unknown report dimension %q", groupBy)
+	}
+
+	// Столбцы измерений берутся только из reportDimensions, пользовательский
+	// ввод в текст запроса не попадает.
+	query := fmt.Sprintf(`SELECT
+			to_char(o.date_created AT TIME ZONE 'UTC', 'YYYY-MM-DD') AS day,
+			coalesce(%[1]s, '') AS "group",
+			p.currency,
+			count(DISTINCT o.order_uid) AS orders,
+			sum(%[2]s) AS revenue
+		FROM (?) o
+		JOIN payments p ON p.order_uid = o.order_uid
+		%[3]s
+		WHERE o.date_created >= ? AND o.date_created < ? AND o.status <> ?
+		GROUP BY 1, 2, 3
+		ORDER BY 1, 2, 3`, dim.column, dim.revenue, dim.joins)
+
+	var rows []models.ReportRow
+	// Заказы берутся подзапросом, чтобы к ним применились ограничение
+	// площадки и мягкое удаление.
+	orders := r.db.Model(&models.Order{}).Scopes(tenant.Scope(r.tenant))
+	err := r.db.Raw(query, orders, from, to, models.OrderStatusCancelled).Scan(&rows).Error
+	return rows, err
+}
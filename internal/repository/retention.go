@@ -0,0 +1,88 @@
+//go:generate go run go.uber.org/mock/mockgen -source=retention.go -destination=mocks/mock_retention_repository.go -package=mocks
+package repository
+
+import (
+	"orderkeeper/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type RetentionRepository interface {
+	// ArchiveOrders мягко удаляет до limit самых старых заказов, созданных
+	// раньше before, пишет для каждого событие order.deleted, как при
+	// DELETE /order/{id}, и возвращает их order_uid.
+	ArchiveOrders(before time.Time, limit int) ([]string, error)
+	// PurgeOrders безвозвратно удаляет до limit самых старых заказов,
+	// созданных раньше before, включая архивированные, вместе с доставкой,
+	// оплатой, позициями и уже отправленными событиями outbox, пишет для
+	// каждого событие order.deleted и возвращает их order_uid.
+	PurgeOrders(before time.Time, limit int) ([]string, error)
+}
+
+type retentionRepo struct {
+	db *gorm.DB
+}
+
+func NewRetentionRepository(db *gorm.DB) RetentionRepository {
+	return &retentionRepo{db: db}
+}
+
+func (r *retentionRepo) ArchiveOrders(before time.Time, limit int) ([]string, error) {
+	var uids []string
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Raw(`UPDATE orders SET deleted_at = now()
+			WHERE order_uid IN (
+				SELECT order_uid FROM orders
+				WHERE date_created < ? AND deleted_at IS NULL
+				ORDER BY date_created
+				LIMIT ?
+				FOR UPDATE SKIP LOCKED
+			)
+			RETURNING order_uid`, before, limit).Scan(&uids).Error
+		if err != nil {
+			return err
+		}
+		for _, uid := range uids {
+			if err := appendOutboxEvent(tx, uid, models.EventOrderDeleted, models.DeletedEvent{OrderUID: uid}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return uids, nil
+}
+
+func (r *retentionRepo) PurgeOrders(before time.Time, limit int) ([]string, error) {
+	var uids []string
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Unscoped().Model(&models.Order{}).
+			Where("date_created < ?", before).
+			Order("date_created").
+			Limit(limit).
+			Pluck("order_uid", &uids).Error
+		if err != nil || len(uids) == 0 {
+			return err
+		}
+		// Неотправленные события остаются до публикации, а новые пишутся до
+		// удаления заказов: площадка события берется из заказа.
+		err = tx.Where("aggregate_id IN ? AND sent_at IS NOT NULL", uids).Delete(&models.OutboxEvent{}).Error
+		if err != nil {
+			return err
+		}
+		for _, uid := range uids {
+			if err := appendOutboxEvent(tx, uid, models.EventOrderDeleted, models.DeletedEvent{OrderUID: uid}); err != nil {
+				return err
+			}
+		}
+		// Доставка, оплата и позиции удаляются каскадом по внешним ключам.
+		return tx.Unscoped().Where("order_uid IN ?", uids).Delete(&models.Order{}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return uids, nil
+}
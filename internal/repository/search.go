@@ -0,0 +1,164 @@
+//go:generate go run go.uber.org/mock/mockgen -source=search.go -destination=mocks/mock_search_repository.go -package=mocks
+package repository
+
+import (
+	"orderkeeper/internal/models"
+	"orderkeeper/internal/pii"
+	"orderkeeper/internal/tenant"
+	"strings"
+	"unicode"
+
+	"gorm.io/gorm"
+)
+
+// minPhoneDigits — сколько цифр должно быть в запросе, чтобы искать по
+// части номера телефона.
+const minPhoneDigits = 4
+
+const headlineOptions = "StartSel=<mark>, StopSel=</mark>, MaxFragments=3, MinWords=3, MaxWords=12, FragmentDelimiter=\" … \""
+
+type SearchRepository interface {
+	// SearchOrders ищет заказы по словам запроса. Каждое слово — префикс,
+	// все слова должны встретиться; цифры запроса дополнительно ищутся в
+	// номере телефона, а запрос с @ — среди email. Пустой запрос ничего не
+	// находит.
+	SearchOrders(query string, limit, offset int) ([]models.SearchHit, int64, error)
+	// ForTenant возвращает репозиторий, который ищет только среди заказов
+	// площадки tenant.
+	ForTenant(tenant string) SearchRepository
+	// WithoutPII возвращает репозиторий, который не ищет по данным
+	// получателя: только по городу и позициям заказа.
+	WithoutPII() SearchRepository
+}
+
+type searchRepo struct {
+	db     *gorm.DB
+	tenant string
+	noPII  bool
+}
+
+func NewSearchRepository(db *gorm.DB) SearchRepository {
+	return &searchRepo{db: db}
+}
+
+func (r *searchRepo) ForTenant(tenant string) SearchRepository {
+	return &searchRepo{db: r.db, tenant: tenant, noPII: r.noPII}
+}
+
+func (r *searchRepo) WithoutPII() SearchRepository {
+	return &searchRepo{db: r.db, tenant: r.tenant, noPII: true}
+}
+
+// orders начинает запрос к заказам площадки репозитория.
+func (r *searchRepo) orders() *gorm.DB {
+	return r.db.Model(&models.Order{}).Scopes(tenant.Scope(r.tenant))
+}
+
+func (r *searchRepo) SearchOrders(query string, limit, offset int) ([]models.SearchHit, int64, error) {
+	tsQuery, digits := buildSearchQuery(query)
+	vector := "o.search_vector"
+	if r.noPII {
+		vector, digits = "o.search_public", ""
+	}
+	if tsQuery == "" && digits == "" {
+		return nil, 0, nil
+	}
+	args := map[string]any{"orders": r.orders(), "query": tsQuery, "digits": digits, "phone_index": "", "email_index": ""}
+	// Зашифрованные телефоны и email ищутся только по точному совпадению
+	// через слепые индексы.
+	if digits != "" {
+		args["phone_index"] = pii.PhoneIndex(digits)
+	}
+	if strings.Contains(query, "@") && !r.noPII {
+		args["email_index"] = pii.EmailIndex(query)
+	}
+	// Заказы берутся подзапросом, чтобы к ним применились ограничение
+	// площадки и мягкое удаление.
+	match := `FROM (@orders) o, to_tsquery('simple', @query) q
+		WHERE ` + vector + ` @@ q
+			OR (@digits <> '' AND o.search_phone LIKE '%' || @digits || '%')
+			OR o.order_uid IN (
+				SELECT order_uid FROM deliveries
+				WHERE (@phone_index <> '' AND phone_index = @phone_index)
+					OR (@email_index <> '' AND email_index = @email_index)
+			)`
+
+	var total int64
+	if err := r.db.Raw(`SELECT count(*) `+match, args).Scan(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	if total == 0 {
+		return nil, 0, nil
+	}
+
+	var rows []struct {
+		OrderUID  string
+		Rank      float64
+		Highlight string
+	}
+	args["limit"], args["offset"] = limit, offset
+	args["options"] = headlineOptions
+	err := r.db.Raw(`SELECT o.order_uid, ts_rank(`+vector+`, q) AS rank,
+			ts_headline('simple', coalesce(o.search_text, ''), q, @options) AS highlight `+match+`
+		ORDER BY rank DESC, o.date_created DESC, o.order_uid
+		LIMIT @limit OFFSET @offset`, args).Scan(&rows).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	uids := make([]string, len(rows))
+	for i, row := range rows {
+		uids[i] = row.OrderUID
+	}
+	var orders []models.Order
+	err = r.orders().
+		Preload("Delivery").
+		Preload("Payment").
+		Preload("Items").
+		Where("order_uid IN ?", uids).
+		Find(&orders).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	byUID := make(map[string]models.Order, len(orders))
+	for _, order := range orders {
+		byUID[order.OrderUID] = order
+	}
+
+	hits := make([]models.SearchHit, 0, len(rows))
+	for _, row := range rows {
+		if order, ok := byUID[row.OrderUID]; ok {
+			hits = append(hits, models.SearchHit{Order: order, Rank: row.Rank, Highlight: row.Highlight})
+		}
+	}
+	return hits, total, nil
+}
+
+// buildSearchQuery превращает пользовательский ввод в tsquery из префиксов
+// слов ("ivan:* & 0000:*") и отдельно собирает цифры для поиска по номеру
+// телефона. Все символы, кроме букв и цифр, считаются разделителями, так
+// что синтаксис tsquery во вводе не интерпретируется.
+func buildSearchQuery(input string) (tsQuery, digits string) {
+	words := strings.FieldsFunc(strings.ToLower(input), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	terms := make([]string, len(words))
+	for i, w := range words {
+		terms[i] = w + ":*"
+	}
+
+	var b strings.Builder
+	for _, r := range input {
+		if r >= '0' && r <= '9' {
+			b.WriteRune(r)
+		}
+	}
+	if b.Len() >= minPhoneDigits {
+		digits = b.String()
+	}
+	if len(terms) == 0 {
+		// to_tsquery('') ничего не находит, но остается валидным.
+		return "", digits
+	}
+	return strings.Join(terms, " & "), digits
+}
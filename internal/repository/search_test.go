@@ -0,0 +1,25 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildSearchQuery(t *testing.T) {
+	tests := []struct {
+		input, tsQuery, digits string
+	}{
+		{"Test Testov", "test:* & testov:*", ""},
+		{"  ИВАНОВ  ", "иванов:*", ""},
+		{"+972 000-0000", "972:* & 000:* & 0000:*", "9720000000"},
+		{"test@gmail.com", "test:* & gmail:* & com:*", ""},
+		{"a & !b | c:*", "a:* & b:* & c:*", ""},
+		{"!!!", "", ""},
+	}
+	for _, tt := range tests {
+		tsQuery, digits := buildSearchQuery(tt.input)
+		assert.Equal(t, tt.tsQuery, tsQuery, tt.input)
+		assert.Equal(t, tt.digits, digits, tt.input)
+	}
+}
@@ -0,0 +1,51 @@
+// Package retention периодически архивирует или удаляет старые заказы по
+// политике хранения.
+package retention
+
+import (
+	"context"
+	"log"
+	"orderkeeper/internal/service"
+	"time"
+)
+
+const defaultInterval = 24 * time.Hour
+
+type Job struct {
+	service  service.RetentionService
+	policy   service.RetentionPolicy
+	interval time.Duration
+}
+
+func NewJob(svc service.RetentionService, policy service.RetentionPolicy, interval time.Duration) *Job {
+	if interval <= 0 {
+		interval = defaultInterval
+	}
+	return &Job{service: svc, policy: policy, interval: interval}
+}
+
+// Run применяет политику сразу и затем раз в interval до отмены ctx.
+func (j *Job) Run(ctx context.Context) {
+	log.Printf("Retention job is running (%s orders older than %v every %v)", j.policy.Mode, j.policy.MaxAge, j.interval)
+	for {
+		if _, err := j.RunOnce(); err != nil {
+			log.Printf("Retention job failed: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			log.Println("Stopping retention job due to context cancellation")
+			return
+		case <-time.After(j.interval):
+		}
+	}
+}
+
+// RunOnce применяет политику один раз и возвращает число обработанных
+// заказов.
+func (j *Job) RunOnce() (int, error) {
+	n, err := j.service.ApplyRetention(j.policy, time.Now())
+	if n > 0 {
+		log.Printf("Retention: %d orders processed in %s mode", n, j.policy.Mode)
+	}
+	return n, err
+}
@@ -0,0 +1,87 @@
+package schemaregistry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// IndexFile — имя файла с описанием схем в каталоге FileClient.
+const IndexFile = "registry.json"
+
+type fileEntry struct {
+	ID      int        `json:"id"`
+	Subject string     `json:"subject"`
+	Version int        `json:"version"`
+	Type    SchemaType `json:"type"`
+	File    string     `json:"file"`
+	Message string     `json:"message"`
+}
+
+// FileClient — реестр схем на локальных файлах для тестов и работы без
+// сети. Каталог содержит registry.json со списком схем:
+//
+//	[{"id": 1, "subject": "orders-value", "version": 1, "type": "AVRO", "file": "order.avsc"},
+//	 {"id": 2, "subject": "orders-value", "version": 2, "type": "PROTOBUF", "file": "order.pb", "message": "orderkeeper.Order"}]
+//
+// Для Protobuf file указывает на FileDescriptorSet (protoc --descriptor_set_out).
+type FileClient struct {
+	byID      map[int]Schema
+	bySubject map[string]Schema
+}
+
+func NewFileClient(dir string) (*FileClient, error) {
+	raw, err := os.ReadFile(filepath.Join(dir, IndexFile))
+	if err != nil {
+		return nil, fmt.Errorf("could not read schema registry index: %w", err)
+	}
+	var entries []fileEntry
+	if err := json.Unmarshal(raw, &entries); err != nil {
+		return nil, fmt.Errorf("invalid schema registry index: %w", err)
+	}
+
+	c := &FileClient{
+		byID:      make(map[int]Schema, len(entries)),
+		bySubject: make(map[string]Schema),
+	}
+	latest := make(map[string]int)
+	for _, e := range entries {
+		switch e.Type {
+		case TypeAvro, TypeProtobuf, TypeJSON:
+		default:
+			return nil, fmt.Errorf("schema %d: unknown type %q", e.ID, e.Type)
+		}
+		if _, dup := c.byID[e.ID]; dup {
+			return nil, fmt.Errorf("schema %d is declared twice", e.ID)
+		}
+		definition, err := os.ReadFile(filepath.Join(dir, e.File))
+		if err != nil {
+			return nil, fmt.Errorf("schema %d: %w", e.ID, err)
+		}
+		schema := Schema{ID: e.ID, Subject: e.Subject, Type: e.Type, Definition: definition, Message: e.Message}
+		c.byID[e.ID] = schema
+		if v, ok := latest[e.Subject]; !ok || e.Version >= v {
+			latest[e.Subject] = e.Version
+			c.bySubject[e.Subject] = schema
+		}
+	}
+	return c, nil
+}
+
+func (c *FileClient) SchemaByID(_ context.Context, id int) (Schema, error) {
+	s, ok := c.byID[id]
+	if !ok {
+		return Schema{}, fmt.Errorf("%w: id %d", ErrSchemaNotFound, id)
+	}
+	return s, nil
+}
+
+func (c *FileClient) LatestBySubject(_ context.Context, subject string) (Schema, error) {
+	s, ok := c.bySubject[subject]
+	if !ok {
+		return Schema{}, fmt.Errorf("%w: subject %q", ErrSchemaNotFound, subject)
+	}
+	return s, nil
+}
@@ -0,0 +1,38 @@
+// Package schemaregistry описывает клиент реестра схем для бинарных
+// форматов сообщений (Avro, Protobuf) и его файловую реализацию.
+package schemaregistry
+
+import (
+	"context"
+	"errors"
+)
+
+type SchemaType string
+
+const (
+	TypeAvro     SchemaType = "AVRO"
+	TypeProtobuf SchemaType = "PROTOBUF"
+	TypeJSON     SchemaType = "JSON"
+)
+
+var ErrSchemaNotFound = errors.New("schema not found")
+
+type Schema struct {
+	ID      int
+	Subject string
+	Type    SchemaType
+	// Definition — текст схемы Avro (.avsc) или сериализованный
+	// FileDescriptorSet для Protobuf.
+	Definition []byte
+	// Message — полное имя сообщения Protobuf в FileDescriptorSet, которое
+	// используется, если в сообщении не указаны индексы.
+	Message string
+}
+
+// Client ищет схемы по идентификатору из wire format Confluent или по
+// subject. Реализация для Confluent Schema Registry может быть подключена
+// вместо FileClient без изменений в консьюмере.
+type Client interface {
+	SchemaByID(ctx context.Context, id int) (Schema, error)
+	LatestBySubject(ctx context.Context, subject string) (Schema, error)
+}
@@ -0,0 +1,89 @@
+//go:generate go run go.uber.org/mock/mockgen -source=apikey.go -destination=mocks/mock_apikey_service.go -package=mocks
+package service
+
+import (
+	"errors"
+	"fmt"
+	"orderkeeper/internal/auth"
+	"orderkeeper/internal/models"
+	"orderkeeper/internal/repository"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrAPIKeyNotFound = errors.New("API key not found")
+	ErrAPIKeyName     = errors.New("API key name is required")
+	ErrAPIKeyScopes   = errors.New("invalid API key scopes")
+	ErrAPIKeyTenant   = errors.New(`API key tenant is required, use "*" for all tenants`)
+)
+
+type APIKeyService interface {
+	// IssueAPIKey создает ключ. Открытый ключ есть только в ответе.
+	// tenant — площадка ключа или auth.AllTenants; пустой недопустим.
+	// Без scopes ключ получает auth.DefaultScope.
+	IssueAPIKey(name, tenant string, scopes []string) (models.IssuedAPIKey, error)
+	ListAPIKeys() ([]models.APIKey, error)
+	RevokeAPIKey(id uint) error
+	AuthenticateAPIKey(key string) (auth.Principal, error)
+}
+
+type apiKeyService struct {
+	repo repository.APIKeyRepository
+}
+
+func NewAPIKeyService(repo repository.APIKeyRepository) APIKeyService {
+	return &apiKeyService{repo: repo}
+}
+
+func (s *apiKeyService) IssueAPIKey(name, tenant string, scopes []string) (models.IssuedAPIKey, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return models.IssuedAPIKey{}, ErrAPIKeyName
+	}
+	tenant = strings.TrimSpace(tenant)
+	if tenant == "" {
+		return models.IssuedAPIKey{}, ErrAPIKeyTenant
+	}
+	if len(scopes) == 0 {
+		scopes = []string{auth.DefaultScope}
+	}
+	if err := auth.ValidateScopes(scopes); err != nil {
+		return models.IssuedAPIKey{}, fmt.Errorf("%w: %v", ErrAPIKeyScopes, err)
+	}
+	key, prefix, hash := auth.GenerateAPIKey()
+	apiKey := models.APIKey{Name: name, Prefix: prefix, Hash: hash, Scopes: scopes, Tenant: tenant}
+	if err := s.repo.CreateAPIKey(&apiKey); err != nil {
+		return models.IssuedAPIKey{}, err
+	}
+	return models.IssuedAPIKey{APIKey: apiKey, Key: key}, nil
+}
+
+func (s *apiKeyService) ListAPIKeys() ([]models.APIKey, error) {
+	keys, err := s.repo.ListAPIKeys()
+	if keys == nil {
+		keys = []models.APIKey{}
+	}
+	return keys, err
+}
+
+func (s *apiKeyService) RevokeAPIKey(id uint) error {
+	err := s.repo.RevokeAPIKey(id, time.Now())
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrAPIKeyNotFound
+	}
+	return err
+}
+
+func (s *apiKeyService) AuthenticateAPIKey(key string) (auth.Principal, error) {
+	apiKey, err := s.repo.GetActiveAPIKey(auth.HashAPIKey(key))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return auth.Principal{}, fmt.Errorf("%w: unknown or revoked API key", auth.ErrInvalidCredentials)
+	}
+	if err != nil {
+		return auth.Principal{}, err
+	}
+	return auth.Principal{Subject: apiKey.Name, Method: auth.MethodAPIKey, Scopes: apiKey.Scopes, Tenant: apiKey.Tenant}, nil
+}
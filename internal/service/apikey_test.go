@@ -0,0 +1,94 @@
+package service
+
+import (
+	"errors"
+	"orderkeeper/internal/auth"
+	"orderkeeper/internal/models"
+	"orderkeeper/internal/repository/mocks"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"gorm.io/gorm"
+)
+
+func TestAPIKeyService(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockAPIKeyRepository(ctrl)
+	apiKeyService := NewAPIKeyService(mockRepo)
+
+	t.Run("issue stores only the hash", func(t *testing.T) {
+		var stored models.APIKey
+		mockRepo.EXPECT().CreateAPIKey(gomock.Any()).DoAndReturn(func(key *models.APIKey) error {
+			key.ID = 7
+			stored = *key
+			return nil
+		})
+
+		issued, err := apiKeyService.IssueAPIKey(" billing ", " WBIL ", []string{auth.ScopeOrdersRead})
+
+		assert.NoError(t, err)
+		assert.Equal(t, uint(7), issued.ID)
+		assert.Equal(t, "billing", stored.Name)
+		assert.Equal(t, []string{auth.ScopeOrdersRead}, stored.Scopes)
+		assert.Equal(t, "WBIL", stored.Tenant)
+		assert.Equal(t, auth.HashAPIKey(issued.Key), stored.Hash)
+		assert.NotContains(t, stored.Prefix+stored.Hash, issued.Key)
+	})
+
+	t.Run("issue without name", func(t *testing.T) {
+		_, err := apiKeyService.IssueAPIKey("  ", auth.AllTenants, []string{auth.ScopeOrdersRead})
+
+		assert.True(t, errors.Is(err, ErrAPIKeyName))
+	})
+
+	t.Run("issue without tenant", func(t *testing.T) {
+		_, err := apiKeyService.IssueAPIKey("billing", " ", []string{auth.ScopeOrdersRead})
+
+		assert.True(t, errors.Is(err, ErrAPIKeyTenant))
+	})
+
+	t.Run("issue with unknown scopes", func(t *testing.T) {
+		_, err := apiKeyService.IssueAPIKey("billing", auth.AllTenants, []string{"orders:delete"})
+
+		assert.True(t, errors.Is(err, ErrAPIKeyScopes))
+	})
+
+	t.Run("issue without scopes", func(t *testing.T) {
+		mockRepo.EXPECT().CreateAPIKey(gomock.Any()).Return(nil)
+
+		issued, err := apiKeyService.IssueAPIKey("billing", auth.AllTenants, nil)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{auth.DefaultScope}, issued.Scopes)
+	})
+
+	t.Run("authenticate", func(t *testing.T) {
+		mockRepo.EXPECT().GetActiveAPIKey(auth.HashAPIKey("okk_secret")).Return(models.APIKey{
+			Name: "billing", Scopes: []string{auth.ScopeOrdersWrite}, Tenant: "WBIL",
+		}, nil)
+
+		principal, err := apiKeyService.AuthenticateAPIKey("okk_secret")
+
+		assert.NoError(t, err)
+		assert.Equal(t, auth.Principal{
+			Subject: "billing", Method: auth.MethodAPIKey, Scopes: []string{auth.ScopeOrdersWrite}, Tenant: "WBIL",
+		}, principal)
+	})
+
+	t.Run("revoked or unknown key", func(t *testing.T) {
+		mockRepo.EXPECT().GetActiveAPIKey(gomock.Any()).Return(models.APIKey{}, gorm.ErrRecordNotFound)
+
+		_, err := apiKeyService.AuthenticateAPIKey("okk_revoked")
+
+		assert.True(t, errors.Is(err, auth.ErrInvalidCredentials))
+	})
+
+	t.Run("revoke missing key", func(t *testing.T) {
+		mockRepo.EXPECT().RevokeAPIKey(uint(9), gomock.Any()).Return(gorm.ErrRecordNotFound)
+
+		assert.True(t, errors.Is(apiKeyService.RevokeAPIKey(9), ErrAPIKeyNotFound))
+	})
+}
@@ -0,0 +1,93 @@
+//go:generate go run go.uber.org/mock/mockgen -source=customer.go -destination=mocks/mock_customer_service.go -package=mocks
+package service
+
+import (
+	"errors"
+	"orderkeeper/internal/cache"
+	"orderkeeper/internal/models"
+	"orderkeeper/internal/repository"
+)
+
+const (
+	DefaultPageLimit = 20
+	MaxPageLimit     = 100
+)
+
+var ErrCustomerNotFound = errors.New("customer not found")
+
+type CustomerService interface {
+	GetCustomerOrders(customerID string, limit, offset int) (models.OrderPage, error)
+	GetCustomerSummary(customerID string) (models.CustomerSummary, error)
+	// EraseCustomer стирает персональные данные получателя во всех заказах
+	// покупателя и возвращает число затронутых заказов.
+	EraseCustomer(customerID string) (int, error)
+	// ForTenant возвращает сервис, который видит только покупателей и
+	// заказы площадки tenant.
+	ForTenant(tenant string) CustomerService
+}
+
+type customerService struct {
+	repo   repository.CustomerRepository
+	cache  *cache.OrderCache
+	tenant string
+}
+
+func NewCustomerService(repo repository.CustomerRepository, cache *cache.OrderCache) CustomerService {
+	return &customerService{repo: repo, cache: cache}
+}
+
+func (s *customerService) ForTenant(id string) CustomerService {
+	return &customerService{repo: s.repo.ForTenant(id), cache: s.cache, tenant: id}
+}
+
+// normalizePage подставляет размер страницы по умолчанию и ограничивает
+// его сверху.
+func normalizePage(limit, offset int) (int, int) {
+	if limit <= 0 {
+		limit = DefaultPageLimit
+	}
+	if limit > MaxPageLimit {
+		limit = MaxPageLimit
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	return limit, offset
+}
+
+func (s *customerService) GetCustomerOrders(customerID string, limit, offset int) (models.OrderPage, error) {
+	limit, offset = normalizePage(limit, offset)
+	orders, total, err := s.repo.GetOrdersByCustomer(customerID, limit, offset)
+	if err != nil {
+		return models.OrderPage{}, err
+	}
+	if orders == nil {
+		orders = []models.Order{}
+	}
+	return models.OrderPage{Orders: orders, Total: total, Limit: limit, Offset: offset}, nil
+}
+
+func (s *customerService) GetCustomerSummary(customerID string) (models.CustomerSummary, error) {
+	summary, err := s.repo.GetCustomerSummary(customerID)
+	if err != nil {
+		return models.CustomerSummary{}, err
+	}
+	if summary.OrderCount == 0 {
+		return models.CustomerSummary{}, ErrCustomerNotFound
+	}
+	return summary, nil
+}
+
+func (s *customerService) EraseCustomer(customerID string) (int, error) {
+	uids, err := s.repo.AnonymizeCustomer(customerID)
+	if err != nil {
+		return 0, err
+	}
+	if len(uids) == 0 {
+		return 0, ErrCustomerNotFound
+	}
+	for _, uid := range uids {
+		s.cache.Delete(s.tenant, uid)
+	}
+	return len(uids), nil
+}
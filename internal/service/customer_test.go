@@ -0,0 +1,68 @@
+package service
+
+import (
+	"errors"
+	"orderkeeper/internal/cache"
+	"orderkeeper/internal/models"
+	"orderkeeper/internal/repository/mocks"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestCustomerService(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockCustomerRepository(ctrl)
+	orderCache := cache.NewOrderCache()
+	customerService := NewCustomerService(mockRepo, orderCache)
+
+	t.Run("page size is clamped", func(t *testing.T) {
+		mockRepo.EXPECT().GetOrdersByCustomer("cust-1", MaxPageLimit, 0).Return(nil, int64(0), nil)
+
+		page, err := customerService.GetCustomerOrders("cust-1", 1000, -5)
+
+		assert.NoError(t, err)
+		assert.Equal(t, MaxPageLimit, page.Limit)
+		assert.NotNil(t, page.Orders)
+	})
+
+	t.Run("default page size", func(t *testing.T) {
+		mockRepo.EXPECT().GetOrdersByCustomer("cust-1", DefaultPageLimit, 40).Return([]models.Order{{OrderUID: "uid-1"}}, int64(41), nil)
+
+		page, err := customerService.GetCustomerOrders("cust-1", 0, 40)
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(41), page.Total)
+	})
+
+	t.Run("customer without orders", func(t *testing.T) {
+		mockRepo.EXPECT().GetCustomerSummary("nobody").Return(models.CustomerSummary{CustomerID: "nobody"}, nil)
+
+		_, err := customerService.GetCustomerSummary("nobody")
+
+		assert.True(t, errors.Is(err, ErrCustomerNotFound))
+	})
+
+	t.Run("erase evicts orders from cache", func(t *testing.T) {
+		orderCache.Set(models.Order{OrderUID: "uid-1", CustomerID: "cust-1"})
+		orderCache.Set(models.Order{OrderUID: "uid-2", CustomerID: "cust-1"})
+		mockRepo.EXPECT().AnonymizeCustomer("cust-1").Return([]string{"uid-1", "uid-2"}, nil)
+
+		erased, err := customerService.EraseCustomer("cust-1")
+
+		assert.NoError(t, err)
+		assert.Equal(t, 2, erased)
+		assert.Equal(t, 0, orderCache.Count())
+	})
+
+	t.Run("erase unknown customer", func(t *testing.T) {
+		mockRepo.EXPECT().AnonymizeCustomer("nobody").Return(nil, nil)
+
+		_, err := customerService.EraseCustomer("nobody")
+
+		assert.True(t, errors.Is(err, ErrCustomerNotFound))
+	})
+}
@@ -0,0 +1,44 @@
+//go:generate go run go.uber.org/mock/mockgen -source=encryption.go -destination=mocks/mock_encryption_service.go -package=mocks
+package service
+
+import "orderkeeper/internal/repository"
+
+const ReencryptBatchSize = 500
+
+type EncryptionService interface {
+	// ReencryptDeliveries перешифровывает текущим ключом все данные
+	// получателей пачками по batchSize и возвращает число перешифрованных
+	// записей. onBatch, если задан, получает нарастающий итог после каждой
+	// пачки.
+	ReencryptDeliveries(batchSize int, onBatch func(total int)) (int, error)
+}
+
+type encryptionService struct {
+	repo repository.EncryptionRepository
+}
+
+func NewEncryptionService(repo repository.EncryptionRepository) EncryptionService {
+	return &encryptionService{repo: repo}
+}
+
+func (s *encryptionService) ReencryptDeliveries(batchSize int, onBatch func(total int)) (int, error) {
+	if batchSize <= 0 {
+		batchSize = ReencryptBatchSize
+	}
+	var lastID uint
+	total := 0
+	for {
+		next, n, err := s.repo.ReencryptDeliveries(lastID, batchSize)
+		if err != nil {
+			return total, err
+		}
+		if n == 0 {
+			return total, nil
+		}
+		lastID = next
+		total += n
+		if onBatch != nil {
+			onBatch(total)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package service
+
+import (
+	"errors"
+	"orderkeeper/internal/repository/mocks"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestEncryptionService_ReencryptDeliveries(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockEncryptionRepository(ctrl)
+	encryptionService := NewEncryptionService(mockRepo)
+
+	t.Run("walks all batches", func(t *testing.T) {
+		gomock.InOrder(
+			mockRepo.EXPECT().ReencryptDeliveries(uint(0), 2).Return(uint(7), 2, nil),
+			mockRepo.EXPECT().ReencryptDeliveries(uint(7), 2).Return(uint(9), 1, nil),
+			mockRepo.EXPECT().ReencryptDeliveries(uint(9), 2).Return(uint(9), 0, nil),
+		)
+		var progress []int
+
+		n, err := encryptionService.ReencryptDeliveries(2, func(total int) { progress = append(progress, total) })
+
+		assert.NoError(t, err)
+		assert.Equal(t, 3, n)
+		assert.Equal(t, []int{2, 3}, progress)
+	})
+
+	t.Run("stops on error", func(t *testing.T) {
+		mockRepo.EXPECT().ReencryptDeliveries(uint(0), ReencryptBatchSize).Return(uint(0), 0, errors.New("boom"))
+
+		_, err := encryptionService.ReencryptDeliveries(0, nil)
+
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,55 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: encryption.go
+//
+// Generated by this command:
+//
+//	mockgen -source=encryption.go -destination=mocks/mock_encryption_service.go -package=mocks
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockEncryptionService is a mock of EncryptionService interface.
+type MockEncryptionService struct {
+	ctrl     *gomock.Controller
+	recorder *MockEncryptionServiceMockRecorder
+	isgomock struct{}
+}
+
+// MockEncryptionServiceMockRecorder is the mock recorder for MockEncryptionService.
+type MockEncryptionServiceMockRecorder struct {
+	mock *MockEncryptionService
+}
+
+// NewMockEncryptionService creates a new mock instance.
+func NewMockEncryptionService(ctrl *gomock.Controller) *MockEncryptionService {
+	mock := &MockEncryptionService{ctrl: ctrl}
+	mock.recorder = &MockEncryptionServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockEncryptionService) EXPECT() *MockEncryptionServiceMockRecorder {
+	return m.recorder
+}
+
+// ReencryptDeliveries mocks base method.
+func (m *MockEncryptionService) ReencryptDeliveries(batchSize int, onBatch func(int)) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReencryptDeliveries", batchSize, onBatch)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReencryptDeliveries indicates an expected call of ReencryptDeliveries.
+func (mr *MockEncryptionServiceMockRecorder) ReencryptDeliveries(batchSize, onBatch any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReencryptDeliveries", reflect.TypeOf((*MockEncryptionService)(nil).ReencryptDeliveries), batchSize, onBatch)
+}
@@ -11,6 +11,7 @@ package mocks
 
 import (
 	models "orderkeeper/internal/models"
+	service "orderkeeper/internal/service"
 	reflect "reflect"
 
 	gomock "go.uber.org/mock/gomock"
@@ -40,6 +41,34 @@ func (m *MockOrderService) EXPECT() *MockOrderServiceMockRecorder {
 	return m.recorder
 }
 
+// CancelOrder mocks base method.
+func (m *MockOrderService) CancelOrder(id, reason string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelOrder", id, reason)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CancelOrder indicates an expected call of CancelOrder.
+func (mr *MockOrderServiceMockRecorder) CancelOrder(id, reason any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelOrder", reflect.TypeOf((*MockOrderService)(nil).CancelOrder), id, reason)
+}
+
+// ConfirmPayment mocks base method.
+func (m *MockOrderService) ConfirmPayment(id, transaction string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConfirmPayment", id, transaction)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ConfirmPayment indicates an expected call of ConfirmPayment.
+func (mr *MockOrderServiceMockRecorder) ConfirmPayment(id, transaction any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConfirmPayment", reflect.TypeOf((*MockOrderService)(nil).ConfirmPayment), id, transaction)
+}
+
 // CreateOrder mocks base method.
 func (m *MockOrderService) CreateOrder(order models.Order) error {
 	m.ctrl.T.Helper()
@@ -54,6 +83,48 @@ func (mr *MockOrderServiceMockRecorder) CreateOrder(order any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOrder", reflect.TypeOf((*MockOrderService)(nil).CreateOrder), order)
 }
 
+// DeleteOrder mocks base method.
+func (m *MockOrderService) DeleteOrder(id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteOrder", id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteOrder indicates an expected call of DeleteOrder.
+func (mr *MockOrderServiceMockRecorder) DeleteOrder(id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteOrder", reflect.TypeOf((*MockOrderService)(nil).DeleteOrder), id)
+}
+
+// ExportOrders mocks base method.
+func (m *MockOrderService) ExportOrders(filter models.OrderFilter, fn func([]models.Order) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExportOrders", filter, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ExportOrders indicates an expected call of ExportOrders.
+func (mr *MockOrderServiceMockRecorder) ExportOrders(filter, fn any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExportOrders", reflect.TypeOf((*MockOrderService)(nil).ExportOrders), filter, fn)
+}
+
+// ForTenant mocks base method.
+func (m *MockOrderService) ForTenant(tenant string) service.OrderService {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ForTenant", tenant)
+	ret0, _ := ret[0].(service.OrderService)
+	return ret0
+}
+
+// ForTenant indicates an expected call of ForTenant.
+func (mr *MockOrderServiceMockRecorder) ForTenant(tenant any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ForTenant", reflect.TypeOf((*MockOrderService)(nil).ForTenant), tenant)
+}
+
 // GetOrderByID mocks base method.
 func (m *MockOrderService) GetOrderByID(id string) (models.Order, error) {
 	m.ctrl.T.Helper()
@@ -69,6 +140,21 @@ func (mr *MockOrderServiceMockRecorder) GetOrderByID(id any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrderByID", reflect.TypeOf((*MockOrderService)(nil).GetOrderByID), id)
 }
 
+// GetOrderByTrack mocks base method.
+func (m *MockOrderService) GetOrderByTrack(track string) (models.Order, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOrderByTrack", track)
+	ret0, _ := ret[0].(models.Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOrderByTrack indicates an expected call of GetOrderByTrack.
+func (mr *MockOrderServiceMockRecorder) GetOrderByTrack(track any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrderByTrack", reflect.TypeOf((*MockOrderService)(nil).GetOrderByTrack), track)
+}
+
 // RestoreCache mocks base method.
 func (m *MockOrderService) RestoreCache() error {
 	m.ctrl.T.Helper()
@@ -82,3 +168,31 @@ func (mr *MockOrderServiceMockRecorder) RestoreCache() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreCache", reflect.TypeOf((*MockOrderService)(nil).RestoreCache))
 }
+
+// UpdateOrderStatus mocks base method.
+func (m *MockOrderService) UpdateOrderStatus(id, status string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateOrderStatus", id, status)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateOrderStatus indicates an expected call of UpdateOrderStatus.
+func (mr *MockOrderServiceMockRecorder) UpdateOrderStatus(id, status any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateOrderStatus", reflect.TypeOf((*MockOrderService)(nil).UpdateOrderStatus), id, status)
+}
+
+// ValidateOrder mocks base method.
+func (m *MockOrderService) ValidateOrder(order models.Order) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ValidateOrder", order)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ValidateOrder indicates an expected call of ValidateOrder.
+func (mr *MockOrderServiceMockRecorder) ValidateOrder(order any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ValidateOrder", reflect.TypeOf((*MockOrderService)(nil).ValidateOrder), order)
+}
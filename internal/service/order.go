@@ -3,63 +3,117 @@ package service
 
 import (
 	"errors"
+	"fmt"
+	"log"
 	"orderkeeper/internal/cache"
+	"orderkeeper/internal/metrics"
 	"orderkeeper/internal/models"
 	"orderkeeper/internal/repository"
-	"strings"
+	"orderkeeper/internal/tenant"
+	"orderkeeper/internal/validation"
 
 	"gorm.io/gorm"
 )
 
-var ErrOrderNotFound = errors.New("order not found")
+var (
+	ErrOrderNotFound       = errors.New("order not found")
+	ErrInvalidStatus       = errors.New("invalid order status")
+	ErrOrderCancelled      = errors.New("order is cancelled")
+	ErrStatusTransition    = errors.New("order status cannot change this way")
+	ErrTransactionMismatch = errors.New("payment transaction does not match order")
+)
+
+const ExportBatchSize = 500
 
 type OrderService interface {
 	CreateOrder(order models.Order) error
+	ValidateOrder(order models.Order) error
 	GetOrderByID(id string) (models.Order, error)
+	GetOrderByTrack(track string) (models.Order, error)
 	RestoreCache() error
+	ExportOrders(filter models.OrderFilter, fn func([]models.Order) error) error
+	UpdateOrderStatus(id, status string) error
+	ConfirmPayment(id, transaction string) error
+	CancelOrder(id, reason string) error
+	DeleteOrder(id string) error
+	// ForTenant возвращает сервис, который видит и меняет только заказы
+	// площадки tenant; tenant.All — заказы всех площадок.
+	ForTenant(tenant string) OrderService
 }
 
 type orderService struct {
-	repo  repository.OrderRepository
-	cache *cache.OrderCache
+	repo           repository.OrderRepository
+	cache          *cache.OrderCache
+	reconciliation validation.Reconciliation
+	tenant         string
 }
 
 func NewOrderService(repo repository.OrderRepository, cache *cache.OrderCache) OrderService {
-	return &orderService{repo: repo, cache: cache}
+	return NewOrderServiceWithReconciliation(repo, cache, validation.Reconciliation{Mode: validation.ReconcileWarn})
 }
 
-func (s *orderService) validateOrder(order *models.Order) error {
-	if order.OrderUID == "" {
-		return errors.New("order_uid is required")
-	}
-	if order.TrackNumber == "" {
-		return errors.New("track_number is required")
-	}
-	if order.Delivery.Name == "" {
-		return errors.New("delivery name is required")
+func NewOrderServiceWithReconciliation(repo repository.OrderRepository, cache *cache.OrderCache, reconciliation validation.Reconciliation) OrderService {
+	return &orderService{repo: repo, cache: cache, reconciliation: reconciliation}
+}
+
+func (s *orderService) ForTenant(id string) OrderService {
+	scoped := *s
+	scoped.repo = s.repo.ForTenant(id)
+	scoped.tenant = id
+	return &scoped
+}
+
+// reconcile сверяет суммы заказа. В строгом режиме расхождение возвращается
+// как ошибка валидации, иначе заказ помечается для ручной проверки.
+func (s *orderService) reconcile(order *models.Order) error {
+	order.NeedsReview = false
+	order.ReviewReason = ""
+	if s.reconciliation.Mode == validation.ReconcileOff {
+		return nil
 	}
-	if order.Delivery.Phone == "" {
-		return errors.New("delivery phone is required")
+	err := validation.Reconcile(order, s.reconciliation.Tolerance)
+	if err == nil {
+		return nil
 	}
-	if !strings.Contains(order.Delivery.Email, "@") {
-		return errors.New("delivery email is invalid")
+	if s.reconciliation.Mode == validation.ReconcileStrict {
+		return err
 	}
-	if order.Payment.Transaction == "" {
-		return errors.New("payment transaction is required")
+	order.NeedsReview = true
+	order.ReviewReason = err.Error()
+	return nil
+}
+
+// ValidateOrder проверяет заказ так же, как CreateOrder, но ничего не сохраняет.
+func (s *orderService) ValidateOrder(order models.Order) error {
+	if order.Status == "" {
+		order.Status = models.OrderStatusCreated
 	}
-	if order.Payment.Amount <= 0 {
-		return errors.New("payment amount must be positive")
+	order.SetCurrency(order.Payment.Currency)
+	if err := validation.Order(&order); err != nil {
+		return err
 	}
-	if len(order.Items) == 0 {
-		return errors.New("order must contain at least one item")
+	if err := tenant.Check(s.tenant, order.Entry); err != nil {
+		return err
 	}
-	return nil
+	return s.reconcile(&order)
 }
 
 func (s *orderService) CreateOrder(order models.Order) error {
-	if err := s.validateOrder(&order); err != nil {
+	if order.Status == "" {
+		order.Status = models.OrderStatusCreated
+	}
+	order.SetCurrency(order.Payment.Currency)
+	if err := validation.Order(&order); err != nil {
+		return err
+	}
+	if err := s.reconcile(&order); err != nil {
+		metrics.Reconciliation.Add("rejected", 1)
 		return err
 	}
+	if order.NeedsReview {
+		metrics.Reconciliation.Add("flagged", 1)
+		log.Printf("Order %s flagged for review: %s", order.OrderUID, order.ReviewReason)
+	}
 
 	if err := s.repo.CreateOrder(order); err != nil {
 		return err
@@ -70,7 +124,7 @@ func (s *orderService) CreateOrder(order models.Order) error {
 }
 
 func (s *orderService) GetOrderByID(id string) (models.Order, error) {
-	if order, exists := s.cache.Get(id); exists {
+	if order, exists := s.cache.Get(s.tenant, id); exists {
 		return order, nil
 	}
 
@@ -86,12 +140,100 @@ func (s *orderService) GetOrderByID(id string) (models.Order, error) {
 	return order, nil
 }
 
+func (s *orderService) GetOrderByTrack(track string) (models.Order, error) {
+	if order, exists := s.cache.GetByTrack(s.tenant, track); exists {
+		return order, nil
+	}
+
+	order, err := s.repo.GetOrderByTrack(track)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.Order{}, ErrOrderNotFound
+		}
+		return models.Order{}, err
+	}
+
+	s.cache.Set(order)
+	return order, nil
+}
+
 func (s *orderService) RestoreCache() error {
-	orders, err := s.repo.GetAllOrders()
+	return s.repo.IterateOrders(models.OrderFilter{}, ExportBatchSize, func(orders []models.Order) error {
+		s.cache.LoadFromDB(orders)
+		return nil
+	})
+}
+
+// ExportOrders передает fn подходящие заказы пачками по ExportBatchSize,
+// не загружая их все в память.
+func (s *orderService) ExportOrders(filter models.OrderFilter, fn func([]models.Order) error) error {
+	return s.repo.IterateOrders(filter, ExportBatchSize, fn)
+}
+
+func (s *orderService) UpdateOrderStatus(id, status string) error {
+	if !models.IsValidOrderStatus(status) {
+		return fmt.Errorf("%w: %q", ErrInvalidStatus, status)
+	}
+	if status == models.OrderStatusCancelled {
+		return s.CancelOrder(id, "")
+	}
+	return s.changeStatus(id, status, func() error {
+		return s.repo.UpdateOrderStatus(id, status)
+	})
+}
+
+func (s *orderService) ConfirmPayment(id, transaction string) error {
+	order, err := s.GetOrderByID(id)
 	if err != nil {
 		return err
 	}
+	if order.Payment.Transaction != transaction {
+		return ErrTransactionMismatch
+	}
+	return s.changeStatus(id, models.OrderStatusPaid, func() error {
+		return s.repo.UpdateOrderStatus(id, models.OrderStatusPaid)
+	})
+}
+
+func (s *orderService) CancelOrder(id, reason string) error {
+	return s.changeStatus(id, models.OrderStatusCancelled, func() error {
+		return s.repo.CancelOrder(id, reason)
+	})
+}
+
+func (s *orderService) DeleteOrder(id string) error {
+	return s.mutate(id, func() error {
+		return s.repo.DeleteOrder(id)
+	})
+}
+
+// changeStatus выполняет смену статуса на status. Повтор текущего статуса
+// не считается ошибкой: сообщения доставляются at-least-once.
+func (s *orderService) changeStatus(id, status string, fn func() error) error {
+	err := s.mutate(id, fn)
+	var conflict *repository.StatusConflictError
+	if !errors.As(err, &conflict) {
+		return err
+	}
+	switch conflict.Status {
+	case status:
+		return nil
+	case models.OrderStatusCancelled:
+		return ErrOrderCancelled
+	default:
+		return fmt.Errorf("%w: %s -> %s", ErrStatusTransition, conflict.Status, status)
+	}
+}
 
-	s.cache.LoadFromDB(orders)
+// mutate выполняет изменение заказа в БД и сбрасывает его из кеша, чтобы
+// следующее чтение получило актуальное состояние.
+func (s *orderService) mutate(id string, fn func() error) error {
+	if err := fn(); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrOrderNotFound
+		}
+		return err
+	}
+	s.cache.Delete(s.tenant, id)
 	return nil
 }
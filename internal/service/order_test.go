@@ -4,8 +4,14 @@ import (
 	"errors"
 	"orderkeeper/internal/cache"
 	"orderkeeper/internal/models"
+	"orderkeeper/internal/money"
+	"orderkeeper/internal/repository"
 	"orderkeeper/internal/repository/mocks"
+	"orderkeeper/internal/tenant"
+	"orderkeeper/internal/validation"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/mock/gomock"
@@ -43,7 +49,7 @@ func TestOrderService_GetOrderByID(t *testing.T) {
 		assert.NoError(t, err)
 		assert.Equal(t, testOrder.OrderUID, result.OrderUID)
 
-		_, exists := cleanCache.Get("test-uid-123")
+		_, exists := cleanCache.Get("", "test-uid-123")
 		assert.True(t, exists)
 	})
 
@@ -59,3 +65,211 @@ func TestOrderService_GetOrderByID(t *testing.T) {
 		assert.True(t, errors.Is(err, ErrOrderNotFound))
 	})
 }
+
+func TestOrderService_StatusChanges(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockOrderRepository(ctrl)
+	orderCache := cache.NewOrderCache()
+	orderService := NewOrderService(mockRepo, orderCache)
+
+	active := models.Order{
+		OrderUID: "uid-active",
+		Status:   models.OrderStatusCreated,
+		Payment:  models.Payment{Transaction: "tx-1"},
+	}
+
+	t.Run("confirm payment evicts cache", func(t *testing.T) {
+		orderCache.Set(active)
+		mockRepo.EXPECT().UpdateOrderStatus("uid-active", models.OrderStatusPaid).Return(nil)
+
+		assert.NoError(t, orderService.ConfirmPayment("uid-active", "tx-1"))
+
+		_, exists := orderCache.Get("", "uid-active")
+		assert.False(t, exists)
+	})
+
+	t.Run("confirm payment with wrong transaction", func(t *testing.T) {
+		orderCache.Set(active)
+
+		err := orderService.ConfirmPayment("uid-active", "tx-other")
+
+		assert.True(t, errors.Is(err, ErrTransactionMismatch))
+	})
+
+	t.Run("invalid status", func(t *testing.T) {
+		err := orderService.UpdateOrderStatus("uid-active", "lost")
+
+		assert.True(t, errors.Is(err, ErrInvalidStatus))
+	})
+
+	t.Run("cancelled order cannot change", func(t *testing.T) {
+		mockRepo.EXPECT().UpdateOrderStatus("uid-cancelled", models.OrderStatusShipped).
+			Return(&repository.StatusConflictError{Status: models.OrderStatusCancelled})
+
+		err := orderService.UpdateOrderStatus("uid-cancelled", models.OrderStatusShipped)
+
+		assert.True(t, errors.Is(err, ErrOrderCancelled))
+	})
+
+	t.Run("status cannot go back", func(t *testing.T) {
+		mockRepo.EXPECT().UpdateOrderStatus("uid-delivered", models.OrderStatusPaid).
+			Return(&repository.StatusConflictError{Status: models.OrderStatusDelivered})
+
+		err := orderService.UpdateOrderStatus("uid-delivered", models.OrderStatusPaid)
+
+		assert.True(t, errors.Is(err, ErrStatusTransition))
+	})
+
+	t.Run("repeated status is accepted", func(t *testing.T) {
+		mockRepo.EXPECT().UpdateOrderStatus("uid-shipped", models.OrderStatusShipped).
+			Return(&repository.StatusConflictError{Status: models.OrderStatusShipped})
+
+		assert.NoError(t, orderService.UpdateOrderStatus("uid-shipped", models.OrderStatusShipped))
+	})
+
+	t.Run("cancel missing order", func(t *testing.T) {
+		mockRepo.EXPECT().CancelOrder("uid-missing", "duplicate").Return(gorm.ErrRecordNotFound)
+
+		err := orderService.CancelOrder("uid-missing", "duplicate")
+
+		assert.True(t, errors.Is(err, ErrOrderNotFound))
+	})
+
+	t.Run("cancel", func(t *testing.T) {
+		orderCache.Set(active)
+		mockRepo.EXPECT().CancelOrder("uid-active", "duplicate").Return(nil)
+
+		assert.NoError(t, orderService.CancelOrder("uid-active", "duplicate"))
+	})
+
+	t.Run("delete evicts cache", func(t *testing.T) {
+		orderCache.Set(active)
+		mockRepo.EXPECT().DeleteOrder("uid-active").Return(nil)
+
+		assert.NoError(t, orderService.DeleteOrder("uid-active"))
+
+		_, exists := orderCache.Get("", "uid-active")
+		assert.False(t, exists)
+	})
+
+	t.Run("delete missing order", func(t *testing.T) {
+		mockRepo.EXPECT().DeleteOrder("uid-missing").Return(gorm.ErrRecordNotFound)
+
+		err := orderService.DeleteOrder("uid-missing")
+
+		assert.True(t, errors.Is(err, ErrOrderNotFound))
+	})
+}
+
+func reconcilableOrder() models.Order {
+	return models.Order{
+		OrderUID: "uid-reconcile", TrackNumber: "TRACK", Entry: "WBIL", Locale: "en",
+		CustomerID: "test", DeliveryService: "meest", DateCreated: models.Timestamp{Time: time.Now()},
+		Delivery: models.Delivery{
+			Name: "Test Testov", Phone: "+9720000000", Zip: "2639809", City: "Kiryat Mozkin",
+			Address: "Ploshad Mira 15", Email: "test@gmail.com",
+		},
+		Payment: models.Payment{
+			Transaction: "uid-reconcile", Currency: "USD", Provider: "wbpay",
+			Amount: money.New(1900, "USD"), PaymentDT: models.UnixTime{Time: time.Unix(1637907727, 0)}, DeliveryCost: money.New(1500, "USD"), GoodsTotal: money.New(317, "USD"),
+		},
+		Items: []models.Item{{CHRTID: 1, NMID: 1, TrackNumber: "TRACK", Name: "Mascaras", Price: money.New(453, "USD"), Sale: 30, TotalPrice: money.New(317, "USD")}},
+	}
+}
+
+func TestOrderService_CreateOrderReconciliation(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockOrderRepository(ctrl)
+
+	t.Run("warn flags order for review", func(t *testing.T) {
+		orderService := NewOrderService(mockRepo, cache.NewOrderCache())
+		mockRepo.EXPECT().CreateOrder(gomock.Cond(func(o models.Order) bool {
+			return o.NeedsReview && strings.Contains(o.ReviewReason, "payment.amount")
+		})).Return(nil)
+
+		assert.NoError(t, orderService.CreateOrder(reconcilableOrder()))
+	})
+
+	t.Run("strict rejects order", func(t *testing.T) {
+		orderService := NewOrderServiceWithReconciliation(mockRepo, cache.NewOrderCache(),
+			validation.Reconciliation{Mode: validation.ReconcileStrict})
+
+		err := orderService.CreateOrder(reconcilableOrder())
+
+		var violations validation.Errors
+		assert.True(t, errors.As(err, &violations))
+		assert.Equal(t, "payment.amount", violations[0].Field)
+	})
+
+	t.Run("tolerance accepts small mismatch", func(t *testing.T) {
+		orderService := NewOrderServiceWithReconciliation(mockRepo, cache.NewOrderCache(),
+			validation.Reconciliation{Mode: validation.ReconcileStrict, Tolerance: 100})
+		mockRepo.EXPECT().CreateOrder(gomock.Cond(func(o models.Order) bool { return !o.NeedsReview })).Return(nil)
+
+		assert.NoError(t, orderService.CreateOrder(reconcilableOrder()))
+	})
+}
+
+func TestOrderService_GetOrderByTrack(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockOrderRepository(ctrl)
+	orderService := NewOrderService(mockRepo, cache.NewOrderCache())
+	order := models.Order{OrderUID: "uid-1", TrackNumber: "TRACK-1", Items: []models.Item{{TrackNumber: "TRACK-2"}}}
+
+	mockRepo.EXPECT().GetOrderByTrack("TRACK-2").Return(order, nil).Times(1)
+	mockRepo.EXPECT().GetOrderByTrack("NOPE").Return(models.Order{}, gorm.ErrRecordNotFound)
+
+	for i := 0; i < 2; i++ {
+		result, err := orderService.GetOrderByTrack("TRACK-2")
+		assert.NoError(t, err)
+		assert.Equal(t, "uid-1", result.OrderUID)
+	}
+	result, err := orderService.GetOrderByTrack("TRACK-1")
+	assert.NoError(t, err)
+	assert.Equal(t, "uid-1", result.OrderUID)
+
+	_, err = orderService.GetOrderByTrack("NOPE")
+	assert.True(t, errors.Is(err, ErrOrderNotFound))
+}
+
+func TestOrderService_ForTenant(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockOrderRepository(ctrl)
+	tenantRepo := mocks.NewMockOrderRepository(ctrl)
+	orderCache := cache.NewOrderCache()
+	orderService := NewOrderService(mockRepo, orderCache)
+	mockRepo.EXPECT().ForTenant("OZON").Return(tenantRepo).AnyTimes()
+	ozon := orderService.ForTenant("OZON")
+
+	orderCache.Set(models.Order{OrderUID: "uid-wb", Entry: "WBIL"})
+
+	t.Run("other tenant's cached order is not visible", func(t *testing.T) {
+		tenantRepo.EXPECT().GetOrderByID("uid-wb").Return(models.Order{}, gorm.ErrRecordNotFound)
+
+		_, err := ozon.GetOrderByID("uid-wb")
+
+		assert.True(t, errors.Is(err, ErrOrderNotFound))
+	})
+
+	t.Run("unscoped service sees every tenant", func(t *testing.T) {
+		result, err := orderService.GetOrderByID("uid-wb")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "WBIL", result.Entry)
+	})
+
+	t.Run("order of another tenant is rejected", func(t *testing.T) {
+		order := reconcilableOrder()
+		order.Entry = "WBIL"
+
+		assert.True(t, errors.Is(ozon.ValidateOrder(order), tenant.ErrMismatch))
+	})
+}
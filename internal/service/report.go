@@ -0,0 +1,152 @@
+//go:generate go run go.uber.org/mock/mockgen -source=report.go -destination=mocks/mock_report_service.go -package=mocks
+package service
+
+import (
+	"errors"
+	"fmt"
+	"orderkeeper/internal/models"
+	"orderkeeper/internal/repository"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultReportCacheTTL = time.Minute
+	MaxReportDays         = 366
+
+	reportDateLayout = "2006-01-02"
+)
+
+var ErrInvalidReport = errors.New("invalid report request")
+
+// ReportRequest задает отчет за дни From..To включительно. Время суток
+// в From и To игнорируется.
+type ReportRequest struct {
+	From    time.Time
+	To      time.Time
+	GroupBy string
+}
+
+type ReportService interface {
+	OrdersReport(req ReportRequest) (models.Report, error)
+	// ForTenant возвращает сервис, который строит отчеты только по заказам
+	// площадки tenant.
+	ForTenant(tenant string) ReportService
+}
+
+type cachedReport struct {
+	report    models.Report
+	expiresAt time.Time
+}
+
+// reportKey — ключ кеша отчетов: отчеты разных площадок не смешиваются.
+type reportKey struct {
+	tenant string
+	req    ReportRequest
+}
+
+// reportCache общий для сервисов всех площадок.
+type reportCache struct {
+	mu      sync.Mutex
+	reports map[reportKey]cachedReport
+}
+
+type reportService struct {
+	repo   repository.ReportRepository
+	ttl    time.Duration
+	now    func() time.Time
+	tenant string
+	cache  *reportCache
+}
+
+// NewReportService создает сервис отчетов, который кеширует готовые отчеты
+// на ttl; ttl <= 0 означает DefaultReportCacheTTL.
+func NewReportService(repo repository.ReportRepository, ttl time.Duration) ReportService {
+	if ttl <= 0 {
+		ttl = DefaultReportCacheTTL
+	}
+	return &reportService{
+		repo:  repo,
+		ttl:   ttl,
+		now:   time.Now,
+		cache: &reportCache{reports: make(map[reportKey]cachedReport)},
+	}
+}
+
+func (s *reportService) ForTenant(id string) ReportService {
+	scoped := *s
+	scoped.repo = s.repo.ForTenant(id)
+	scoped.tenant = id
+	return &scoped
+}
+
+func (s *reportService) OrdersReport(req ReportRequest) (models.Report, error) {
+	req, err := s.normalize(req)
+	if err != nil {
+		return models.Report{}, err
+	}
+
+	now := s.now()
+	key := reportKey{tenant: s.tenant, req: req}
+	s.cache.mu.Lock()
+	cached, ok := s.cache.reports[key]
+	s.cache.mu.Unlock()
+	if ok && now.Before(cached.expiresAt) {
+		return cached.report, nil
+	}
+
+	rows, err := s.repo.OrdersPerDay(req.From, req.To.AddDate(0, 0, 1), req.GroupBy)
+	if err != nil {
+		return models.Report{}, err
+	}
+	if rows == nil {
+		rows = []models.ReportRow{}
+	}
+	report := models.Report{
+		From:    req.From.Format(reportDateLayout),
+		To:      req.To.Format(reportDateLayout),
+		GroupBy: req.GroupBy,
+		Rows:    rows,
+	}
+
+	s.cache.mu.Lock()
+	for k, entry := range s.cache.reports {
+		if !now.Before(entry.expiresAt) {
+			delete(s.cache.reports, k)
+		}
+	}
+	s.cache.reports[key] = cachedReport{report: report, expiresAt: now.Add(s.ttl)}
+	s.cache.mu.Unlock()
+	return report, nil
+}
+
+// normalize приводит даты к началу дня UTC и подставляет значения по
+// умолчанию: последние 30 дней, группировка по валюте.
+func (s *reportService) normalize(req ReportRequest) (ReportRequest, error) {
+	if req.GroupBy == "" {
+		req.GroupBy = models.ReportByCurrency
+	}
+	if !repository.IsReportDimension(req.GroupBy) {
+		return req, fmt.Errorf("%w: unknown group_by %q", ErrInvalidReport, req.GroupBy)
+	}
+	if req.To.IsZero() {
+		req.To = s.now()
+	}
+	req.To = truncateDay(req.To)
+	if req.From.IsZero() {
+		req.From = req.To.AddDate(0, 0, -29)
+	}
+	req.From = truncateDay(req.From)
+	if req.From.After(req.To) {
+		return req, fmt.Errorf("%w: from is after to", ErrInvalidReport)
+	}
+	if req.To.Sub(req.From) >= MaxReportDays*24*time.Hour {
+		return req, fmt.Errorf("%w: period must not exceed %d days", ErrInvalidReport, MaxReportDays)
+	}
+	return req, nil
+}
+
+func truncateDay(t time.Time) time.Time {
+	y, m, d := t.UTC().Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,70 @@
+package service
+
+import (
+	"errors"
+	"orderkeeper/internal/models"
+	"orderkeeper/internal/repository/mocks"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestReportService_OrdersReport(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockReportRepository(ctrl)
+	reportService := NewReportService(mockRepo, time.Minute).(*reportService)
+	now := time.Date(2025, 3, 10, 15, 4, 5, 0, time.UTC)
+	reportService.now = func() time.Time { return now }
+
+	from := time.Date(2025, 2, 9, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 3, 11, 0, 0, 0, 0, time.UTC)
+	rows := []models.ReportRow{{Day: "2025-03-01", Group: "USD", Currency: "USD", Orders: 2, Revenue: 3634}}
+
+	t.Run("defaults and cache", func(t *testing.T) {
+		mockRepo.EXPECT().OrdersPerDay(from, to, models.ReportByCurrency).Return(rows, nil).Times(1)
+
+		for i := 0; i < 2; i++ {
+			report, err := reportService.OrdersReport(ReportRequest{})
+			assert.NoError(t, err)
+			assert.Equal(t, "2025-02-09", report.From)
+			assert.Equal(t, "2025-03-10", report.To)
+			assert.Equal(t, rows, report.Rows)
+		}
+	})
+
+	t.Run("cache expires", func(t *testing.T) {
+		now = now.Add(2 * time.Minute)
+		mockRepo.EXPECT().OrdersPerDay(from, to, models.ReportByCurrency).Return(nil, nil)
+
+		report, err := reportService.OrdersReport(ReportRequest{})
+
+		assert.NoError(t, err)
+		assert.Empty(t, report.Rows)
+	})
+
+	t.Run("cache is per tenant", func(t *testing.T) {
+		tenantRepo := mocks.NewMockReportRepository(ctrl)
+		mockRepo.EXPECT().ForTenant("OZON").Return(tenantRepo)
+		tenantRepo.EXPECT().OrdersPerDay(from, to, models.ReportByCurrency).Return(rows, nil)
+
+		report, err := reportService.ForTenant("OZON").OrdersReport(ReportRequest{})
+
+		assert.NoError(t, err)
+		assert.Equal(t, rows, report.Rows)
+	})
+
+	t.Run("invalid requests", func(t *testing.T) {
+		for _, req := range []ReportRequest{
+			{GroupBy: "color"},
+			{From: now, To: now.AddDate(0, 0, -1)},
+			{From: now.AddDate(-2, 0, 0), To: now},
+		} {
+			_, err := reportService.OrdersReport(req)
+			assert.True(t, errors.Is(err, ErrInvalidReport), req)
+		}
+	})
+}
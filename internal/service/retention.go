@@ -0,0 +1,87 @@
+//go:generate go run go.uber.org/mock/mockgen -source=retention.go -destination=mocks/mock_retention_service.go -package=mocks
+package service
+
+import (
+	"fmt"
+	"orderkeeper/internal/cache"
+	"orderkeeper/internal/metrics"
+	"orderkeeper/internal/repository"
+	"orderkeeper/internal/tenant"
+	"time"
+)
+
+const (
+	// RetentionArchive мягко удаляет старые заказы: они пропадают из API,
+	// но остаются в БД.
+	RetentionArchive = "archive"
+	// RetentionPurge удаляет старые заказы безвозвратно.
+	RetentionPurge = "purge"
+
+	RetentionBatchSize = 500
+)
+
+// RetentionPolicy — заказы старше MaxAge архивируются или удаляются в
+// зависимости от Mode. Нулевой MaxAge отключает политику.
+type RetentionPolicy struct {
+	Mode   string
+	MaxAge time.Duration
+}
+
+func (p RetentionPolicy) Validate() error {
+	if p.Mode != RetentionArchive && p.Mode != RetentionPurge {
+		return fmt.Errorf("unknown retention mode %q", p.Mode)
+	}
+	if p.MaxAge < 0 {
+		return fmt.Errorf("retention age must not be negative, got %v", p.MaxAge)
+	}
+	return nil
+}
+
+func (p RetentionPolicy) Enabled() bool {
+	return p.MaxAge > 0
+}
+
+type RetentionService interface {
+	// ApplyRetention обрабатывает по политике все заказы, созданные раньше
+	// now минус MaxAge, и возвращает их число.
+	ApplyRetention(policy RetentionPolicy, now time.Time) (int, error)
+}
+
+type retentionService struct {
+	repo  repository.RetentionRepository
+	cache *cache.OrderCache
+}
+
+func NewRetentionService(repo repository.RetentionRepository, cache *cache.OrderCache) RetentionService {
+	return &retentionService{repo: repo, cache: cache}
+}
+
+func (s *retentionService) ApplyRetention(policy RetentionPolicy, now time.Time) (int, error) {
+	if err := policy.Validate(); err != nil {
+		return 0, err
+	}
+	if !policy.Enabled() {
+		return 0, nil
+	}
+	apply, counter := s.repo.ArchiveOrders, "archived"
+	if policy.Mode == RetentionPurge {
+		apply, counter = s.repo.PurgeOrders, "purged"
+	}
+
+	before := now.Add(-policy.MaxAge)
+	total := 0
+	for {
+		uids, err := apply(before, RetentionBatchSize)
+		if err != nil {
+			return total, err
+		}
+		for _, uid := range uids {
+			s.cache.Delete(tenant.All, uid)
+		}
+		total += len(uids)
+		metrics.Retention.Add(counter, int64(len(uids)))
+		if len(uids) < RetentionBatchSize {
+			return total, nil
+		}
+	}
+}
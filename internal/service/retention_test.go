@@ -0,0 +1,65 @@
+package service
+
+import (
+	"fmt"
+	"orderkeeper/internal/cache"
+	"orderkeeper/internal/models"
+	"orderkeeper/internal/repository/mocks"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestRetentionService(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRetentionRepository(ctrl)
+	orderCache := cache.NewOrderCache()
+	retentionService := NewRetentionService(mockRepo, orderCache)
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	before := now.Add(-30 * 24 * time.Hour)
+
+	t.Run("archive in batches", func(t *testing.T) {
+		orderCache.Set(models.Order{OrderUID: "uid-0"})
+		full := make([]string, RetentionBatchSize)
+		for i := range full {
+			full[i] = fmt.Sprintf("uid-%d", i)
+		}
+		gomock.InOrder(
+			mockRepo.EXPECT().ArchiveOrders(before, RetentionBatchSize).Return(full, nil),
+			mockRepo.EXPECT().ArchiveOrders(before, RetentionBatchSize).Return([]string{"uid-last"}, nil),
+		)
+
+		n, err := retentionService.ApplyRetention(RetentionPolicy{Mode: RetentionArchive, MaxAge: 30 * 24 * time.Hour}, now)
+
+		assert.NoError(t, err)
+		assert.Equal(t, RetentionBatchSize+1, n)
+		_, exists := orderCache.Get("", "uid-0")
+		assert.False(t, exists)
+	})
+
+	t.Run("purge", func(t *testing.T) {
+		mockRepo.EXPECT().PurgeOrders(before, RetentionBatchSize).Return([]string{"uid-1"}, nil)
+
+		n, err := retentionService.ApplyRetention(RetentionPolicy{Mode: RetentionPurge, MaxAge: 30 * 24 * time.Hour}, now)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, n)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		n, err := retentionService.ApplyRetention(RetentionPolicy{Mode: RetentionArchive}, now)
+
+		assert.NoError(t, err)
+		assert.Zero(t, n)
+	})
+
+	t.Run("unknown mode", func(t *testing.T) {
+		_, err := retentionService.ApplyRetention(RetentionPolicy{Mode: "shred", MaxAge: time.Hour}, now)
+
+		assert.Error(t, err)
+	})
+}
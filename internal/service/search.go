@@ -0,0 +1,53 @@
+//go:generate go run go.uber.org/mock/mockgen -source=search.go -destination=mocks/mock_search_service.go -package=mocks
+package service
+
+import (
+	"errors"
+	"orderkeeper/internal/models"
+	"orderkeeper/internal/repository"
+	"strings"
+)
+
+var ErrEmptyQuery = errors.New("search query is empty")
+
+type SearchService interface {
+	Search(query string, limit, offset int) (models.SearchPage, error)
+	// ForTenant возвращает сервис, который ищет только среди заказов
+	// площадки tenant.
+	ForTenant(tenant string) SearchService
+	// WithoutPII возвращает сервис для вызывающих без области pii:read:
+	// он не ищет по имени, телефону, email и адресу получателя.
+	WithoutPII() SearchService
+}
+
+type searchService struct {
+	repo repository.SearchRepository
+}
+
+func NewSearchService(repo repository.SearchRepository) SearchService {
+	return &searchService{repo: repo}
+}
+
+func (s *searchService) ForTenant(id string) SearchService {
+	return &searchService{repo: s.repo.ForTenant(id)}
+}
+
+func (s *searchService) WithoutPII() SearchService {
+	return &searchService{repo: s.repo.WithoutPII()}
+}
+
+func (s *searchService) Search(query string, limit, offset int) (models.SearchPage, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return models.SearchPage{}, ErrEmptyQuery
+	}
+	limit, offset = normalizePage(limit, offset)
+	hits, total, err := s.repo.SearchOrders(query, limit, offset)
+	if err != nil {
+		return models.SearchPage{}, err
+	}
+	if hits == nil {
+		hits = []models.SearchHit{}
+	}
+	return models.SearchPage{Query: query, Hits: hits, Total: total, Limit: limit, Offset: offset}, nil
+}
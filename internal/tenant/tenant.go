@@ -0,0 +1,39 @@
+// Package tenant разделяет данные площадок (Order.Entry), которые
+// обслуживаются одним развертыванием.
+package tenant
+
+import (
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// All — пустая площадка: доступ к заказам всех площадок.
+const All = ""
+
+var ErrMismatch = errors.New("order belongs to another tenant")
+
+// Scope ограничивает запрос к таблице orders заказами площадки id. Для All
+// запрос не меняется.
+func Scope(id string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if id == All {
+			return db
+		}
+		return db.Where(clause.Eq{
+			Column: clause.Column{Table: clause.CurrentTable, Name: "entry"},
+			Value:  id,
+		})
+	}
+}
+
+// Check проверяет, что заказ площадки entry можно записать от имени
+// площадки id.
+func Check(id, entry string) error {
+	if id != All && entry != id {
+		return fmt.Errorf("%w: entry %q, tenant %q", ErrMismatch, entry, id)
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package validation
+
+import (
+	"net/mail"
+	"orderkeeper/internal/money"
+	"regexp"
+	"strings"
+
+	"golang.org/x/text/language"
+)
+
+var (
+	phonePattern = regexp.MustCompile(`^\+?[0-9]{7,15}$`)
+	zipPattern   = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9 -]{1,8}[A-Za-z0-9]$`)
+)
+
+// IsEmail проверяет адрес по RFC 5322 без отображаемого имени.
+func IsEmail(s string) bool {
+	addr, err := mail.ParseAddress(s)
+	if err != nil || addr.Address != s {
+		return false
+	}
+	domain := s[strings.LastIndexByte(s, '@')+1:]
+	return strings.Contains(domain, ".")
+}
+
+// IsPhone принимает номера в международном формате; пробелы, дефисы и
+// скобки игнорируются.
+func IsPhone(s string) bool {
+	return phonePattern.MatchString(strings.NewReplacer(" ", "", "-", "", "(", "", ")", "").Replace(s))
+}
+
+func IsZip(s string) bool {
+	return zipPattern.MatchString(s)
+}
+
+// IsCurrency проверяет трехбуквенный код валюты ISO 4217.
+func IsCurrency(s string) bool {
+	return money.Currency(s).Valid()
+}
+
+// IsLocale проверяет тег языка BCP 47, например "en" или "ru-RU".
+func IsLocale(s string) bool {
+	tag, err := language.Parse(s)
+	return err == nil && tag != language.Und
+}
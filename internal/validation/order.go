@@ -0,0 +1,84 @@
+package validation
+
+import (
+	"fmt"
+	"orderkeeper/internal/models"
+)
+
+const maxSale = 100
+
+// Order проверяет заказ и возвращает Errors со всеми нарушениями или nil.
+func Order(order *models.Order) error {
+	c := &collector{}
+
+	c.required("order_uid", order.OrderUID)
+	c.required("track_number", order.TrackNumber)
+	c.required("entry", order.Entry)
+	c.required("customer_id", order.CustomerID)
+	c.required("delivery_service", order.DeliveryService)
+	if c.required("locale", order.Locale) && !IsLocale(order.Locale) {
+		c.add("locale", RuleLocale, "must be a BCP 47 language tag, got %q", order.Locale)
+	}
+	if order.DateCreated.IsZero() {
+		c.add("date_created", RuleRequired, "is required")
+	}
+	if order.Status != "" && !models.IsValidOrderStatus(order.Status) {
+		c.add("status", RuleStatus, "unknown order status %q", order.Status)
+	}
+
+	delivery(c, &order.Delivery)
+	payment(c, &order.Payment)
+
+	if len(order.Items) == 0 {
+		c.add("items", RuleRequired, "order must contain at least one item")
+	}
+	for i := range order.Items {
+		item(c, fmt.Sprintf("items[%d]", i), &order.Items[i], order.TrackNumber)
+	}
+
+	return c.err()
+}
+
+func delivery(c *collector, d *models.Delivery) {
+	c.required("delivery.name", d.Name)
+	c.required("delivery.city", d.City)
+	c.required("delivery.address", d.Address)
+	if c.required("delivery.phone", d.Phone) && !IsPhone(d.Phone) {
+		c.add("delivery.phone", RulePhone, "must be an international phone number, got %q", d.Phone)
+	}
+	if c.required("delivery.zip", d.Zip) && !IsZip(d.Zip) {
+		c.add("delivery.zip", RuleZip, "must be a postal code, got %q", d.Zip)
+	}
+	if c.required("delivery.email", d.Email) && !IsEmail(d.Email) {
+		c.add("delivery.email", RuleEmail, "must be a valid email address, got %q", d.Email)
+	}
+}
+
+func payment(c *collector, p *models.Payment) {
+	c.required("payment.transaction", p.Transaction)
+	c.required("payment.provider", p.Provider)
+	if c.required("payment.currency", string(p.Currency)) && !p.Currency.Valid() {
+		c.add("payment.currency", RuleCurrency, "must be an ISO 4217 currency code, got %q", p.Currency)
+	}
+	c.positive("payment.amount", int64(p.Amount.Amount))
+	if p.PaymentDT.IsZero() {
+		c.add("payment.payment_dt", RuleRequired, "is required")
+	}
+	c.nonNegative("payment.delivery_cost", int64(p.DeliveryCost.Amount))
+	c.nonNegative("payment.goods_total", int64(p.GoodsTotal.Amount))
+	c.nonNegative("payment.custom_fee", int64(p.CustomFee.Amount))
+}
+
+func item(c *collector, path string, it *models.Item, trackNumber string) {
+	c.required(path+".name", it.Name)
+	c.positive(path+".chrt_id", int64(it.CHRTID))
+	c.positive(path+".nm_id", int64(it.NMID))
+	c.positive(path+".price", int64(it.Price.Amount))
+	c.nonNegative(path+".total_price", int64(it.TotalPrice.Amount))
+	if it.Sale < 0 || it.Sale > maxSale {
+		c.add(path+".sale", RuleRange, "must be between 0 and %d percent, got %d", maxSale, it.Sale)
+	}
+	if it.TrackNumber != "" && trackNumber != "" && it.TrackNumber != trackNumber {
+		c.add(path+".track_number", RuleMatch, "must match order track_number %q, got %q", trackNumber, it.TrackNumber)
+	}
+}
@@ -0,0 +1,148 @@
+package validation
+
+import (
+	"errors"
+	"orderkeeper/internal/models"
+	"orderkeeper/internal/money"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func validOrder() models.Order {
+	return models.Order{
+		OrderUID:        "b563feb7b2b84b6test",
+		TrackNumber:     "WBILMTESTTRACK",
+		Entry:           "WBIL",
+		Locale:          "en",
+		CustomerID:      "test",
+		DeliveryService: "meest",
+		DateCreated:     models.Timestamp{Time: time.Date(2021, 11, 26, 6, 22, 19, 0, time.UTC)},
+		Delivery: models.Delivery{
+			Name:    "Test Testov",
+			Phone:   "+9720000000",
+			Zip:     "2639809",
+			City:    "Kiryat Mozkin",
+			Address: "Ploshad Mira 15",
+			Region:  "Kraiot",
+			Email:   "test@gmail.com",
+		},
+		Payment: models.Payment{
+			Transaction:  "b563feb7b2b84b6test",
+			Currency:     "USD",
+			Provider:     "wbpay",
+			Amount:       money.New(1817, "USD"),
+			PaymentDT:    models.UnixTime{Time: time.Unix(1637907727, 0)},
+			Bank:         "alpha",
+			DeliveryCost: money.New(1500, "USD"),
+			GoodsTotal:   money.New(317, "USD"),
+			CustomFee:    money.New(0, "USD"),
+		},
+		Items: []models.Item{{
+			CHRTID:      9934930,
+			TrackNumber: "WBILMTESTTRACK",
+			Price:       money.New(453, "USD"),
+			Name:        "Mascaras",
+			Sale:        30,
+			TotalPrice:  money.New(317, "USD"),
+			NMID:        2389212,
+		}},
+	}
+}
+
+func TestOrder(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		order := validOrder()
+		assert.NoError(t, Order(&order))
+	})
+
+	t.Run("reports all violations", func(t *testing.T) {
+		order := validOrder()
+		order.Locale = "not a locale"
+		order.Delivery.Email = "test@"
+		order.Delivery.Phone = "call me"
+		order.Payment.Currency = "ABC"
+		order.Payment.Amount.Amount = 0
+		order.Items = append(order.Items, models.Item{
+			CHRTID: 1, NMID: 1, Price: money.New(10, "USD"), Name: "x", Sale: 120, TrackNumber: "OTHER",
+		})
+
+		err := Order(&order)
+
+		var violations Errors
+		require.True(t, errors.As(err, &violations))
+		var codes []string
+		for _, v := range violations {
+			codes = append(codes, v.Code())
+		}
+		assert.ElementsMatch(t, []string{
+			"locale:locale",
+			"delivery.phone:phone",
+			"delivery.email:email",
+			"payment.currency:currency",
+			"payment.amount:positive",
+			"items[].sale:range",
+			"items[].track_number:match",
+		}, codes)
+		assert.Equal(t, "items[1].sale", violations[len(violations)-2].Field)
+	})
+
+	t.Run("missing items", func(t *testing.T) {
+		order := validOrder()
+		order.Items = nil
+
+		err := Order(&order)
+
+		assert.Equal(t, Errors{{Field: "items", Rule: RuleRequired, Message: "order must contain at least one item"}}, err)
+	})
+}
+
+func TestFormats(t *testing.T) {
+	assert.True(t, IsEmail("user@example.com"))
+	assert.False(t, IsEmail("Name <user@example.com>"))
+	assert.False(t, IsEmail("user@localhost"))
+	assert.True(t, IsPhone("+7 (999) 123-45-67"))
+	assert.False(t, IsPhone("12345"))
+	assert.True(t, IsZip("SW1A 1AA"))
+	assert.False(t, IsZip("12"))
+	assert.True(t, IsCurrency("RUB"))
+	assert.False(t, IsCurrency("usd1"))
+	assert.True(t, IsLocale("ru-RU"))
+	assert.False(t, IsLocale("!!"))
+}
+
+func TestReconcile(t *testing.T) {
+	t.Run("consistent", func(t *testing.T) {
+		order := validOrder()
+		assert.NoError(t, Reconcile(&order, 0))
+	})
+
+	t.Run("mismatches", func(t *testing.T) {
+		order := validOrder()
+		order.Items[0].TotalPrice.Amount = 300
+		order.Payment.Amount.Amount = 1900
+
+		err := Reconcile(&order, 0)
+
+		var violations Errors
+		require.True(t, errors.As(err, &violations))
+		var fields []string
+		for _, v := range violations {
+			assert.Equal(t, RuleReconcile, v.Rule)
+			fields = append(fields, v.Field)
+		}
+		assert.Equal(t, []string{"items[0].total_price", "payment.goods_total", "payment.amount"}, fields)
+	})
+
+	t.Run("within tolerance", func(t *testing.T) {
+		order := validOrder()
+		order.Items[0].TotalPrice.Amount = 319
+		order.Payment.GoodsTotal.Amount = 319
+		order.Payment.Amount.Amount = 1820
+
+		assert.Error(t, Reconcile(&order, 0))
+		assert.NoError(t, Reconcile(&order, 3))
+	})
+}
@@ -0,0 +1,98 @@
+package validation
+
+import (
+	"fmt"
+	"orderkeeper/internal/models"
+	"orderkeeper/internal/money"
+	"strings"
+)
+
+// Режимы сверки сумм заказа.
+const (
+	// ReconcileWarn сохраняет заказ с расхождениями и помечает его для проверки.
+	ReconcileWarn = "warn"
+	// ReconcileStrict отклоняет заказ с расхождениями.
+	ReconcileStrict = "strict"
+	// ReconcileOff отключает сверку.
+	ReconcileOff = "off"
+)
+
+const RuleReconcile = "reconcile"
+
+// Reconciliation настраивает сверку сумм. Tolerance — допустимое
+// расхождение в минимальных единицах валюты оплаты.
+type Reconciliation struct {
+	Mode      string
+	Tolerance money.Amount
+}
+
+func ParseReconcileMode(s string) (string, error) {
+	switch mode := strings.ToLower(strings.TrimSpace(s)); mode {
+	case "":
+		return ReconcileWarn, nil
+	case ReconcileWarn, ReconcileStrict, ReconcileOff:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("unknown reconciliation mode %q, expected %q, %q or %q", s, ReconcileWarn, ReconcileStrict, ReconcileOff)
+	}
+}
+
+// Reconcile сверяет суммы заказа: total_price каждой позиции с ценой и
+// скидкой, goods_total с суммой позиций и amount с goods_total, доставкой
+// и пошлиной. Округление цены со скидкой до целого не считается расхождением.
+func Reconcile(order *models.Order, tolerance money.Amount) error {
+	c := &collector{}
+	p := order.Payment
+
+	goodsTotal := money.New(0, p.Currency)
+	for i, it := range order.Items {
+		field := fmt.Sprintf("items[%d].total_price", i)
+		sum, err := goodsTotal.Add(it.TotalPrice)
+		if err != nil {
+			c.add(field, RuleReconcile, "%v", err)
+			return c.err()
+		}
+		goodsTotal = sum
+
+		low, exact := it.Price.Amount.Discount(it.Sale)
+		high := low
+		if !exact {
+			high++
+		}
+		if total := it.TotalPrice.Amount; total < low-tolerance || total > high+tolerance {
+			c.add(field, RuleReconcile, "must equal price %s with %d%% sale, got %s",
+				it.Price, it.Sale, it.TotalPrice)
+		}
+	}
+
+	if !within(p.GoodsTotal, goodsTotal, tolerance) {
+		c.add("payment.goods_total", RuleReconcile,
+			"must equal the sum of item total prices %s, got %s", goodsTotal, p.GoodsTotal)
+	}
+
+	amount, err := sum(p.GoodsTotal, p.DeliveryCost, p.CustomFee)
+	if err != nil {
+		c.add("payment.amount", RuleReconcile, "%v", err)
+	} else if !within(p.Amount, amount, tolerance) {
+		c.add("payment.amount", RuleReconcile,
+			"must equal goods_total + delivery_cost + custom_fee = %s, got %s", amount, p.Amount)
+	}
+
+	return c.err()
+}
+
+func sum(first money.Money, rest ...money.Money) (money.Money, error) {
+	total := first
+	for _, m := range rest {
+		var err error
+		if total, err = total.Add(m); err != nil {
+			return money.Money{}, err
+		}
+	}
+	return total, nil
+}
+
+func within(got, want money.Money, tolerance money.Amount) bool {
+	diff, err := got.Sub(want)
+	return err == nil && diff.Amount.Abs() <= tolerance
+}
@@ -0,0 +1,103 @@
+// Package validation содержит единые правила проверки заказа для HTTP API и
+// консьюмера Kafka. Проверка не останавливается на первой ошибке и
+// возвращает все нарушения сразу.
+package validation
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Коды правил. По ним клиенты и отчет dry-run различают нарушения, не
+// разбирая текст сообщения.
+const (
+	RuleRequired    = "required"
+	RuleEmail       = "email"
+	RulePhone       = "phone"
+	RuleZip         = "zip"
+	RuleCurrency    = "currency"
+	RuleLocale      = "locale"
+	RuleStatus      = "status"
+	RulePositive    = "positive"
+	RuleNonNegative = "non_negative"
+	RuleRange       = "range"
+	RuleMatch       = "match"
+)
+
+// FieldError описывает нарушение одного правила. Field — путь к полю в
+// JSON заказа, например "items[0].price".
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+func (e FieldError) Error() string {
+	return e.Field + ": " + e.Message
+}
+
+// Code возвращает идентификатор нарушения без индексов элементов, чтобы
+// одинаковые нарушения в разных позициях считались вместе.
+func (e FieldError) Code() string {
+	var b strings.Builder
+	inIndex := false
+	for _, r := range e.Field {
+		switch {
+		case r == '[':
+			inIndex = true
+			b.WriteRune(r)
+		case r == ']':
+			inIndex = false
+			b.WriteRune(r)
+		case !inIndex:
+			b.WriteRune(r)
+		}
+	}
+	return b.String() + ":" + e.Rule
+}
+
+// Errors — все нарушения, найденные в заказе.
+type Errors []FieldError
+
+func (e Errors) Error() string {
+	msgs := make([]string, len(e))
+	for i, fe := range e {
+		msgs[i] = fe.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+type collector struct {
+	errs Errors
+}
+
+func (c *collector) add(field, rule, format string, args ...any) {
+	c.errs = append(c.errs, FieldError{Field: field, Rule: rule, Message: fmt.Sprintf(format, args...)})
+}
+
+func (c *collector) required(field, value string) bool {
+	if strings.TrimSpace(value) == "" {
+		c.add(field, RuleRequired, "is required")
+		return false
+	}
+	return true
+}
+
+func (c *collector) positive(field string, value int64) {
+	if value <= 0 {
+		c.add(field, RulePositive, "must be positive, got %d", value)
+	}
+}
+
+func (c *collector) nonNegative(field string, value int64) {
+	if value < 0 {
+		c.add(field, RuleNonNegative, "must not be negative, got %d", value)
+	}
+}
+
+func (c *collector) err() error {
+	if len(c.errs) == 0 {
+		return nil
+	}
+	return c.errs
+}
@@ -1,6 +1,7 @@
 document.addEventListener('DOMContentLoaded', () => {
     console.log('DOM fully loaded and parsed');
 
+    const apiKeyInput = document.getElementById('apiKey');
     const orderIdInput = document.getElementById('orderId');
     const getOrderBtn = document.getElementById('getOrderBtn');
     const resultDiv = document.getElementById('result');
@@ -30,7 +31,9 @@ document.addEventListener('DOMContentLoaded', () => {
         resultDiv.classList.remove('error');
 
         try {
-            const response = await fetch(`/order/${encodeURIComponent(orderId)}`);
+            const response = await fetch(`/order/${encodeURIComponent(orderId)}`, {
+                headers: { 'X-API-Key': apiKeyInput.value.trim() }
+            });
             console.log('Response received:', response);
 
             if (!response.ok) {
@@ -63,6 +66,11 @@ document.addEventListener('DOMContentLoaded', () => {
         fetchOrder(orderId);
     });
 
+    apiKeyInput.value = sessionStorage.getItem('apiKey') || '';
+    apiKeyInput.addEventListener('change', () => {
+        sessionStorage.setItem('apiKey', apiKeyInput.value.trim());
+    });
+
     orderIdInput.addEventListener('keyup', (event) => {
         if (event.key === 'Enter') {
             console.log('Enter key pressed in orderId input');